@@ -0,0 +1,29 @@
+package hotcertification
+
+// Pause makes the node refuse new requests until Resume is called. Requests that have already been admitted are
+// still replicated and signed, and the node keeps contributing signature shares to the requests of other nodes.
+func (c *Coordinator) Pause(reason string) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	c.paused = true
+	c.pauseReason = reason
+	c.Readiness.Set(SubsystemIssuance, false, "paused: "+reason)
+}
+
+// Resume lets new requests in again after Pause.
+func (c *Coordinator) Resume() {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	c.paused = false
+	c.pauseReason = ""
+	c.Readiness.Set(SubsystemIssuance, true, "")
+}
+
+// Paused tells whether issuance is paused and why.
+func (c *Coordinator) Paused() (paused bool, reason string) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+	return c.paused, c.pauseReason
+}
@@ -0,0 +1,34 @@
+package hotcertification_test
+
+import (
+	"context"
+	"testing"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+func TestPause(t *testing.T) {
+	base := newTestCSR()
+	c := hc.NewCoordinator(&hc.Options{})
+
+	admitted := add(t, c, requestOf(base, 1, 0))
+	c.Pause("maintenance")
+	if paused, reason := c.Paused(); !paused || reason != "maintenance" {
+		t.Errorf("got paused %v with reason %q", paused, reason)
+	}
+
+	if _, err := c.AddRequest(context.Background(), requestOf(base, 1, 1)); hc.ReasonOf(err) != protocol.ErrorReason_PAUSED {
+		t.Fatalf("expected new request to be refused while paused, got %v", err)
+	}
+	// requests that have been admitted before go on
+	if hash := add(t, c, requestOf(base, 1, 0)); hash != admitted {
+		t.Errorf("resubmitted request got another ID")
+	}
+
+	c.Resume()
+	add(t, c, requestOf(base, 1, 1))
+	if got := len(c.Requests()); got != 2 {
+		t.Errorf("got %v stored requests, want 2", got)
+	}
+}
@@ -0,0 +1,217 @@
+package hotcertification
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// defaults for the admission control if they aren't set in the config
+const (
+	defaultQueueLimit        = 10000
+	defaultSigningQueueLimit = 10000
+	defaultRetryAfter        = time.Second
+	maxBuckets               = 4096 // number of rate limited clients after which idle ones are forgotten
+)
+
+// OverloadedError is returned for a request that hasn't been admitted because either the CA or the client
+// that sent it is over its limits. The client should try again after RetryAfter.
+type OverloadedError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *OverloadedError) Error() string {
+	return fmt.Sprintf("%v; retry after %v", e.Reason, e.RetryAfter)
+}
+
+// FairQueue holds the requests waiting to be proposed. Every client has its own FIFO queue and the
+// clients are served round-robin so a client with many pending requests can't starve the others.
+type FairQueue struct {
+	mut     sync.Mutex
+	clients map[uint32][]*protocol.CSR
+	order   []uint32 // clients with pending requests in the order they are served
+	size    int
+}
+
+func NewFairQueue() *FairQueue {
+	return &FairQueue{clients: make(map[uint32][]*protocol.CSR)}
+}
+
+// Push appends csr to the queue of the client that sent it.
+func (q *FairQueue) Push(csr *protocol.CSR) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	if len(q.clients[csr.ClientID]) == 0 {
+		q.order = append(q.order, csr.ClientID)
+	}
+	q.clients[csr.ClientID] = append(q.clients[csr.ClientID], csr)
+	q.size++
+}
+
+// Pop removes and returns the oldest request of the next client in turn, or nil if the queue is empty.
+func (q *FairQueue) Pop() *protocol.CSR {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	if len(q.order) == 0 {
+		return nil
+	}
+
+	id := q.order[0]
+	q.order = q.order[1:]
+
+	pending := q.clients[id]
+	csr := pending[0]
+	pending[0] = nil
+	if len(pending) > 1 {
+		q.clients[id] = pending[1:]
+		// the client goes to the back of the line
+		q.order = append(q.order, id)
+	} else {
+		delete(q.clients, id)
+	}
+	q.size--
+
+	return csr
+}
+
+// Remove takes csr out of the queue. It returns false if csr isn't in the queue (anymore).
+// Requests are compared by identity, so csr must be the same pointer that has been pushed.
+func (q *FairQueue) Remove(csr *protocol.CSR) bool {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	pending := q.clients[csr.ClientID]
+	for i, other := range pending {
+		if other != csr {
+			continue
+		}
+
+		if len(pending) == 1 {
+			delete(q.clients, csr.ClientID)
+			for j, id := range q.order {
+				if id == csr.ClientID {
+					q.order = append(q.order[:j], q.order[j+1:]...)
+					break
+				}
+			}
+		} else {
+			q.clients[csr.ClientID] = append(pending[:i], pending[i+1:]...)
+		}
+		q.size--
+		return true
+	}
+
+	return false
+}
+
+// Len returns the number of requests in the queue.
+func (q *FairQueue) Len() int {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	return q.size
+}
+
+// ClientLen returns the number of requests of the client with the given ID in the queue.
+func (q *FairQueue) ClientLen(id uint32) int {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	return len(q.clients[id])
+}
+
+// tokenBucket limits the rate of requests of a single client.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// admission decides whether a new request is let into the certification process.
+type admission struct {
+	queueLimit       int
+	clientQueueLimit int     // no per client limit if zero
+	rate             float64 // requests per second per client; no rate limit if zero
+	burst            float64
+	retryAfter       time.Duration
+	buckets          map[uint32]*tokenBucket
+}
+
+func newAdmission(opts *Options) *admission {
+	a := &admission{
+		queueLimit:       opts.QueueLimit,
+		clientQueueLimit: opts.ClientQueueLimit,
+		rate:             opts.ClientRate,
+		burst:            float64(opts.ClientBurst),
+		retryAfter:       time.Duration(opts.RetryAfter) * time.Millisecond,
+		buckets:          make(map[uint32]*tokenBucket),
+	}
+	if a.queueLimit <= 0 {
+		a.queueLimit = defaultQueueLimit
+	}
+	if a.burst < 1 {
+		a.burst = math.Max(1, math.Ceil(a.rate))
+	}
+	if a.retryAfter <= 0 {
+		a.retryAfter = defaultRetryAfter
+	}
+	return a
+}
+
+// admit checks a new request of the client with the given ID against the queue limits and takes a token
+// from the client's bucket. The caller must hold c.Mut.
+func (a *admission) admit(q *FairQueue, clientID uint32) error {
+	if q.Len() >= a.queueLimit {
+		return &OverloadedError{Reason: "replication queue is full", RetryAfter: a.retryAfter}
+	}
+	if a.clientQueueLimit > 0 && q.ClientLen(clientID) >= a.clientQueueLimit {
+		return &OverloadedError{
+			Reason:     fmt.Sprintf("client %v has too many pending requests", clientID),
+			RetryAfter: a.retryAfter,
+		}
+	}
+	return a.take(clientID)
+}
+
+// take removes a token from the bucket of the client or returns an error telling it when the next one is available.
+func (a *admission) take(clientID uint32) error {
+	if a.rate <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	b := a.buckets[clientID]
+	if b == nil {
+		if len(a.buckets) >= maxBuckets {
+			a.prune(now)
+		}
+		b = &tokenBucket{tokens: a.burst, last: now}
+		a.buckets[clientID] = b
+	}
+
+	b.tokens = math.Min(a.burst, b.tokens+now.Sub(b.last).Seconds()*a.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / a.rate * float64(time.Second))
+		return &OverloadedError{
+			Reason:     fmt.Sprintf("client %v exceeded its rate limit of %v requests per second", clientID, a.rate),
+			RetryAfter: wait,
+		}
+	}
+	b.tokens--
+
+	return nil
+}
+
+// prune forgets the buckets that have been refilled completely since they behave like new ones.
+func (a *admission) prune(now time.Time) {
+	for id, b := range a.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*a.rate >= a.burst {
+			delete(a.buckets, id)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package hotcertification_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// requestOf returns the n-th distinct request of a client that reuses the certificate request of base.
+func requestOf(base *protocol.CSR, clientID uint32, n int) *protocol.CSR {
+	return &protocol.CSR{
+		ClientID:           clientID,
+		CertificateRequest: base.CertificateRequest,
+		ValidationInfo:     []byte{byte(n)},
+	}
+}
+
+func TestFairQueueRoundRobin(t *testing.T) {
+	base := newTestCSR()
+	q := hc.NewFairQueue()
+
+	// client 1 floods the queue before client 2 and 3 send one request each
+	for i := 0; i < 5; i++ {
+		q.Push(requestOf(base, 1, i))
+	}
+	q.Push(requestOf(base, 2, 0))
+	q.Push(requestOf(base, 3, 0))
+
+	var order []uint32
+	for csr := q.Pop(); csr != nil; csr = q.Pop() {
+		order = append(order, csr.ClientID)
+	}
+
+	want := []uint32{1, 2, 3, 1, 1, 1, 1}
+	if len(order) != len(want) {
+		t.Fatalf("got %v requests, want %v", len(order), len(want))
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("got order %v, want %v", order, want)
+		}
+	}
+}
+
+func TestQueueLimits(t *testing.T) {
+	base := newTestCSR()
+	c := hc.NewCoordinator(&hc.Options{QueueLimit: 3, ClientQueueLimit: 2})
+
+	for i := 0; i < 2; i++ {
+		add(t, c, requestOf(base, 1, i))
+	}
+
+	var overloaded *hc.OverloadedError
+	if _, err := c.AddRequest(context.Background(), requestOf(base, 1, 2)); !errors.As(err, &overloaded) {
+		t.Fatalf("expected client to be over its queue limit, got %v", err)
+	}
+	if overloaded.RetryAfter <= 0 {
+		t.Errorf("expected retry-after hint")
+	}
+
+	// other clients are still admitted until the queue is full
+	add(t, c, requestOf(base, 2, 0))
+	if _, err := c.AddRequest(context.Background(), requestOf(base, 3, 0)); !errors.As(err, &overloaded) {
+		t.Fatalf("expected full queue, got %v", err)
+	}
+
+	// a refused request is forgotten so it can be sent again later
+	if _, ok := c.Lookup(hc.HashCSR(requestOf(base, 3, 0))); ok {
+		t.Errorf("refused request has been stored")
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	base := newTestCSR()
+	c := hc.NewCoordinator(&hc.Options{ClientRate: 1, ClientBurst: 2})
+
+	add(t, c, requestOf(base, 1, 0))
+	add(t, c, requestOf(base, 1, 1))
+
+	var overloaded *hc.OverloadedError
+	if _, err := c.AddRequest(context.Background(), requestOf(base, 1, 2)); !errors.As(err, &overloaded) {
+		t.Fatalf("expected rate limit, got %v", err)
+	}
+	if overloaded.RetryAfter <= 0 || overloaded.RetryAfter > time.Second {
+		t.Errorf("unexpected retry-after hint %v", overloaded.RetryAfter)
+	}
+
+	// resubmitting a request that has already been admitted doesn't count against the limit
+	add(t, c, requestOf(base, 1, 0))
+
+	// the limit is per client
+	add(t, c, requestOf(base, 2, 0))
+}
+
+// TestSigningQueueFull checks that executing a command doesn't block on a full signing queue. The request fails
+// and is signed when the client tries again.
+func TestSigningQueueFull(t *testing.T) {
+	base := newTestCSR()
+	c := hc.NewCoordinator(&hc.Options{SigningQueueLimit: 1})
+
+	add(t, c, requestOf(base, 1, 0))
+	replicate(t, c)
+	second := requestOf(base, 2, 0)
+	hash := add(t, c, second)
+
+	executed := make(chan struct{})
+	go func() {
+		defer close(executed)
+		replicate(t, c)
+	}()
+	select {
+	case <-executed:
+	case <-time.After(time.Second):
+		t.Fatal("execution blocks on the full signing queue")
+	}
+
+	info, err := c.Wait(context.Background(), hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var overloaded *hc.OverloadedError
+	if !errors.As(info.Err, &overloaded) || !info.Replicated {
+		t.Fatalf("expected replicated request that failed with a full signing queue, got %v", info.Err)
+	}
+	if reason := hc.ReasonOf(info.Err); reason != protocol.ErrorReason_OVERLOADED {
+		t.Errorf("expected reason OVERLOADED, got %v", reason)
+	}
+
+	<-c.SigningQueue
+	add(t, c, second)
+	if csr := <-c.SigningQueue; hc.HashCSR(csr) != hash {
+		t.Errorf("retry hasn't been queued for signing")
+	}
+}
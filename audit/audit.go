@@ -0,0 +1,346 @@
+// Package audit keeps a tamper-evident record of the operations of the CA.
+//
+// Every replica appends an entry to its log for each command HotStuff commits, each CSR it rejects, each certificate
+// it learns has been issued and each request that failed. Each entry contains the hash of the previous one, so
+// removing, reordering or changing entries breaks the chain. The committed commands additionally form a second
+// chain, the commit digest, which is the same on all replicas since they execute the commands in the same order.
+// Every few commits the replicas threshold sign the commit digest; these checkpoints prove that a quorum of
+// replicas agreed on the log up to that point.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// EntryType tells what an entry records.
+type EntryType string
+
+// Types of entries
+const (
+	TypeConfig     EntryType = "config"     // the replica started with a configuration it hasn't used before
+	TypeCommitted  EntryType = "committed"  // HotStuff committed the CSR, i.e. the replicas accepted it
+	TypeRejected   EntryType = "rejected"   // the replica rejected a proposed CSR
+	TypeIssued     EntryType = "issued"     // a certificate has been issued for the CSR
+	TypeFailed     EntryType = "failed"     // no certificate has been issued for the CSR
+	TypeCheckpoint EntryType = "checkpoint" // a quorum of replicas signed the commit digest
+)
+
+// DefaultCheckpointInterval is the number of commits between two checkpoints if it isn't configured.
+const DefaultCheckpointInterval = 100
+
+// Entry is a single record of the audit log. Entries are stored as one JSON object per line.
+type Entry struct {
+	Seq       uint64    `json:"seq"`
+	Time      time.Time `json:"time"`
+	Type      EntryType `json:"type"`
+	CSRHash   string    `json:"csr_hash,omitempty"`
+	Serial    string    `json:"serial,omitempty"`    // serial number of the issued certificate in hex
+	Reason    string    `json:"reason,omitempty"`    // why a CSR has been rejected or a request failed
+	Commit    uint64    `json:"commit,omitempty"`    // number of commits up to this entry (committed and checkpoint)
+	Digest    string    `json:"digest,omitempty"`    // commit digest (committed and checkpoint) or config digest
+	Signature string    `json:"signature,omitempty"` // threshold signature of a checkpoint in hex
+	Prev      string    `json:"prev"`                // hash of the previous entry
+	Hash      string    `json:"hash"`                // hash of this entry without the hash itself
+}
+
+// ComputeHash returns the hash of the entry, which covers all fields except Hash.
+func (e Entry) ComputeHash() string {
+	e.Hash = ""
+	b, err := json.Marshal(e)
+	if err != nil {
+		// an Entry contains nothing that can't be marshaled
+		panic(err)
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+var zeroHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// NextCommitDigest extends the commit digest by a committed CSR.
+func NextCommitDigest(prev []byte, csrHash string) []byte {
+	h := sha256.New()
+	h.Write(prev)
+	h.Write([]byte(csrHash))
+	return h.Sum(nil)
+}
+
+// CheckpointHash is the hash of CheckpointDigest.
+const CheckpointHash = crypto.SHA256
+
+// CheckpointDigest returns the digest that is threshold signed for the checkpoint after the given number of commits.
+func CheckpointDigest(commit uint64, commitDigest []byte) []byte {
+	var index [8]byte
+	binary.BigEndian.PutUint64(index[:], commit)
+
+	h := sha256.New()
+	h.Write([]byte("hotcertification audit checkpoint"))
+	h.Write(index[:])
+	h.Write(commitDigest)
+	return h.Sum(nil)
+}
+
+// Checkpoint is due to be signed once the given number of commits has been reached.
+type Checkpoint struct {
+	Commit uint64
+	Digest []byte // the commit digest after Commit commits
+}
+
+// Log is the audit log of a replica. The methods of a nil *Log do nothing, so auditing can be disabled.
+type Log struct {
+	mut         sync.Mutex
+	file        *os.File
+	seq         uint64
+	head        string
+	commit      uint64
+	digest      []byte
+	interval    uint64
+	digests     map[uint64][]byte // commit digests at the checkpoints, by number of commits
+	reached     chan struct{}     // closed and replaced every time a checkpoint is reached
+	checkpoints chan Checkpoint
+}
+
+// Open opens the audit log at path, creating it if it doesn't exist, and checks that it hasn't been tampered with.
+// A checkpoint is due every interval commits. If configDigest differs from the one recorded last, a config entry is
+// appended.
+func Open(path string, interval int, configDigest []byte) (*Log, error) {
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
+	}
+
+	l := &Log{
+		head:        zeroHash,
+		digest:      make([]byte, sha256.Size),
+		interval:    uint64(interval),
+		digests:     make(map[uint64][]byte),
+		reached:     make(chan struct{}),
+		checkpoints: make(chan Checkpoint, 16),
+	}
+
+	var lastConfig string
+	if f, err := os.Open(path); err == nil {
+		err = l.replay(f, &lastConfig)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("audit log %v: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l.file = f
+
+	if digest := hex.EncodeToString(configDigest); digest != lastConfig {
+		err = l.append(&Entry{Type: TypeConfig, Digest: digest})
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// replay restores the state of the log from the entries written before.
+func (l *Log) replay(f *os.File, lastConfig *string) error {
+	v := newVerifier(nil)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		entry, err := v.next(scanner.Bytes())
+		if err != nil {
+			return err
+		}
+		if entry.Type == TypeConfig {
+			*lastConfig = entry.Digest
+		}
+		if entry.Type == TypeCommitted && entry.Commit%l.interval == 0 {
+			l.digests[entry.Commit] = v.digest
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	l.seq = v.seq
+	l.head = v.head
+	l.commit = v.commit
+	l.digest = v.digest
+	return nil
+}
+
+// Close closes the file of the log.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.file.Close()
+}
+
+// Committed records that HotStuff committed the CSR with the given hash.
+func (l *Log) Committed(csrHash string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.commit++
+	l.digest = NextCommitDigest(l.digest, csrHash)
+
+	err := l.append(&Entry{
+		Type:    TypeCommitted,
+		CSRHash: csrHash,
+		Commit:  l.commit,
+		Digest:  hex.EncodeToString(l.digest),
+	})
+	if err != nil {
+		return err
+	}
+
+	if l.commit%l.interval == 0 {
+		l.digests[l.commit] = l.digest
+		close(l.reached)
+		l.reached = make(chan struct{})
+
+		select {
+		case l.checkpoints <- Checkpoint{Commit: l.commit, Digest: l.digest}:
+		default:
+			// nobody signs checkpoints; the log is still chained but won't be attested
+		}
+	}
+	return nil
+}
+
+// Rejected records that this replica rejected the CSR with the given hash.
+func (l *Log) Rejected(csrHash, reason string) error {
+	return l.add(&Entry{Type: TypeRejected, CSRHash: csrHash, Reason: reason})
+}
+
+// Issued records that the certificate with the given serial number has been issued for the CSR with the given hash.
+func (l *Log) Issued(csrHash string, serial *big.Int) error {
+	return l.add(&Entry{Type: TypeIssued, CSRHash: csrHash, Serial: serial.Text(16)})
+}
+
+// Failed records that no certificate has been issued for the CSR with the given hash.
+func (l *Log) Failed(csrHash, reason string) error {
+	return l.add(&Entry{Type: TypeFailed, CSRHash: csrHash, Reason: reason})
+}
+
+// Checkpoints returns the checkpoints that are due to be signed.
+func (l *Log) Checkpoints() <-chan Checkpoint {
+	if l == nil {
+		return nil
+	}
+	return l.checkpoints
+}
+
+// CommitDigest waits until the log contains the given number of commits and returns the commit digest at that point.
+// It only knows the digests at checkpoints.
+func (l *Log) CommitDigest(ctx context.Context, commit uint64) ([]byte, error) {
+	if l == nil {
+		return nil, fmt.Errorf("auditing is disabled")
+	}
+
+	for {
+		l.mut.Lock()
+		digest, ok := l.digests[commit]
+		current, reached := l.commit, l.reached
+		l.mut.Unlock()
+
+		if ok {
+			return digest, nil
+		}
+		if current >= commit {
+			return nil, fmt.Errorf("no checkpoint after %v commits", commit)
+		}
+
+		select {
+		case <-reached:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// AddCheckpoint records the threshold signature of a checkpoint.
+func (l *Log) AddCheckpoint(cp Checkpoint, signature []byte) error {
+	return l.add(&Entry{
+		Type:      TypeCheckpoint,
+		Commit:    cp.Commit,
+		Digest:    hex.EncodeToString(cp.Digest),
+		Signature: hex.EncodeToString(signature),
+	})
+}
+
+func (l *Log) add(entry *Entry) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.append(entry)
+}
+
+// append chains the entry to the log and writes it to the file. The caller must hold l.mut.
+func (l *Log) append(entry *Entry) error {
+	entry.Seq = l.seq + 1
+	entry.Time = time.Now().UTC()
+	entry.Prev = l.head
+	entry.Hash = entry.ComputeHash()
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	// the entry is only part of the chain once it has been written
+	if _, err := l.file.Write(b); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+
+	l.seq = entry.Seq
+	l.head = entry.Hash
+	return nil
+}
+
+// decode parses a line of the log strictly, so that additional fields count as tampering.
+func decode(line []byte) (*Entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.DisallowUnknownFields()
+
+	entry := new(Entry)
+	if err := dec.Decode(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,249 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/niclabs/tcrsa"
+
+	"github.com/raphasch/hotcertification/audit"
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+var config = []byte("config")
+
+func openLog(t *testing.T, path string, interval int) *audit.Log {
+	t.Helper()
+	l, err := audit.Open(path, interval, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// writeLog writes a log with a few entries of every type and returns its lines.
+func writeLog(t *testing.T, path string) []string {
+	t.Helper()
+
+	l := openLog(t, path, 2)
+	for _, err := range []error{
+		l.Committed("aaaa"),
+		l.Rejected("bbbb", "INVALID_CSR"),
+		l.Committed("cccc"),
+		l.Issued("aaaa", big.NewInt(42)),
+		l.Failed("cccc", "INSUFFICIENT_SHARES"),
+		l.Committed("dddd"),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func verify(lines []string) (*audit.Summary, error) {
+	return audit.Verify(strings.NewReader(strings.Join(lines, "")), nil)
+}
+
+func TestVerify(t *testing.T) {
+	lines := writeLog(t, filepath.Join(t.TempDir(), "audit.log"))
+
+	summary, err := verify(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// config entry plus the six above
+	if summary.Entries != 7 {
+		t.Errorf("got %v entries; want 7", summary.Entries)
+	}
+	if got := strings.Join(summary.Commits, ","); got != "aaaa,cccc,dddd" {
+		t.Errorf("got commits %v; want aaaa,cccc,dddd", got)
+	}
+}
+
+func TestTamperingDetected(t *testing.T) {
+	lines := writeLog(t, filepath.Join(t.TempDir(), "audit.log"))
+
+	changed := append([]string(nil), lines...)
+	changed[2] = strings.Replace(changed[2], "INVALID_CSR", "POLICY_REJECTED", 1)
+
+	removed := append(append([]string(nil), lines[:3]...), lines[4:]...)
+
+	reordered := append([]string(nil), lines...)
+	reordered[2], reordered[3] = reordered[3], reordered[2]
+
+	// the changed entry is rehashed, so only the link of the next entry breaks
+	rehashed := append([]string(nil), lines...)
+	entry := strings.Replace(rehashed[2], "INVALID_CSR", "POLICY_REJECTED", 1)
+	rehashed[2] = rehash(t, entry)
+
+	for _, test := range []struct {
+		name  string
+		lines []string
+		want  error
+	}{
+		{"changed", changed, audit.ErrTampered},
+		{"removed", removed, audit.ErrGap},
+		{"reordered", reordered, audit.ErrGap},
+		{"rehashed", rehashed, audit.ErrGap},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := verify(test.lines)
+			if !errors.Is(err, test.want) {
+				t.Errorf("got error %v; want %v", err, test.want)
+			}
+		})
+	}
+}
+
+// rehash fixes the hash of an entry that has been changed.
+func rehash(t *testing.T, line string) string {
+	t.Helper()
+	var entry audit.Entry
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatal(err)
+	}
+	entry.Hash = entry.ComputeHash()
+	b, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b) + "\n"
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeLog(t, path)
+
+	// the chain continues where it ended and the config isn't recorded again
+	l := openLog(t, path, 2)
+	if err := l.Committed("eeee"); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	l, err := audit.Open(path, 2, []byte("other config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	summary, err := audit.Verify(f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the seven entries written before, one commit and the changed config
+	if summary.Entries != 9 || len(summary.Commits) != 4 {
+		t.Errorf("got %v entries and %v commits; want 9 and 4", summary.Entries, len(summary.Commits))
+	}
+
+	// a log that has been tampered with isn't continued
+	b, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(b, []byte("eeee"), []byte("ffff"), 1), 0600)
+	if _, err := audit.Open(path, 2, config); !errors.Is(err, audit.ErrTampered) {
+		t.Errorf("opened log that has been tampered with: %v", err)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	keys, err := crypto.ComputeTresholdKeys(3, 4, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l := openLog(t, path, 2)
+	defer l.Close()
+
+	// replicas asking for the commit digest before this one has reached the checkpoint wait for it
+	digest := make(chan []byte)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		d, err := l.CommitDigest(ctx, 2)
+		if err != nil {
+			t.Error(err)
+		}
+		digest <- d
+	}()
+
+	l.Committed("aaaa")
+	l.Committed("bbbb")
+
+	var cp audit.Checkpoint
+	select {
+	case cp = <-l.Checkpoints():
+	default:
+		t.Fatal("no checkpoint due after two commits")
+	}
+	if d := <-digest; !bytes.Equal(d, cp.Digest) || cp.Commit != 2 {
+		t.Errorf("commit digest after %v commits differs from the one of the checkpoint", cp.Commit)
+	}
+
+	signed := audit.CheckpointDigest(cp.Commit, cp.Digest)
+	var shares tcrsa.SigShareList
+	for _, key := range keys[:3] {
+		share, err := crypto.SignDigestShare(signed, audit.CheckpointHash, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, share)
+	}
+	signature, err := crypto.JoinDigestShares(signed, audit.CheckpointHash, keys[0], shares...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddCheckpoint(cp, signature); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := os.ReadFile(path)
+	summary, err := audit.Verify(bytes.NewReader(b), keys[0].Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Checkpoints != 1 || summary.LastCheckpoint != 2 {
+		t.Errorf("got %v checkpoints, the last after %v commits; want 1 after 2", summary.Checkpoints, summary.LastCheckpoint)
+	}
+
+	other, err := crypto.ComputeTresholdKeys(3, 4, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := audit.Verify(bytes.NewReader(b), other[0].Public()); !errors.Is(err, audit.ErrTampered) {
+		t.Errorf("checkpoint signed by another key accepted: %v", err)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := &audit.Summary{Commits: []string{"aaaa", "bbbb", "cccc"}}
+	behind := &audit.Summary{Commits: []string{"aaaa", "bbbb"}}
+	diverged := &audit.Summary{Commits: []string{"aaaa", "dddd", "cccc"}}
+
+	if err := audit.Compare(a, behind); err != nil {
+		t.Errorf("replica that lags behind reported as diverged: %v", err)
+	}
+	if err := audit.Compare(a, diverged); !errors.Is(err, audit.ErrDiverged) {
+		t.Errorf("got error %v; want %v", err, audit.ErrDiverged)
+	}
+}
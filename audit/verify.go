@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/rsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrGap is returned if entries are missing or have been reordered.
+	ErrGap = errors.New("entries are missing")
+	// ErrTampered is returned if an entry has been changed.
+	ErrTampered = errors.New("entry has been tampered with")
+	// ErrDiverged is returned if two replicas committed different commands.
+	ErrDiverged = errors.New("logs diverge")
+)
+
+// Summary describes a verified audit log.
+type Summary struct {
+	Entries        uint64
+	Commits        []string // hashes of the committed CSRs in the order they have been committed
+	Checkpoints    int      // number of signed checkpoints
+	LastCheckpoint uint64   // number of commits covered by the last signed checkpoint
+	Head           string   // hash of the last entry
+}
+
+// Verify reads an audit log and checks that the chain of entries is complete and unchanged, that the commit digests
+// are correct and, if pub isn't nil, that the checkpoints have been signed with the key of the CA.
+// Entries cut off at the end of the log can only be detected by comparing it with the logs of other replicas.
+func Verify(r io.Reader, pub *rsa.PublicKey) (*Summary, error) {
+	v := newVerifier(pub)
+	summary := &Summary{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		entry, err := v.next(scanner.Bytes())
+		if err != nil {
+			return summary, err
+		}
+
+		switch entry.Type {
+		case TypeCommitted:
+			summary.Commits = append(summary.Commits, entry.CSRHash)
+		case TypeCheckpoint:
+			summary.Checkpoints++
+			summary.LastCheckpoint = entry.Commit
+		}
+		summary.Entries = v.seq
+		summary.Head = v.head
+	}
+	return summary, scanner.Err()
+}
+
+// Compare checks that two replicas committed the same commands. One of them might lag behind the other.
+func Compare(a, b *Summary) error {
+	n := len(a.Commits)
+	if len(b.Commits) < n {
+		n = len(b.Commits)
+	}
+	for i := 0; i < n; i++ {
+		if a.Commits[i] != b.Commits[i] {
+			return fmt.Errorf("%w at commit %v: %v vs. %v", ErrDiverged, i+1, a.Commits[i], b.Commits[i])
+		}
+	}
+	return nil
+}
+
+// verifier checks the entries of a log one after the other.
+type verifier struct {
+	pub     *rsa.PublicKey // checkpoint signatures aren't checked if nil
+	seq     uint64
+	head    string
+	commit  uint64
+	digest  []byte
+	digests [][]byte // commit digests after each commit
+}
+
+func newVerifier(pub *rsa.PublicKey) *verifier {
+	return &verifier{
+		pub:    pub,
+		head:   zeroHash,
+		digest: make([]byte, len(zeroHash)/2),
+	}
+}
+
+func (v *verifier) next(line []byte) (*Entry, error) {
+	entry, err := decode(line)
+	if err != nil {
+		return nil, fmt.Errorf("%w: entry %v is malformed: %v", ErrTampered, v.seq+1, err)
+	}
+
+	if entry.Seq != v.seq+1 {
+		return nil, fmt.Errorf("%w: expected entry %v, found %v", ErrGap, v.seq+1, entry.Seq)
+	}
+	if entry.Prev != v.head {
+		return nil, fmt.Errorf("%w: entry %v doesn't link to the previous entry", ErrGap, entry.Seq)
+	}
+	if entry.Hash != entry.ComputeHash() {
+		return nil, fmt.Errorf("%w: hash of entry %v doesn't match", ErrTampered, entry.Seq)
+	}
+
+	switch entry.Type {
+	case TypeCommitted:
+		if entry.Commit != v.commit+1 {
+			return nil, fmt.Errorf("%w: entry %v is commit %v, expected %v", ErrGap, entry.Seq, entry.Commit, v.commit+1)
+		}
+		digest := NextCommitDigest(v.digest, entry.CSRHash)
+		if entry.Digest != hex.EncodeToString(digest) {
+			return nil, fmt.Errorf("%w: commit digest of entry %v doesn't match", ErrTampered, entry.Seq)
+		}
+		v.commit++
+		v.digest = digest
+		v.digests = append(v.digests, digest)
+
+	case TypeCheckpoint:
+		if entry.Commit == 0 || entry.Commit > v.commit {
+			return nil, fmt.Errorf("%w: checkpoint in entry %v covers unknown commit %v", ErrTampered, entry.Seq, entry.Commit)
+		}
+		digest := v.digests[entry.Commit-1]
+		if entry.Digest != hex.EncodeToString(digest) {
+			return nil, fmt.Errorf("%w: checkpoint in entry %v doesn't match commit %v", ErrTampered, entry.Seq, entry.Commit)
+		}
+		if v.pub != nil {
+			signature, err := hex.DecodeString(entry.Signature)
+			if err == nil {
+				err = rsa.VerifyPKCS1v15(v.pub, CheckpointHash, CheckpointDigest(entry.Commit, digest), signature)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid signature on checkpoint in entry %v: %v", ErrTampered, entry.Seq, err)
+			}
+		}
+
+	case TypeConfig, TypeRejected, TypeIssued, TypeFailed:
+		// only chained
+
+	default:
+		return nil, fmt.Errorf("%w: entry %v has unknown type '%v'", ErrTampered, entry.Seq, entry.Type)
+	}
+
+	v.seq = entry.Seq
+	v.head = entry.Hash
+	return entry, nil
+}
@@ -0,0 +1,80 @@
+package hotcertification
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/backoff"
+)
+
+// defaults for connecting to the other nodes if they aren't set in the config
+const (
+	defaultConnectTimeout = 10 * time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// Backoff is how connections to the other nodes are retried, both while the cluster is bootstrapped and when
+// they break later on. The same Config is handed to gRPC and gorums so that all connections back off alike.
+type Backoff struct {
+	backoff.Config
+	Timeout time.Duration // how long a single attempt to connect may take
+}
+
+// NewBackoff returns the backoff configured in opts.
+func NewBackoff(opts *Options) Backoff {
+	b := Backoff{
+		Config:  backoff.DefaultConfig,
+		Timeout: durationOrDefault(opts.ConnectTimeout, defaultConnectTimeout),
+	}
+	b.MaxDelay = durationOrDefault(opts.MaxBackoff, defaultMaxBackoff)
+	if b.BaseDelay > b.MaxDelay {
+		b.BaseDelay = b.MaxDelay
+	}
+	return b
+}
+
+// Delay returns how long to wait after the given number of failed attempts. It grows exponentially up to MaxDelay
+// and is randomized by Jitter so that the nodes of a cluster that has been started at once don't retry in lockstep.
+func (b Backoff) Delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay, max := float64(b.BaseDelay), float64(b.MaxDelay)
+	for i := 1; i < failures && delay < max; i++ {
+		delay *= b.Multiplier
+	}
+	if delay > max {
+		delay = max
+	}
+	delay *= 1 + b.Jitter*(rand.Float64()*2-1)
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// Retry calls attempt until it succeeds, backing off in between. Each attempt gets a context that expires after
+// Timeout. Retry only gives up once ctx is done, returning the error of the last attempt.
+func Retry(ctx context.Context, b Backoff, attempt func(ctx context.Context) error) error {
+	for failures := 0; ; failures++ {
+		timer := time.NewTimer(b.Delay(failures))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, b.Timeout)
+		err := attempt(attemptCtx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %v", ctx.Err(), err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package hotcertification_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/backoff"
+
+	hc "github.com/raphasch/hotcertification"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	b := hc.Backoff{Config: backoff.Config{BaseDelay: 100 * time.Millisecond, Multiplier: 2, MaxDelay: time.Second}}
+
+	want := []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+		800 * time.Millisecond, time.Second, time.Second}
+	for failures, delay := range want {
+		if got := b.Delay(failures); got != delay {
+			t.Errorf("delay after %v failures is %v, want %v", failures, got, delay)
+		}
+	}
+
+	// jitter never takes the delay beyond its bounds
+	b.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		if got := b.Delay(10); got < 800*time.Millisecond || got > 1200*time.Millisecond {
+			t.Fatalf("delay with jitter is %v", got)
+		}
+	}
+}
+
+func TestNewBackoff(t *testing.T) {
+	b := hc.NewBackoff(&hc.Options{ConnectTimeout: 500, MaxBackoff: 2000})
+	if b.Timeout != 500*time.Millisecond || b.MaxDelay != 2*time.Second {
+		t.Errorf("got timeout %v and max delay %v", b.Timeout, b.MaxDelay)
+	}
+	if b = hc.NewBackoff(&hc.Options{}); b.Timeout <= 0 || b.MaxDelay < b.BaseDelay {
+		t.Errorf("got default timeout %v, base delay %v and max delay %v", b.Timeout, b.BaseDelay, b.MaxDelay)
+	}
+}
+
+var fastBackoff = hc.Backoff{
+	Config:  backoff.Config{BaseDelay: time.Millisecond, Multiplier: 2, MaxDelay: 10 * time.Millisecond},
+	Timeout: time.Second,
+}
+
+func TestRetry(t *testing.T) {
+	attempts := 0
+	err := hc.Retry(context.Background(), fastBackoff, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("attempt has no deadline")
+		}
+		attempts++
+		if attempts < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("got %v after %v attempts", err, attempts)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	failure := errors.New("unreachable")
+	err := hc.Retry(ctx, fastBackoff, func(context.Context) error { return failure })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want the context's error", err)
+	}
+}
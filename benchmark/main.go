@@ -18,11 +18,14 @@ import (
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	pb "github.com/raphasch/hotcertification/protocol"
 )
@@ -34,6 +37,7 @@ type options struct {
 	File        string `mapstructure:"file"`
 	Scenario    string `mapstructure:"scenario"`
 	Num         int    `mapstructure:"num"`
+	Clients     int    `mapstructure:"clients"`
 	Destination string `mapstructure:"destination"`
 }
 
@@ -54,6 +58,7 @@ func parseOptions() options {
 	flag.String("file", "", "The file to attach to the CSR as the Validation Info.")
 	flag.String("scenario", "4,100,none,0", "What scenario the measurements are for.")
 	num := flag.IntP("number", "n", 100, "number of requests to send.")
+	flag.IntP("clients", "c", 1, "number of clients sending requests concurrently.")
 
 	flag.Parse()
 
@@ -112,7 +117,7 @@ func getClient(opts options) (client pb.CertificationClient, err error) {
 	return client, nil
 }
 
-func generateTestCSR(opts options) (csr *pb.CSR, err error) {
+func generateTestCSR(opts options, clientID uint32) (csr *pb.CSR, err error) {
 
 	// generate private and public key for certificate
 	clientKey, err := rsa.GenerateKey(rand.Reader, 512)
@@ -146,7 +151,7 @@ func generateTestCSR(opts options) (csr *pb.CSR, err error) {
 	checkError("failed to open validation info file: ", err)
 
 	csr = &pb.CSR{
-		ClientID:           8,
+		ClientID:           clientID,
 		CertificateRequest: bytes,
 		ValidationInfo:     valInfo,
 	}
@@ -166,23 +171,48 @@ func main() {
 	client, err := getClient(opts)
 	checkError("couldn't instantiate client: ", err)
 
-	csr, err := generateTestCSR(opts)
-	checkError("failed to generate CSR:", err)
+	if opts.Clients < 1 {
+		opts.Clients = 1
+	}
+
+	// every request needs its own CSR since resubmitting a CSR only returns the certificate issued before;
+	// every client sends its share of the requests one after another
+	csrs := make([]*pb.CSR, opts.Num)
+	for i := range csrs {
+		csrs[i], err = generateTestCSR(opts, uint32(i%opts.Clients)+1)
+		checkError("failed to generate CSR:", err)
+	}
 
 	ctx := context.Background()
 
 	measurements := make([]time.Duration, opts.Num)
-	for i := 0; i < opts.Num; i++ {
-		log.Println("Sending #", i)
-		start := time.Now()
-
-		// putting CSR into protocol buffers format and calling remote function
-		_, err := client.GetCertificate(ctx, csr)
-		checkError("failed to call RPC:", err)
-
-		elapsed := time.Since(start)
-		measurements[i] = elapsed
+	var wg sync.WaitGroup
+	begin := time.Now()
+	for c := 0; c < opts.Clients; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			for i := c; i < opts.Num; i += opts.Clients {
+				log.Println("Sending #", i)
+				start := time.Now()
+
+				// putting CSR into protocol buffers format and calling remote function;
+				// requests refused because the server is overloaded are sent again after the time it asks for
+				_, err := client.GetCertificate(ctx, csrs[i])
+				for status.Code(err) == codes.ResourceExhausted {
+					time.Sleep(pb.RetryDelay(err))
+					_, err = client.GetCertificate(ctx, csrs[i])
+				}
+				checkError("failed to call RPC:", err)
+
+				measurements[i] = time.Since(start)
+			}
+		}(c)
 	}
+	wg.Wait()
+	total := time.Since(begin)
+
+	log.Printf("%v requests from %v clients in %v (%.2f certificates/s)", opts.Num, opts.Clients, total, float64(opts.Num)/total.Seconds())
 
 	file, err := os.OpenFile(opts.Destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	checkError("Cannot create file", err)
@@ -0,0 +1,108 @@
+/*
+	AUDIT LOG VERIFIER:
+		1. Checks that the hash chain of each audit log is complete and unchanged
+		2. Checks the threshold signatures of the checkpoints with the public key of the CA
+		3. Checks that all replicas committed the same commands
+*/
+package main
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/raphasch/hotcertification/audit"
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+func usage() {
+	fmt.Printf("Usage: %s [options] auditlog [auditlog...]\n", os.Args[0])
+	fmt.Println()
+	fmt.Println("Verifies the audit logs of one or more replicas and compares them with each other.")
+	fmt.Println()
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+
+	help := flag.BoolP("help", "h", false, "Prints this text.")
+	rootCA := flag.String("root-ca", "", "The root certificate of the CA; checkpoint signatures aren't checked without it")
+	flag.Parse()
+
+	if *help {
+		usage()
+		os.Exit(0)
+	}
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	var pub *rsa.PublicKey
+	if *rootCA != "" {
+		cert, err := crypto.ReadCertFile(*rootCA)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read root certificate: %v\n", err)
+			os.Exit(1)
+		}
+		var ok bool
+		pub, ok = cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "Root certificate doesn't contain an RSA key\n")
+			os.Exit(1)
+		}
+	}
+
+	failed := false
+	summaries := make(map[string]*audit.Summary)
+	for _, path := range flag.Args() {
+		summary, err := verify(path, pub)
+		if err != nil {
+			fmt.Printf("%v: FAILED: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%v: OK: %v entries, %v commits, %v signed checkpoints (last after %v commits), head %v\n",
+			path, summary.Entries, len(summary.Commits), summary.Checkpoints, summary.LastCheckpoint, summary.Head)
+		summaries[path] = summary
+	}
+
+	// every log is compared with the longest one, which all others have to be a prefix of
+	var longest string
+	for path, summary := range summaries {
+		if longest == "" || len(summary.Commits) > len(summaries[longest].Commits) {
+			longest = path
+		}
+	}
+	for _, path := range flag.Args() {
+		summary, ok := summaries[path]
+		if !ok || path == longest {
+			continue
+		}
+		if err := audit.Compare(summaries[longest], summary); err != nil {
+			fmt.Printf("%v and %v: FAILED: %v\n", longest, path, err)
+			failed = true
+		} else if missing := len(summaries[longest].Commits) - len(summary.Commits); missing > 0 {
+			fmt.Printf("%v: %v commits behind %v\n", path, missing, longest)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func verify(path string, pub *rsa.PublicKey) (*audit.Summary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return audit.Verify(f, pub)
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/protocol"
+	"github.com/raphasch/hotcertification/signing"
+)
+
+// adminServer lets operators inspect and control the node. It only accepts clients with a certificate of the admin CA.
+type adminServer struct {
+	backendSrv  *grpc.Server
+	coordinator *hc.Coordinator
+	peers       func() []signing.Peer
+	id          uint32
+	started     time.Time
+	log         logging.Logger
+
+	protocol.UnimplementedAdminServer
+}
+
+// NewAdminServer returns the admin service of the node. It fails unless the node has a certificate for the service
+// and a CA for the client certificates is configured, since the service must not be served without mutual TLS.
+func NewAdminServer(coordinator *hc.Coordinator, opts *hc.Options, peers func() []signing.Peer) (*adminServer, error) {
+	node := opts.Self()
+	if node.AdminCert == "" || node.AdminKey == "" || opts.AdminCA == "" {
+		return nil, fmt.Errorf("admin service needs admin-cert, admin-key and admin-ca")
+	}
+
+	cert, err := tls.LoadX509KeyPair(node.AdminCert, node.AdminKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(opts.AdminCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read admin CA: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate in admin CA file %v", opts.AdminCA)
+	}
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	})
+	adminSrv := &adminServer{
+		backendSrv:  grpc.NewServer(grpc.Creds(creds)),
+		coordinator: coordinator,
+		peers:       peers,
+		id:          uint32(opts.ID),
+		started:     time.Now(),
+		log:         logging.New("admin"),
+	}
+	protocol.RegisterAdminServer(adminSrv.backendSrv, adminSrv)
+
+	return adminSrv, nil
+}
+
+func (srv *adminServer) GetNodeStatus(context.Context, *protocol.NodeStatusRequest) (*protocol.NodeStatus, error) {
+	return srv.status(), nil
+}
+
+// status gathers the state of the node and lists everything that keeps it from working properly.
+func (srv *adminServer) status() *protocol.NodeStatus {
+	view, leader := srv.coordinator.Consensus()
+	paused, reason := srv.coordinator.Paused()
+	st := &protocol.NodeStatus{
+		ID:               srv.id,
+		View:             uint64(view),
+		Leader:           uint32(leader),
+		Paused:           paused,
+		PauseReason:      reason,
+		ReplicationQueue: uint32(srv.coordinator.ReplicationQueue.Len()),
+		SigningQueue:     uint32(len(srv.coordinator.SigningQueue)),
+		Requests:         uint32(len(srv.coordinator.Requests())),
+		Uptime:           uint64(time.Since(srv.started).Milliseconds()),
+	}
+	if sth := srv.coordinator.CTLog.STH(); sth != nil {
+		st.TreeSize = sth.TreeSize
+	}
+
+	states := srv.coordinator.Readiness.States()
+	subsystems := make([]string, 0, len(states))
+	for name := range states {
+		subsystems = append(subsystems, name)
+	}
+	sort.Strings(subsystems)
+	for _, name := range subsystems {
+		state := states[name]
+		st.Subsystems = append(st.Subsystems, &protocol.SubsystemStatus{
+			Name:   name,
+			Ready:  state.Ready,
+			Reason: state.Reason,
+			Since:  unixMilli(state.Since),
+		})
+		if !state.Ready {
+			st.Problems = append(st.Problems, fmt.Sprintf("%v isn't ready: %v", name, state.Reason))
+		}
+	}
+
+	for _, p := range srv.peers() {
+		ps := &protocol.PeerStatus{
+			ID:        uint32(p.ID),
+			Address:   p.Address,
+			Connected: p.Connected,
+			Latency:   uint64(p.Latency.Microseconds()),
+		}
+		if p.Err != nil {
+			ps.LastError = p.Err.Error()
+			st.Problems = append(st.Problems, fmt.Sprintf("node %v is unreachable", p.ID))
+		}
+		st.Peers = append(st.Peers, ps)
+	}
+
+	st.Healthy = len(st.Problems) == 0
+	return st
+}
+
+// ListRequests lists the requests in the store, the most recently submitted first.
+func (srv *adminServer) ListRequests(_ context.Context, req *protocol.ListRequestsRequest) (*protocol.RequestList, error) {
+	wanted := make(map[protocol.RequestState]bool, len(req.States))
+	for _, state := range req.States {
+		wanted[state] = true
+	}
+
+	list := &protocol.RequestList{}
+	for hash, info := range srv.coordinator.Requests() {
+		if len(wanted) > 0 && !wanted[info.State()] {
+			continue
+		}
+		list.Requests = append(list.Requests, summary(hash, info))
+	}
+
+	sort.Slice(list.Requests, func(i, j int) bool {
+		a, b := list.Requests[i], list.Requests[j]
+		if a.Submitted != b.Submitted {
+			return a.Submitted > b.Submitted
+		}
+		if a.Committed != b.Committed {
+			return a.Committed > b.Committed
+		}
+		return a.ID < b.ID
+	})
+	if req.Limit > 0 && len(list.Requests) > int(req.Limit) {
+		list.Requests = list.Requests[:req.Limit]
+	}
+	return list, nil
+}
+
+func (srv *adminServer) GetRequest(_ context.Context, id *protocol.RequestID) (*protocol.RequestDetails, error) {
+	info, ok := srv.coordinator.Lookup(id.ID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown request %v", id.ID)
+	}
+	events, _ := srv.coordinator.Events(id.ID)
+
+	details := &protocol.RequestDetails{Summary: summary(id.ID, info), Events: events}
+	if info.Certificate != nil {
+		details.Certificate = info.Certificate.Raw
+	}
+	return details, nil
+}
+
+func (srv *adminServer) PauseIssuance(ctx context.Context, req *protocol.PauseRequest) (*protocol.NodeStatus, error) {
+	reason := req.Reason
+	if reason == "" {
+		reason = "no reason given"
+	}
+	srv.log.Infof("%v paused issuance: %v", operator(ctx), reason)
+	srv.coordinator.Pause(reason)
+	return srv.status(), nil
+}
+
+func (srv *adminServer) ResumeIssuance(ctx context.Context, _ *protocol.ResumeRequest) (*protocol.NodeStatus, error) {
+	srv.log.Infof("%v resumed issuance", operator(ctx))
+	srv.coordinator.Resume()
+	return srv.status(), nil
+}
+
+// summary converts the stored information about a request.
+func summary(hash string, info hc.RequestInfo) *protocol.RequestSummary {
+	s := &protocol.RequestSummary{
+		ID:        hash,
+		ClientID:  info.CSR.GetClientID(),
+		State:     info.State(),
+		Submitted: unixMilli(info.Submitted),
+		Committed: unixMilli(info.Committed),
+		Completed: unixMilli(info.Completed),
+		Deadline:  unixMilli(info.Deadline),
+	}
+	if info.Err != nil {
+		s.Reason = hc.ReasonOf(info.Err)
+		s.Error = info.Err.Error()
+	}
+	return s
+}
+
+func unixMilli(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano() / int64(time.Millisecond))
+}
+
+// operator returns the subject of the client certificate the call has been made with.
+func operator(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "unknown operator"
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return "unknown operator"
+	}
+	return info.State.PeerCertificates[0].Subject.String()
+}
+
+func (srv *adminServer) Start(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	srv.log.Infof("Admin server listening on %v.", addr)
+
+	return srv.backendSrv.Serve(lis)
+}
+
+func (srv *adminServer) Stop(ctx context.Context) {
+	gracefulStop(ctx, srv.backendSrv)
+}
@@ -9,32 +9,52 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net"
+	"time"
 
 	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/ctlog"
+	"github.com/raphasch/hotcertification/logging"
 	"github.com/raphasch/hotcertification/protocol"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 )
 
 type clientServer struct {
-	backendSrv  *grpc.Server
-	coordinator *hc.Coordinator
+	backendSrv    *grpc.Server
+	coordinator   *hc.Coordinator
+	inclusionWait time.Duration // how long certificates are held back until they have been logged; zero doesn't wait
+	log           logging.Logger
 
 	// gRPC stuff for backward compatability
 	protocol.UnimplementedCertificationServer
 }
 
-func NewClientServer(coordinator *hc.Coordinator) *clientServer {
+func NewClientServer(coordinator *hc.Coordinator, opts *hc.Options) *clientServer {
 
-	var opts []grpc.ServerOption
-	grpcServer := grpc.NewServer(opts...)
+	// the trace context sent by clients is picked up so that their spans are linked to the request
+	srvOpts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
+	}
+	grpcServer := grpc.NewServer(srvOpts...)
 	clientSrv := &clientServer{
-		backendSrv:  grpcServer,
-		coordinator: coordinator,
+		backendSrv:    grpcServer,
+		coordinator:   coordinator,
+		inclusionWait: time.Duration(opts.InclusionWait) * time.Millisecond,
+		log:           logging.New("client-server"),
 	}
 
 	protocol.RegisterCertificationServer(grpcServer, clientSrv)
+	// orchestrators learn from the health service whether the node is ready to take requests
+	if coordinator.Readiness != nil {
+		healthpb.RegisterHealthServer(grpcServer, coordinator.Readiness.HealthServer())
+	}
 
 	return clientSrv
 }
@@ -42,32 +62,189 @@ func NewClientServer(coordinator *hc.Coordinator) *clientServer {
 // need this because [see here](https://stackoverflow.com/questions/65079032/grpc-with-mustembedunimplemented-method)
 func (srv *clientServer) mustEmbedUnimplementedCertificationServer() {}
 
-func (srv *clientServer) GetCertificate(_ context.Context, csr *protocol.CSR) (*protocol.Certificate, error) {
+func (srv *clientServer) GetCertificate(ctx context.Context, csr *protocol.CSR) (*protocol.Certificate, error) {
 
-	srv.coordinator.Log.Info("Received CSR ", hc.HashCSR(csr)[:6])
+	hash := hc.HashCSR(csr)
+	log := logging.With(srv.log, "csr", hash[:6])
+	log.Info("Received CSR")
+
+	// a CSR that no replica would accept doesn't have to go through consensus
+	if err := srv.coordinator.Validate(csr); err != nil {
+		return nil, statusError(hash, err)
+	}
 
 	// First step; replication
-	srv.coordinator.AddRequest(csr)
+	// the request is withdrawn if the client gives up before it has been proposed
+	hash, err := srv.coordinator.AddRequest(ctx, csr)
+	if err != nil {
+		return nil, statusError(hash, err)
+	}
 
 	// wait for fully signed certificate
-	certificate := <-srv.coordinator.FinishedCerts
-	if certificate.Certificate != nil {
-		srv.coordinator.Log.Info("Returning fully signed certificate to client.")
-		return certificate, nil
-	} else {
-		return nil, fmt.Errorf("couldn't compute full signature on certificate")
+	info, err := srv.coordinator.Wait(ctx, hash)
+	if err != nil {
+		return nil, statusError(hash, err)
 	}
+	if info.Certificate == nil {
+		return nil, statusError(hash, requestError(info))
+	}
+
+	// the certificate is returned without a proof if it hasn't been logged yet; the client can fetch it later.
+	// The log is only extended by the sequencer, so issuance doesn't wait for it unless configured to
+	proof, logged := srv.coordinator.CTLog.Proof(info.Certificate.Raw)
+	if !logged && srv.inclusionWait > 0 {
+		proofCtx, cancel := context.WithTimeout(ctx, srv.inclusionWait)
+		defer cancel()
+		proof, err = srv.coordinator.CTLog.WaitProof(proofCtx, info.Certificate.Raw)
+		logged = err == nil
+	}
+	pending := !logged && srv.coordinator.CTLog != nil
+	if pending {
+		log.Info("Certificate hasn't been added to the certificate log yet; its inclusion proof is pending.")
+	}
+
+	log.Info("Returning fully signed certificate to client.")
+	srv.coordinator.MarkReturned(hash)
+	return &protocol.Certificate{Certificate: info.Certificate.Raw, Proof: proof.Proto(), ProofPending: pending}, nil
 }
 
-func (srv *clientServer) Start(addr string) {
+// SubmitCSR starts the certification process and returns immediately with the ID of the request.
+// The request outlives the call and is kept until it expires.
+func (srv *clientServer) SubmitCSR(ctx context.Context, csr *protocol.CSR) (*protocol.RequestID, error) {
+	if err := srv.coordinator.Validate(csr); err != nil {
+		return nil, statusError(hc.HashCSR(csr), err)
+	}
+
+	// keeps the span of the call so that the request can be traced back to it
+	detached := trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
+	hash, err := srv.coordinator.AddRequest(detached, csr)
+	if err != nil {
+		return nil, statusError(hash, err)
+	}
+
+	logging.With(srv.log, "csr", hash[:6]).Info("Received CSR")
+
+	return &protocol.RequestID{ID: hash}, nil
+}
+
+func (srv *clientServer) GetRequestStatus(_ context.Context, id *protocol.RequestID) (*protocol.RequestStatus, error) {
+	info, ok := srv.coordinator.Lookup(id.ID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown request %v", id.ID)
+	}
+
+	reqStatus := &protocol.RequestStatus{ID: id.ID, State: info.State()}
+	if info.Err != nil {
+		reqStatus.Error = info.Err.Error()
+		reqStatus.Reason = hc.ReasonOf(info.Err)
+	}
+	return reqStatus, nil
+}
+
+// FetchCertificate returns the certificate of a request that has been submitted to any node of the cluster.
+func (srv *clientServer) FetchCertificate(_ context.Context, id *protocol.RequestID) (*protocol.Certificate, error) {
+	info, ok := srv.coordinator.Lookup(id.ID)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown request %v", id.ID)
+	}
+
+	switch {
+	case info.Certificate == nil && (info.Rejected || info.Err != nil):
+		return nil, statusError(id.ID, requestError(info))
+	case info.Certificate == nil:
+		return nil, status.Errorf(codes.Unavailable, "certificate for request %v has not been issued yet", id.ID)
+	}
+
+	srv.coordinator.MarkReturned(id.ID)
+	proof, logged := srv.coordinator.CTLog.Proof(info.Certificate.Raw)
+	return &protocol.Certificate{
+		Certificate:  info.Certificate.Raw,
+		Proof:        proof.Proto(),
+		ProofPending: !logged && srv.coordinator.CTLog != nil,
+	}, nil
+}
+
+// WatchRequest streams an event every time the request moves on in the certification process.
+// The stream ends after the certificate has been issued or the request failed.
+func (srv *clientServer) WatchRequest(id *protocol.RequestID, stream protocol.Certification_WatchRequestServer) error {
+	events, cancel, ok := srv.coordinator.Watch(id.ID)
+	if !ok {
+		return status.Errorf(codes.NotFound, "unknown request %v", id.ID)
+	}
+	defer cancel()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+// GetSTH returns the latest signed tree head of the certificate log.
+func (srv *clientServer) GetSTH(context.Context, *protocol.STHRequest) (*protocol.SignedTreeHead, error) {
+	sth := srv.coordinator.CTLog.STH()
+	if sth == nil {
+		return nil, status.Error(codes.Unavailable, "no tree head has been signed yet")
+	}
+	return sth.Proto(), nil
+}
+
+// GetProofByHash returns the inclusion proof of a leaf of the certificate log (get-proof-by-hash in RFC 6962).
+func (srv *clientServer) GetProofByHash(_ context.Context, req *protocol.ProofByHashRequest) (*protocol.InclusionProof, error) {
+	index, path, err := srv.coordinator.CTLog.ProofByHash(req.Hash, req.TreeSize)
+	if err != nil {
+		return nil, logError(err)
+	}
+
+	proof := &protocol.InclusionProof{LeafIndex: index, AuditPath: path, TreeSize: req.TreeSize}
+	if sth, ok := srv.coordinator.CTLog.STHOfSize(req.TreeSize); ok {
+		proof.STH = sth.Proto()
+	}
+	return proof, nil
+}
+
+// GetConsistency returns the proof that the certificate log of size First is a prefix of the one of size Second
+// (get-sth-consistency in RFC 6962).
+func (srv *clientServer) GetConsistency(_ context.Context, req *protocol.ConsistencyRequest) (*protocol.ConsistencyProof, error) {
+	hashes, err := srv.coordinator.CTLog.Consistency(req.First, req.Second)
+	if err != nil {
+		return nil, logError(err)
+	}
+	return &protocol.ConsistencyProof{Hashes: hashes}, nil
+}
+
+// logError translates an error of the certificate log into a status.
+func logError(err error) error {
+	if errors.Is(err, ctlog.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// Start serves clients on addr until Stop is called.
+func (srv *clientServer) Start(addr string) error {
 
 	// open port
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
 
-	srv.coordinator.Log.Infof("Client server listening on %v.", addr)
+	srv.log.Infof("Client server listening on %v.", addr)
+
+	return srv.backendSrv.Serve(lis)
+}
 
-	srv.backendSrv.Serve(lis)
+// Stop lets the calls in progress finish, unless ctx is done first, and stops the server.
+func (srv *clientServer) Stop(ctx context.Context) {
+	gracefulStop(ctx, srv.backendSrv)
+	srv.log.Info("Client server stopped.")
 }
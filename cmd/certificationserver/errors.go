@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// errorDomain is the domain of the ErrorInfo attached to every error returned to clients
+const errorDomain = "hotcertification"
+
+// codeOf maps the reason a request failed to the gRPC status code the client gets
+var codeOf = map[protocol.ErrorReason]codes.Code{
+	protocol.ErrorReason_INVALID_CSR:         codes.InvalidArgument,
+	protocol.ErrorReason_POLICY_REJECTED:     codes.PermissionDenied,
+	protocol.ErrorReason_CONSENSUS_TIMEOUT:   codes.DeadlineExceeded,
+	protocol.ErrorReason_INSUFFICIENT_SHARES: codes.Unavailable,
+	protocol.ErrorReason_INTERNAL:            codes.Internal,
+	protocol.ErrorReason_CT_UNAVAILABLE:      codes.Unavailable,
+	protocol.ErrorReason_PAUSED:              codes.Unavailable,
+	protocol.ErrorReason_NOT_READY:           codes.Unavailable,
+	protocol.ErrorReason_OVERLOADED:          codes.ResourceExhausted,
+}
+
+// requestError returns the error of a failed request.
+func requestError(info hc.RequestInfo) error {
+	if info.Err != nil {
+		return info.Err
+	}
+	if info.Rejected {
+		return hc.NewRequestError(protocol.ErrorReason_POLICY_REJECTED, nil, "CSR has been rejected")
+	}
+	return hc.NewRequestError(protocol.ErrorReason_INTERNAL, nil, "couldn't compute full signature on certificate")
+}
+
+// statusError turns an error of the request identified by hash into a gRPC status. The status carries an ErrorInfo
+// with the reason of the failure and, if the request has been refused because of overload, a RetryInfo.
+func statusError(hash string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	metadata := map[string]string{"request_id": hash}
+
+	var overloaded *hc.OverloadedError
+	var reqErr *hc.RequestError
+	switch {
+	case errors.As(err, &overloaded):
+		return withDetails(status.New(codes.ResourceExhausted, overloaded.Reason),
+			&errdetails.ErrorInfo{Reason: protocol.ErrorReason_OVERLOADED.String(), Domain: errorDomain, Metadata: metadata},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(overloaded.RetryAfter)})
+
+	case errors.As(err, &reqErr):
+		return withDetails(status.New(codeOf[reqErr.Reason], err.Error()),
+			&errdetails.ErrorInfo{Reason: reqErr.Reason.String(), Domain: errorDomain, Metadata: metadata})
+
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		// the client's own deadline; the request might still be completed and fetched later
+		return status.FromContextError(err).Err()
+
+	default:
+		return withDetails(status.New(codes.Internal, err.Error()),
+			&errdetails.ErrorInfo{Reason: protocol.ErrorReason_INTERNAL.String(), Domain: errorDomain, Metadata: metadata})
+	}
+}
+
+func withDetails(st *status.Status, details ...protoiface.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/logging"
+)
+
+// defaults for shutting down if they aren't set in the config
+const (
+	defaultDrainTimeout = 30 * time.Second
+	stopTimeout         = 5 * time.Second // how long the servers get to stop once the requests have been drained
+)
+
+// shutdownSignals make the node shut down; a second one while draining makes it stop at once.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// service is a server of the node. run blocks until its context is cancelled (and stop has been called, if any).
+type service struct {
+	name string
+	run  func(ctx context.Context) error
+	stop func(ctx context.Context) // makes run return; may be nil
+}
+
+// lifecycle starts the servers of a node with a shared context and shuts them down in order: new requests are
+// refused and the ones in flight drained while all servers keep running, then the servers are stopped and at
+// last the logs of the coordinator are closed.
+type lifecycle struct {
+	coordinator  *hc.Coordinator
+	services     []service
+	drainTimeout time.Duration
+	log          logging.Logger
+}
+
+func newLifecycle(coordinator *hc.Coordinator, opts *hc.Options) *lifecycle {
+	l := &lifecycle{
+		coordinator:  coordinator,
+		drainTimeout: defaultDrainTimeout,
+		log:          logging.New("lifecycle"),
+	}
+	if opts.DrainTimeout > 0 {
+		l.drainTimeout = time.Duration(opts.DrainTimeout) * time.Millisecond
+	}
+	return l
+}
+
+// add registers a service; services are started in the order they have been added and stopped in reverse.
+func (l *lifecycle) add(name string, run func(ctx context.Context) error, stop func(ctx context.Context)) {
+	l.services = append(l.services, service{name: name, run: run, stop: stop})
+}
+
+// run starts all services and blocks until ctx is done or one of the services fails. It then shuts the node
+// down and returns the error of the failed service, if any.
+func (l *lifecycle) run(ctx context.Context) error {
+	srvCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	failed := make(chan error, len(l.services))
+	for _, s := range l.services {
+		wg.Add(1)
+		go func(s service) {
+			defer wg.Done()
+			if err := s.run(srvCtx); err != nil && srvCtx.Err() == nil {
+				failed <- fmt.Errorf("%v failed: %w", s.name, err)
+			}
+		}(s)
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		l.log.Info("Shutting down")
+	case err = <-failed:
+		l.log.Errorf("Shutting down: %v", err)
+	}
+
+	l.drain()
+	l.coordinator.Readiness.Shutdown()
+
+	cancel()
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancelStop()
+	for i := len(l.services) - 1; i >= 0; i-- {
+		if stop := l.services[i].stop; stop != nil {
+			stop(stopCtx)
+		}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-stopCtx.Done():
+		l.log.Warn("Not all servers stopped in time")
+	}
+
+	if closeErr := l.coordinator.Close(); closeErr != nil {
+		l.log.Errorf("Failed to close logs: %v", closeErr)
+	}
+	l.log.Info("Node stopped")
+	return err
+}
+
+// drain waits for the requests in flight until they are completed, the drain timeout has passed or another
+// shutdown signal arrives, and reports what became of them.
+func (l *lifecycle) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.drainTimeout)
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, shutdownSignals...)
+	defer stop()
+
+	report := l.coordinator.Drain(ctx)
+	for _, hash := range report.Completed {
+		logging.With(l.log, "csr", hash[:6]).Debug("Completed while draining")
+	}
+	for _, hash := range report.Abandoned {
+		logging.With(l.log, "csr", hash[:6]).Warn("Abandoned")
+	}
+	l.log.Infof("Drained requests: %v completed, %v abandoned", len(report.Completed), len(report.Abandoned))
+}
+
+// gracefulStop lets the calls in progress finish, unless ctx is done first, and stops srv.
+func gracefulStop(ctx context.Context, srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+}
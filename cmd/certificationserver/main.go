@@ -15,24 +15,32 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"time"
 
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
 	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/audit"
 	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/crypto/keystore"
+	"github.com/raphasch/hotcertification/ctlog"
+	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/metrics"
 	"github.com/raphasch/hotcertification/replication"
 	"github.com/raphasch/hotcertification/signing"
+	"github.com/raphasch/hotcertification/tracing"
 )
 
 func usage() {
 	fmt.Printf("Usage: %s [options]\n", os.Args[0])
 	fmt.Println()
-	fmt.Println("Loads configuration from ./hotcertification.yml and file specified by --config")
+	fmt.Println("Loads configuration from ./hotcertification.toml or the file specified by --config")
 	fmt.Println()
 	fmt.Println("Options:")
 	flag.PrintDefaults()
@@ -43,9 +51,23 @@ func parseOptionsAndConfig() *hc.Options {
 
 	help := flag.BoolP("help", "h", false, "Prints this text.")
 	config := flag.String("config", "", "The path to the config file in case it isn't in working directory.")
-	thresholdkey := flag.String("thresholdkey", "", "The path to the threshold key file")
+	thresholdkey := flag.String("thresholdkey", "", "The path to the threshold key file or the PKCS #11 URI of the token it is kept in")
 	id := flag.Int("id", 0, "The ID of this server.")
+	flag.String("passphrase", "", "Where the passphrase of an encrypted threshold key or the PIN of its token is read from: 'prompt', 'env:NAME' or 'file:PATH'.\nDefaults to $"+crypto.PassphraseEnv+" if it is set and to prompting otherwise.")
 	flag.String("privkey", "", "The path to the ecdsa private key file used for TLS and HotStuff")
+	flag.Int("signing-workers", 4, "The number of threshold signing sessions that are run concurrently.")
+	flag.Int("signing-timeout", 10000, "The time in milliseconds after which a single signing session is aborted.")
+	flag.Int("queue-limit", 10000, "The max number of requests waiting for replication before new ones are refused.")
+	flag.Int("client-queue-limit", 0, "The max number of requests of a single client waiting for replication (0 means unlimited).")
+	flag.Float64("client-rate", 0, "The number of requests per second a single client may submit (0 means unlimited).")
+	flag.Int("request-timeout", 60000, "The time in milliseconds after which a request that hasn't been completed expires.")
+	flag.Int("retention", 3600000, "The time in milliseconds completed requests are kept before they are removed.")
+	flag.Int("replay-window", 100000, "The number of commits a replicated CSR is remembered for to reject replays of it.")
+	flag.Int("drain-timeout", 30000, "The time in milliseconds a node that shuts down waits for the requests in flight.")
+	flag.String("trace-exporter", "", "Where spans are exported to: 'otlp', 'file' or empty to disable tracing.")
+	flag.String("trace-endpoint", "", "The address of the OTLP collector or the path of the trace file.")
+	flag.Int("sth-interval", 1000, "The time in milliseconds between two extensions of the certificate log by the sequencer.")
+	flag.Int("inclusion-wait", 0, "The time in milliseconds a certificate is held back until it has been added to the certificate log; 0 returns it right away.")
 
 	//tls := flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
 
@@ -82,25 +104,20 @@ func parseOptionsAndConfig() *hc.Options {
 		os.Exit(1)
 	}
 
-	viper.SetConfigName("hotcertification")
-	//viper.SetConfigType("yaml")
-	viper.AddConfigPath(".")
+	// read config from the working directory unless another file is given, e.g. one written by keygen
+	if *config != "" {
+		viper.SetConfigFile(*config)
+	} else {
+		viper.SetConfigName("hotcertification")
+		//viper.SetConfigType("yaml")
+		viper.AddConfigPath(".")
+	}
 	err = viper.ReadInConfig()
 	if err != nil {
 		log.Printf("Fatal error config file: %s \n", err)
 		os.Exit(1)
 	}
 
-	// read config if not in working directory
-	if *config != "" {
-		viper.SetConfigFile(*config)
-		err = viper.MergeInConfig()
-		if err != nil {
-			log.Printf("Fatal error config file: %s \n", err)
-			os.Exit(1)
-		}
-	}
-
 	var opts hc.Options
 	err = viper.Unmarshal(&opts)
 	if err != nil {
@@ -108,38 +125,147 @@ func parseOptionsAndConfig() *hc.Options {
 		os.Exit(1)
 	}
 
+	// nothing is started with a configuration that can't work
+	err = hc.Validate(&opts)
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+
 	return &opts
 }
 
 func main() {
 	opts := parseOptionsAndConfig()
 
-	// so program can be stopped with CTRL+C
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	// every log entry tells which node it comes from
+	err := logging.Configure(opts.Logging, "node", opts.ID)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+
+	// so program can be stopped with CTRL+C or by the init system
+	ctx, cancel := signal.NotifyContext(context.Background(), shutdownSignals...)
 	defer cancel()
 
-	thresholdKey, err := crypto.ReadThresholdKeyFile(opts.ThresholdKey)
+	passphrase, err := crypto.PassphraseSource(opts.Passphrase, false)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	keyStore, err := keystore.Open(opts.ThresholdKey, passphrase)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	thresholdKey, err := keyStore.Key()
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	rootCA, err := crypto.ReadCertFile(opts.RootCA)
+	if err != nil {
+		log.Printf("failed to read root-ca: %v", err)
+		os.Exit(1)
+	}
+	err = hc.ValidateKey(opts, thresholdKey, rootCA)
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	// a corrupted share would only show once a client request has to be signed
+	err = crypto.CheckShare(thresholdKey)
+	if err != nil {
+		log.Printf("threshold key failed its self-test: %v", err)
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, opts.TraceExporter, opts.TraceEndpoint, "hotcertification", int(opts.ID))
 	if err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
+	// spans still buffered are flushed before exiting
+	flushSpans := func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("failed to flush spans: %v", err)
+		}
+	}
 
-	coordinator := hc.NewCoordinator()
-	//cmdCache := hc.NewCmdCache(1)
+	coordinator := hc.NewCoordinator(opts)
+	coordinator.Readiness = hc.NewReadiness()
 
-	// Parsing signing node information
-	signingNodes := make([]string, len(opts.Nodes))
-	for i, node := range opts.Nodes {
-		signingNodes[i] = node.SigningSrvAddr
+	if path := opts.Self().AuditLog; path != "" {
+		coordinator.Audit, err = audit.Open(path, opts.CheckpointInterval, configDigest(opts, thresholdKey))
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
 	}
 
+	// tree heads of the certificate log are signed with the key of the CA
+	coordinator.CTLog, err = ctlog.Open(opts.Self().CTLog, thresholdKey.Public())
+	if err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+	//cmdCache := hc.NewCmdCache(1)
+
 	replicationServer := replication.NewReplicationServer(coordinator, opts)
-	signingServer := signing.NewSigningServer(coordinator, thresholdKey, signingNodes, opts.RootCA)
-	clientServer := NewClientServer(coordinator)
+	signingServer := signing.NewSigningServer(coordinator, thresholdKey, opts)
+	clientServer := NewClientServer(coordinator, opts)
+
+	node := opts.Self()
+	lc := newLifecycle(coordinator, opts)
+
+	metrics.RegisterQueue("replication", coordinator.ReplicationQueue.Len)
+	metrics.RegisterQueue("signing", func() int { return len(coordinator.SigningQueue) })
+	if addr := node.MetricsAddr; addr != "" {
+		lc.add("metrics", func(ctx context.Context) error { return metrics.Serve(ctx, addr) }, nil)
+	}
 
-	go replicationServer.Start(ctx, opts.Nodes[opts.ID-1].ReplicationSrvAddr)
-	go signingServer.Start(opts.Nodes[opts.ID-1].SigningSrvAddr)
-	go clientServer.Start(opts.Nodes[opts.ID-1].ClientSrvAddr)
+	lc.add("gc", func(ctx context.Context) error {
+		coordinator.RunGC(ctx)
+		return nil
+	}, nil)
+	lc.add("replication", func(ctx context.Context) error {
+		return replicationServer.Start(ctx, node.ReplicationSrvAddr)
+	}, nil)
+	lc.add("signing", func(ctx context.Context) error {
+		return signingServer.Start(ctx, node.SigningSrvAddr)
+	}, nil)
+	lc.add("client server", func(context.Context) error {
+		return clientServer.Start(node.ClientSrvAddr)
+	}, clientServer.Stop)
 
-	<-ctx.Done()
+	if addr := node.AdminAddr; addr != "" {
+		adminServer, err := NewAdminServer(coordinator, opts, signingServer.Peers)
+		if err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		lc.add("admin server", func(context.Context) error { return adminServer.Start(addr) }, adminServer.Stop)
+	}
+
+	err = lc.run(ctx)
+	if closeErr := keyStore.Close(); closeErr != nil {
+		log.Printf("failed to close key store: %v", closeErr)
+	}
+	flushSpans()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// configDigest identifies the configuration of the cluster in the audit log.
+func configDigest(opts *hc.Options, key *crypto.ThresholdKey) []byte {
+	h := sha256.New()
+	for _, node := range opts.Nodes {
+		fmt.Fprintf(h, "%v %v %v %v %v\n", node.ID, node.PubKey, node.ClientSrvAddr, node.ReplicationSrvAddr, node.SigningSrvAddr)
+	}
+	fmt.Fprintf(h, "%x %v %v %v\n", key.Public().N, key.Public().E, key.KeyMeta.K, key.KeyMeta.L)
+	return h.Sum(nil)
 }
@@ -19,19 +19,36 @@ import (
 
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	hc "github.com/raphasch/hotcertification"
 	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/ctlog"
 	pb "github.com/raphasch/hotcertification/protocol"
+	"github.com/raphasch/hotcertification/tracing"
 )
 
 type options struct {
 	TLS         bool   `mapstructure:"tls"`
 	RootCA      string `mapstructure:"root-ca"`
 	ServerAddr  string `mapstructure:"server-addr"`
+	Async       bool   `mapstructure:"async"`
+	RequestID   string `mapstructure:"request-id"`
 	Destination string `mapstructure:"destination"`
+
+	TraceExporter string `mapstructure:"trace-exporter"`
+	TraceEndpoint string `mapstructure:"trace-endpoint"`
 }
 
+// endTrace ends the span of the request and exports the remaining spans.
+// fail calls it as well since deferred functions don't run when exiting.
+var endTrace = func() {}
+
 func usage() {
 	fmt.Printf("Usage: %s [options] [destination]\n", os.Args[0])
 	fmt.Println()
@@ -44,8 +61,12 @@ func parseOptions() options {
 
 	help := flag.BoolP("help", "h", false, "Prints this text.")
 	flag.Bool("tls", false, "Connection uses TLS if true, else plain TCP")
-	flag.String("root-ca", "", "The file containing the root CA  cert file")
+	flag.String("root-ca", "", "The file containing the root CA  cert file; the inclusion proof of the certificate is verified with it")
 	flag.String("server-addr", "localhost:8081", "The server address in the format of host:port")
+	flag.Bool("async", false, "Submit the CSR and poll for the certificate instead of waiting on a single call")
+	flag.String("request-id", "", "Fetch the certificate of a previously submitted request from any node instead of sending a new CSR")
+	flag.String("trace-exporter", "", "Where spans are exported to: 'otlp', 'file' or empty to disable tracing")
+	flag.String("trace-endpoint", "", "The address of the OTLP collector or the path of the trace file")
 
 	flag.Parse()
 
@@ -95,6 +116,23 @@ func main() {
 		gRPC_opts = append(gRPC_opts, grpc.WithInsecure())
 	}
 	gRPC_opts = append(gRPC_opts, grpc.WithBlock())
+	// the trace context is sent along so that the spans of the nodes are linked to the client's
+	gRPC_opts = append(gRPC_opts,
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()),
+	)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), opts.TraceExporter, opts.TraceEndpoint, "hotcertification-client", 0)
+	if err != nil {
+		log.Fatalf("failed to set up tracing: %v", err)
+	}
+	flushSpans := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(ctx)
+	}
+	endTrace = flushSpans
+	defer func() { endTrace() }()
 
 	// gprc start connection
 	conn, err := grpc.Dial(opts.ServerAddr, gRPC_opts...)
@@ -105,36 +143,75 @@ func main() {
 	defer conn.Close()
 	hotcertification := pb.NewCertificationClient(conn)
 
-	// generate private and public key for certificate
-	keySize := 512
-	clientKey, err := rsa.GenerateKey(rand.Reader, keySize)
-	if err != nil {
-		log.Fatalf("failed to generate client keys: %v", err)
-		return
-	}
-
-	// creating CSR with client public key
-	clientCSR, err := generateCSR(clientKey)
-	if err != nil {
-		log.Fatalf("failed to generate CSR: %v", err)
-		return
-	}
-
 	// Adding some context
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
 
-	csr := &pb.CSR{
-		ClientID:           8,
-		CertificateRequest: clientCSR.Raw,
-		ValidationInfo:     make([]byte, 100),
-	}
+	var response *pb.Certificate
+	var id *pb.RequestID
+	if opts.RequestID != "" {
+		// collecting the result of an earlier submission
+		id = &pb.RequestID{ID: opts.RequestID}
+		ctx, span := tracing.Start(ctx, opts.RequestID, "fetch certificate", trace.WithSpanKind(trace.SpanKindClient))
+		endTrace = func() { span.End(); flushSpans() }
+		response, err = awaitCertificate(ctx, hotcertification, id)
+		if err != nil {
+			fail("failed to fetch certificate", err)
+		}
+	} else {
+		// generate private and public key for certificate
+		keySize := 512
+		clientKey, err := rsa.GenerateKey(rand.Reader, keySize)
+		if err != nil {
+			log.Fatalf("failed to generate client keys: %v", err)
+			return
+		}
 
-	// putting CSR into protocol buffers format and calling remote function
-	response, err := hotcertification.GetCertificate(ctx, csr)
-	if err != nil {
-		log.Fatalf("failed to call RPC: %v", err)
-		return
+		// creating CSR with client public key
+		clientCSR, err := generateCSR(clientKey)
+		if err != nil {
+			log.Fatalf("failed to generate CSR: %v", err)
+			return
+		}
+
+		csr := &pb.CSR{
+			ClientID:           8,
+			CertificateRequest: clientCSR.Raw,
+			ValidationInfo:     make([]byte, 100),
+		}
+
+		id = &pb.RequestID{ID: hc.HashCSR(csr)}
+
+		// the span is put into the trace of the request so that the spans of all nodes end up in the same trace
+		ctx, span := tracing.Start(ctx, id.ID, "certify", trace.WithSpanKind(trace.SpanKindClient))
+		endTrace = func() { span.End(); flushSpans() }
+
+		if opts.Async {
+			err = retryOverloaded(ctx, func() (err error) {
+				id, err = hotcertification.SubmitCSR(ctx, csr)
+				return err
+			})
+			if err != nil {
+				fail("failed to request certificate", err)
+			}
+			fmt.Println("Submitted request", id.ID)
+
+			go printProgress(ctx, hotcertification, id)
+
+			response, err = awaitCertificate(ctx, hotcertification, id)
+			if err != nil {
+				fail("failed to fetch certificate", err)
+			}
+		} else {
+			// putting CSR into protocol buffers format and calling remote function
+			err = retryOverloaded(ctx, func() (err error) {
+				response, err = hotcertification.GetCertificate(ctx, csr)
+				return err
+			})
+			if err != nil {
+				fail("failed to request certificate", err)
+			}
+		}
 	}
 
 	certificate, err := x509.ParseCertificate(response.Certificate)
@@ -145,12 +222,179 @@ func main() {
 
 	// TODO: verify signature with root certificate
 
+	if response.ProofPending {
+		fmt.Println("Waiting for the certificate to be added to the certificate log")
+		response = awaitProof(ctx, hotcertification, id, response)
+	}
+	if response.ProofPending {
+		fmt.Println("Certificate hasn't been added to the certificate log yet; fetch it again with --request-id", id.ID, "for its inclusion proof")
+	} else if response.Proof != nil && opts.RootCA != "" {
+		err = verifyInclusion(opts.RootCA, response)
+		if err != nil {
+			log.Fatalf("failed to verify inclusion proof: %v", err)
+		}
+		fmt.Printf("Verified that the certificate is entry %v of the certificate log of size %v\n",
+			response.Proof.LeafIndex, response.Proof.STH.GetTreeSize())
+	}
+
 	// Write certificate to file
 	crypto.WriteCertFile(certificate, opts.Destination)
 
 	fmt.Println("Wrote certificate to file")
 }
 
+// verifyInclusion checks the inclusion proof of the certificate with the public key of the root certificate.
+func verifyInclusion(rootCAFile string, response *pb.Certificate) error {
+	rootCA, err := crypto.ReadCertFile(rootCAFile)
+	if err != nil {
+		return err
+	}
+	pub, ok := rootCA.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("root certificate doesn't contain an RSA key")
+	}
+	return ctlog.VerifyCertificate(pub, response.Certificate, ctlog.ProofFromProto(response.Proof))
+}
+
+// awaitCertificate polls the status of a request until its certificate has been issued and then fetches it.
+func awaitCertificate(ctx context.Context, client pb.CertificationClient, id *pb.RequestID) (*pb.Certificate, error) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		reqStatus, err := client.GetRequestStatus(ctx, id)
+		// the node might not know about the request until it has been replicated
+		if err != nil && status.Code(err) != codes.NotFound {
+			return nil, err
+		}
+
+		switch {
+		// fetching returns the reason of the failure as well
+		case reqStatus.GetState() == pb.RequestState_REJECTED, reqStatus.GetReason() != pb.ErrorReason_NONE:
+			return client.FetchCertificate(ctx, id)
+		case reqStatus.GetState() == pb.RequestState_SIGNED || reqStatus.GetState() == pb.RequestState_RETURNED:
+			return client.FetchCertificate(ctx, id)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// awaitProof fetches the certificate of a request again until it comes with its inclusion proof, since the CA returns
+// certificates before they have been added to its certificate log unless it is configured to wait. The last response
+// is returned if ctx is done before.
+func awaitProof(ctx context.Context, client pb.CertificationClient, id *pb.RequestID, response *pb.Certificate) *pb.Certificate {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for response.GetProofPending() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return response
+		}
+		// the node might be busy; it is asked again until ctx is done
+		if fetched, err := client.FetchCertificate(ctx, id); err == nil {
+			response = fetched
+		}
+	}
+	return response
+}
+
+// fail prints why a call to the CA failed and what can be done about it, and exits.
+func fail(what string, err error) {
+	endTrace()
+	fmt.Fprintf(os.Stderr, "%v: %v\n", what, status.Convert(err).Message())
+	if hint := hint(err); hint != "" {
+		fmt.Fprintln(os.Stderr, hint)
+	}
+	os.Exit(1)
+}
+
+// hint returns advice on how to deal with an error returned by the CA.
+func hint(err error) string {
+	st := status.Convert(err)
+
+	var reason string
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			reason = info.GetReason()
+		}
+	}
+
+	switch reason {
+	case pb.ErrorReason_INVALID_CSR.String():
+		return "The CSR is malformed or its signature doesn't match the public key in it. Generate a new CSR."
+	case pb.ErrorReason_POLICY_REJECTED.String():
+		return "The CA isn't willing to sign this CSR. Check its subject and the validation info attached to it."
+	case pb.ErrorReason_CONSENSUS_TIMEOUT.String():
+		return "The cluster didn't replicate the request in time, probably because too few nodes are up. " +
+			"Try again once the cluster has recovered."
+	case pb.ErrorReason_INSUFFICIENT_SHARES.String():
+		return "Too few nodes contributed a signature share in time, probably because too few nodes are up. " +
+			"Try again once the cluster has recovered."
+	case pb.ErrorReason_INTERNAL.String():
+		return "The node failed internally. Check its log or send the CSR to another node with --server-addr."
+	case pb.ErrorReason_CT_UNAVAILABLE.String():
+		return "Too few Certificate Transparency logs accepted the precertificate. Try again once they are reachable."
+	case pb.ErrorReason_PAUSED.String():
+		return "An operator paused issuance on this node. Send the CSR to another node with --server-addr."
+	case pb.ErrorReason_NOT_READY.String():
+		return "The node can't reach enough of the cluster yet. Try again in a moment or send the CSR to another node with --server-addr."
+	}
+
+	switch st.Code() {
+	case codes.DeadlineExceeded:
+		return "No answer in time. The certificate might still be issued; with --async the request ID is printed " +
+			"so that the certificate can be fetched later with --request-id."
+	case codes.Unavailable:
+		return "The node can't be reached. Check --server-addr or try another node."
+	case codes.NotFound:
+		return "The node doesn't know the request (yet). Check the request ID or ask the node the CSR has been sent to."
+	}
+	return ""
+}
+
+// retryOverloaded calls rpc again as long as the server refuses it because it is overloaded,
+// waiting as long as the server asks for in between.
+func retryOverloaded(ctx context.Context, rpc func() error) error {
+	for {
+		err := rpc()
+		if status.Code(err) != codes.ResourceExhausted {
+			return err
+		}
+
+		wait := pb.RetryDelay(err)
+		fmt.Printf("Server is busy (%v); retrying in %v\n", status.Convert(err).Message(), wait)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// printProgress prints the events of a request until its certificate has been issued.
+func printProgress(ctx context.Context, client pb.CertificationClient, id *pb.RequestID) {
+	stream, err := client.WatchRequest(ctx, id)
+	if err != nil {
+		return
+	}
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return
+		}
+		fmt.Printf("[%v] %v\n", event.State, event.Message)
+	}
+}
+
 func generateCSR(clientPrivKey *rsa.PrivateKey) (csr *x509.CertificateRequest, err error) {
 	csrTmpl := &x509.CertificateRequest{
 		SignatureAlgorithm: x509.SHA256WithRSA,
@@ -0,0 +1,254 @@
+// hcctl inspects and controls a running node through its admin service.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	flag "github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	pb "github.com/raphasch/hotcertification/protocol"
+)
+
+func usage() {
+	fmt.Printf("Usage: %s [options] command [arguments]\n", os.Args[0])
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  status              Shows the health, consensus state, queues and peers of the node")
+	fmt.Println("  requests            Lists the requests known to the node, the most recent first")
+	fmt.Println("  request <id>        Shows a request and its events")
+	fmt.Println("  pause [reason]      Makes the node refuse new requests")
+	fmt.Println("  resume              Lets new requests in again")
+	fmt.Println()
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+
+	help := flag.BoolP("help", "h", false, "Prints this text.")
+	addr := flag.String("addr", "localhost:7081", "The address of the admin service of the node")
+	cert := flag.String("cert", "", "The client certificate of the operator")
+	key := flag.String("key", "", "The private key of the client certificate")
+	ca := flag.String("ca", "", "The CA the certificate of the admin service is verified with")
+	serverName := flag.String("server-name", "", "The name in the certificate of the admin service if it differs from the host of --addr")
+	timeout := flag.Duration("timeout", 10*time.Second, "How long to wait for the node")
+	states := flag.StringSlice("state", nil, "Only list requests in these states, e.g. SIGNED,REJECTED")
+	limit := flag.Uint32("limit", 50, "The max number of requests listed (0 lists all)")
+	out := flag.String("out", "", "Write the certificate of the request to this file")
+
+	flag.Parse()
+
+	if *help {
+		usage()
+		os.Exit(0)
+	}
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(1)
+	}
+	if *cert == "" || *key == "" || *ca == "" {
+		log.Fatal("the admin service needs --cert, --key and --ca")
+	}
+
+	creds, err := clientCredentials(*cert, *key, *ca, *serverName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, *addr, grpc.WithTransportCredentials(creds), grpc.WithBlock())
+	if err != nil {
+		log.Fatalf("failed to dial %v: %v", *addr, err)
+	}
+	defer conn.Close()
+	admin := pb.NewAdminClient(conn)
+
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "status":
+		st, err := admin.GetNodeStatus(ctx, &pb.NodeStatusRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+		printStatus(os.Stdout, st)
+
+	case "requests":
+		req := &pb.ListRequestsRequest{Limit: *limit}
+		for _, s := range *states {
+			state, ok := pb.RequestState_value[strings.ToUpper(s)]
+			if !ok {
+				log.Fatalf("unknown state %v", s)
+			}
+			req.States = append(req.States, pb.RequestState(state))
+		}
+		list, err := admin.ListRequests(ctx, req)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printRequests(os.Stdout, list.Requests)
+
+	case "request":
+		if len(args) != 1 {
+			log.Fatal("usage: request <id>")
+		}
+		details, err := admin.GetRequest(ctx, &pb.RequestID{ID: args[0]})
+		if err != nil {
+			log.Fatal(err)
+		}
+		printRequest(os.Stdout, details)
+		if *out != "" {
+			if details.Certificate == nil {
+				log.Fatal("no certificate has been issued for the request")
+			}
+			certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: details.Certificate})
+			if err := os.WriteFile(*out, certPEM, 0644); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+	case "pause":
+		st, err := admin.PauseIssuance(ctx, &pb.PauseRequest{Reason: strings.Join(args, " ")})
+		if err != nil {
+			log.Fatal(err)
+		}
+		printStatus(os.Stdout, st)
+
+	case "resume":
+		st, err := admin.ResumeIssuance(ctx, &pb.ResumeRequest{})
+		if err != nil {
+			log.Fatal(err)
+		}
+		printStatus(os.Stdout, st)
+
+	default:
+		fmt.Printf("Unknown command %v\n\n", cmd)
+		usage()
+		os.Exit(1)
+	}
+}
+
+// clientCredentials returns the TLS credentials the operator authenticates with.
+func clientCredentials(certFile, keyFile, caFile, serverName string) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate in %v", caFile)
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+func printStatus(w io.Writer, st *pb.NodeStatus) {
+	health := "healthy"
+	if !st.Healthy {
+		health = "unhealthy"
+	}
+	fmt.Fprintf(w, "Node %v is %v (up %v)\n", st.ID, health, (time.Duration(st.Uptime) * time.Millisecond).Round(time.Second))
+	for _, problem := range st.Problems {
+		fmt.Fprintf(w, "  - %v\n", problem)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "View:\t%v\n", st.View)
+	fmt.Fprintf(tw, "Leader:\t%v\n", st.Leader)
+	if st.Paused {
+		fmt.Fprintf(tw, "Issuance:\tpaused (%v)\n", st.PauseReason)
+	} else {
+		fmt.Fprintf(tw, "Issuance:\trunning\n")
+	}
+	fmt.Fprintf(tw, "Replication queue:\t%v\n", st.ReplicationQueue)
+	fmt.Fprintf(tw, "Signing queue:\t%v\n", st.SigningQueue)
+	fmt.Fprintf(tw, "Stored requests:\t%v\n", st.Requests)
+	fmt.Fprintf(tw, "Certificate log size:\t%v\n", st.TreeSize)
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUBSYSTEM\tREADY\tSINCE\tREASON")
+	for _, sub := range st.Subsystems {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", sub.Name, sub.Ready, timeOf(sub.Since), sub.Reason)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PEER\tADDRESS\tCONNECTED\tLATENCY\tLAST ERROR")
+	for _, p := range st.Peers {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", p.ID, p.Address, p.Connected, time.Duration(p.Latency)*time.Microsecond, p.LastError)
+	}
+	tw.Flush()
+}
+
+func printRequests(w io.Writer, requests []*pb.RequestSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCLIENT\tSTATE\tSUBMITTED\tREASON")
+	for _, r := range requests {
+		reason := ""
+		if r.Reason != pb.ErrorReason_NONE {
+			reason = r.Reason.String()
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", r.ID[:12], r.ClientID, r.State, timeOf(r.Submitted), reason)
+	}
+	tw.Flush()
+}
+
+func printRequest(w io.Writer, details *pb.RequestDetails) {
+	r := details.Summary
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID:\t%v\n", r.ID)
+	fmt.Fprintf(tw, "Client:\t%v\n", r.ClientID)
+	fmt.Fprintf(tw, "State:\t%v\n", r.State)
+	if r.Reason != pb.ErrorReason_NONE {
+		fmt.Fprintf(tw, "Error:\t%v (%v)\n", r.Error, r.Reason)
+	}
+	fmt.Fprintf(tw, "Submitted:\t%v\n", timeOf(r.Submitted))
+	fmt.Fprintf(tw, "Committed:\t%v\n", timeOf(r.Committed))
+	fmt.Fprintf(tw, "Completed:\t%v\n", timeOf(r.Completed))
+	fmt.Fprintf(tw, "Deadline:\t%v\n", timeOf(r.Deadline))
+	if cert, err := x509.ParseCertificate(details.Certificate); err == nil {
+		fmt.Fprintf(tw, "Certificate:\tserial %x, subject %v\n", cert.SerialNumber, cert.Subject)
+	}
+	tw.Flush()
+
+	if len(details.Events) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Events:")
+		for _, e := range details.Events {
+			fmt.Fprintf(w, "  %-10v %v\n", e.State, e.Message)
+		}
+	}
+}
+
+// timeOf formats a time in milliseconds since the epoch.
+func timeOf(ms uint64) string {
+	if ms == 0 {
+		return "-"
+	}
+	return time.Unix(0, int64(ms)*int64(time.Millisecond)).Format(time.RFC3339)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+// ceremony runs keygen step by step: every step waits for the operator, the secrets of each node are written to a
+// medium of their own and everything that happens is recorded in a transcript that is signed with the new key.
+type ceremony struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// media is where the medium of a node is mounted; "{id}" is replaced with the ID of the node. If it is empty,
+	// the secrets are written to the directories of the nodes in the destination.
+	media      string
+	imported   bool
+	transcript *crypto.Transcript
+}
+
+func newCeremony(in io.Reader, out io.Writer, media string, imported bool) *ceremony {
+	return &ceremony{in: bufio.NewReader(in), out: out, media: media, imported: imported}
+}
+
+// confirm tells the operator what happens next and waits until they press Enter.
+func (c *ceremony) confirm(format string, args ...interface{}) error {
+	fmt.Fprintf(c.out, format+" Press Enter to continue.", args...)
+	_, err := c.in.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("ceremony aborted: %w", err)
+	}
+	return nil
+}
+
+// log records a step in the transcript once it has been started.
+func (c *ceremony) log(format string, args ...interface{}) {
+	if c.transcript != nil {
+		c.transcript.Log(format, args...)
+	}
+}
+
+// start starts the transcript once the keys have been generated.
+func (c *ceremony) start(keys []*crypto.ThresholdKey, root *x509.Certificate) error {
+	var err error
+	c.transcript, err = crypto.NewTranscript(keys, root, c.imported)
+	if err != nil {
+		return err
+	}
+	if c.imported {
+		c.log("split the imported key into %v shares with a threshold of %v", len(keys), keys[0].KeyMeta.K)
+	} else {
+		c.log("generated a key of %v bits split into %v shares with a threshold of %v", c.transcript.KeySize, len(keys), keys[0].KeyMeta.K)
+	}
+	return nil
+}
+
+// medium returns the node with its secrets on its medium.
+func (c *ceremony) medium(n node) node {
+	if c.media == "" {
+		return n
+	}
+	return n.in(strings.ReplaceAll(c.media, "{id}", strconv.Itoa(n.ID)))
+}
+
+// transcriptFile is where the transcript of the cluster is written.
+func transcriptFile(c cluster) string {
+	return filepath.Join(c.Destination, "transcript.json")
+}
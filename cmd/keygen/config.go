@@ -0,0 +1,130 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var (
+	//go:embed hotcertification.toml.tmpl
+	configTemplate string
+	//go:embed run_servers.sh.tmpl
+	scriptTemplate string
+
+	templates = template.Must(template.New("hotcertification.toml").Parse(configTemplate))
+	_         = template.Must(templates.New("run_servers.sh").Parse(scriptTemplate))
+)
+
+// node is everything the configuration holds about a single node. All paths are relative to the directory keygen
+// has been run in, which is the directory the nodes have to be started in.
+type node struct {
+	ID                 int
+	Host               string
+	PubKey             string
+	TLSCert            string
+	ClientSrvAddr      string
+	ReplicationSrvAddr string
+	SigningSrvAddr     string
+	MetricsAddr        string
+	AuditLog           string
+	CTLog              string
+
+	// secrets that are only handed to the node itself; they are passed on the command line, not in the config
+	Dir          string
+	ThresholdKey string
+	PrivKey      string
+}
+
+// cluster is the configuration that is rendered into hotcertification.toml and run_servers.sh.
+type cluster struct {
+	Destination string
+	Config      string
+	RootCA      string
+	TLSCA       string
+	KeySize     int
+	Nodes       []node
+}
+
+// ports are the base ports of the servers of a node; node i listens on base port + i.
+type ports struct {
+	Client      int
+	Replication int
+	Signing     int
+	Metrics     int
+}
+
+// newCluster lays out a cluster of n nodes whose files are written to dest. host is the host of the nodes,
+// in which "{id}" is replaced with the ID of the node.
+func newCluster(dest string, n int, keySize int, host string, p ports) cluster {
+	c := cluster{
+		Destination: dest,
+		Config:      filepath.Join(dest, "hotcertification.toml"),
+		RootCA:      filepath.Join(dest, "root.crt"),
+		TLSCA:       filepath.Join(dest, "tls-ca.crt"),
+		KeySize:     keySize,
+	}
+	for id := 1; id <= n; id++ {
+		name := fmt.Sprintf("n%v", id)
+		h := hostOf(host, id)
+		nd := node{
+			ID:                 id,
+			Host:               h,
+			PubKey:             filepath.Join(dest, name+".key.pub"),
+			TLSCert:            filepath.Join(dest, name+".crt"),
+			ClientSrvAddr:      net.JoinHostPort(h, strconv.Itoa(p.Client+id)),
+			ReplicationSrvAddr: net.JoinHostPort(h, strconv.Itoa(p.Replication+id)),
+			SigningSrvAddr:     net.JoinHostPort(h, strconv.Itoa(p.Signing+id)),
+			MetricsAddr:        net.JoinHostPort(h, strconv.Itoa(p.Metrics+id)),
+			AuditLog:           filepath.Join("audit", name+".log"),
+			CTLog:              filepath.Join("ctlog", name+".log"),
+		}
+		c.Nodes = append(c.Nodes, nd.in(filepath.Join(dest, name)))
+	}
+	return c
+}
+
+// in returns the node with its secrets in dir instead.
+func (n node) in(dir string) node {
+	name := fmt.Sprintf("n%v", n.ID)
+	n.Dir = dir
+	n.ThresholdKey = filepath.Join(dir, name+".thresholdkey")
+	n.PrivKey = filepath.Join(dir, name+".key")
+	return n
+}
+
+// hostOf returns the host of the node with the given ID.
+func hostOf(host string, id int) string {
+	return strings.ReplaceAll(host, "{id}", strconv.Itoa(id))
+}
+
+// writeConfig renders the config file and the script that starts all nodes locally.
+func writeConfig(c cluster) error {
+	if err := render("hotcertification.toml", c.Config, 0644, c); err != nil {
+		return err
+	}
+	return render("run_servers.sh", filepath.Join(c.Destination, "run_servers.sh"), 0755, c)
+}
+
+func render(name, path string, perm os.FileMode, c cluster) (err error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	if err = templates.ExecuteTemplate(file, name, c); err != nil {
+		return fmt.Errorf("cannot render %v: %w", path, err)
+	}
+	return nil
+}
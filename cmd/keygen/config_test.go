@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	hc "github.com/raphasch/hotcertification"
+)
+
+// TestWriteConfig checks that the nodes accept the config that is written for them.
+func TestWriteConfig(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	c := newCluster("keys", 7, 1024, "node{id}.example.com", ports{Client: 8080, Replication: 13370, Signing: 23370, Metrics: 9090})
+	touch := func(path string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	touch(c.RootCA)
+	for _, n := range c.Nodes {
+		for _, path := range []string{n.PubKey, n.TLSCert, n.ThresholdKey, n.PrivKey} {
+			touch(path)
+		}
+	}
+	if err := writeConfig(c); err != nil {
+		t.Fatal(err)
+	}
+
+	v := viper.New()
+	v.SetConfigFile(c.Config)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	var opts hc.Options
+	if err := v.Unmarshal(&opts); err != nil {
+		t.Fatal(err)
+	}
+	opts.ID = 5
+	opts.ThresholdKey = c.Nodes[4].ThresholdKey
+	opts.PrivKey = c.Nodes[4].PrivKey
+	if err := hc.Validate(&opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(opts.Nodes) != 7 || opts.KeySize != 1024 || opts.RootCA != "keys/root.crt" {
+		t.Fatalf("got %v nodes, key-size %v and root-ca %v", len(opts.Nodes), opts.KeySize, opts.RootCA)
+	}
+	self := opts.Self()
+	if self.ClientSrvAddr != "node5.example.com:8085" || self.PubKey != "keys/n5.key.pub" {
+		t.Errorf("node 5 has client-srv-address %v and pubkey %v", self.ClientSrvAddr, self.PubKey)
+	}
+
+	script, err := os.ReadFile(filepath.Join("keys", "run_servers.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--config keys/hotcertification.toml --id 5 --thresholdkey keys/n5/n5.thresholdkey --privkey keys/n5/n5.key"
+	if !strings.Contains(string(script), want) {
+		t.Errorf("run_servers.sh doesn't start node 5 with %q:\n%s", want, script)
+	}
+}
+
+// TestExampleConfig checks that the example config in the root of the repository is what keygen -n 4 keys writes
+// with the default flags, so that both don't drift apart. A change to the template has to be rendered into it.
+func TestExampleConfig(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("..", "..", "hotcertification.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newCluster("keys", 4, 512, "127.0.0.1", ports{Client: 8080, Replication: 13370, Signing: 23370, Metrics: 9090})
+	var got bytes.Buffer
+	if err := templates.ExecuteTemplate(&got, "hotcertification.toml", c); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != string(want) {
+		t.Errorf("hotcertification.toml isn't the rendered template; it should be:\n%s", got.String())
+	}
+}
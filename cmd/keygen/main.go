@@ -8,37 +8,62 @@
 			Every saves this certificate to issue new partially signed certificates.
 			The Gateway/Aggregator then combines these partial signatures and issues a completely normal looking certificate to the client
 
-		3. Create TLS certificates for secure communication between nodes with root certificate
-		4. Computes HotStuff ecdsa keys for replication/consensus logic
-		5. Marshall/Write root cert, tls certs, hotstuff priv and pub keys and threshold keys to files
-*/
+		3. Computes HotStuff ecdsa keys for replication/consensus logic
+		4. Create TLS certificates for secure communication between nodes with a TLS CA
+		5. Marshall/Write root cert, tls certs, hotstuff priv and pub keys and threshold keys to files;
+		   the secrets of each node go into a directory of their own
+		6. Write the config of the cluster and a script that runs it locally
+
+		With --import-key and --import-cert the key of an existing CA is split into the threshold keys instead
+		and its certificate is kept as the root certificate.
 
-// TODO: add keygen functions for HotStuff
+		With --ceremony every step waits for the operator and a signed transcript of the ceremony is written, which
+		node operators check their shares against with "keygen verify".
+*/
 
 package main
 
 import (
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/relab/hotstuff/crypto/keygen"
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	hc "github.com/raphasch/hotcertification"
 	"github.com/raphasch/hotcertification/crypto"
 )
 
 type options struct {
-	Num         uint16 `mapstructure:"num"`
-	Threshold   uint16 `mapstructure:"threshold"`
-	KeySize     int    `mapstructure:"key-size"`
-	Destination string
-	//TLS     bool // if TLS enabled then also generates tls certificates signed by root CA and writes to file. TODO:
+	Num             uint16 `mapstructure:"num"`
+	Threshold       uint16 `mapstructure:"threshold"`
+	KeySize         int    `mapstructure:"key-size"`
+	Hash            string `mapstructure:"hash"`
+	Host            string `mapstructure:"host"`
+	ClientPort      int    `mapstructure:"client-port"`
+	ReplicationPort int    `mapstructure:"replication-port"`
+	SigningPort     int    `mapstructure:"signing-port"`
+	MetricsPort     int    `mapstructure:"metrics-port"`
+	Passphrase      string `mapstructure:"passphrase"`
+	ImportKey       string `mapstructure:"import-key"`
+	ImportCert      string `mapstructure:"import-cert"`
+	Ceremony        bool   `mapstructure:"ceremony"`
+	Media           string `mapstructure:"media"`
+	Destination     string
 }
 
 func usage() {
 	fmt.Printf("Usage: %s [options] [destination]\n", os.Args[0])
+	fmt.Printf("       %s verify [options] thresholdkey\n", os.Args[0])
+	fmt.Println()
+	fmt.Println("Writes the keys and certificates of a cluster, its hotcertification.toml and run_servers.sh to destination.")
+	fmt.Println("The secrets of node i are written to destination/ni; hand each node only its own directory.")
 	fmt.Println()
 	fmt.Println("Options:")
 	flag.PrintDefaults()
@@ -48,21 +73,32 @@ func parseOptions() (options, string) {
 
 	flag.Usage = usage
 	help := flag.BoolP("help", "h", false, "Prints this text.")
-	flag.Uint16P("num", "n", 3, "The number of nodes to generate keys/certs for.")
-	flag.Uint16P("threshold", "t", 2, "The threshold of nodes that can generate a valid signature on a certificate.")
-	flag.Int("key-size", 512, "The size of the RSA private key from which the threshold keys are generated. Has to be one of 512/1024/2048/4096.")
+	flag.Uint16P("num", "n", 4, "The number of nodes to generate keys/certs for.")
+	flag.Uint16P("threshold", "t", 0, "The threshold of nodes that can generate a valid signature on a certificate. Defaults to a quorum (n-f), which is what the nodes expect.")
+	flag.Int("key-size", 512, "The size of the RSA private key from which the threshold keys are generated, e.g. 2048, 3072 or 4096.\nLarge keys take long to generate; 512 bits are only good for testing.")
+	flag.String("hash", "sha256", "The hash function the CA signs with: sha256, sha384 or sha512. SHA-512 needs a key of more than 512 bits.")
+	flag.String("host", "127.0.0.1", "The host of the nodes; {id} is replaced with the ID of a node, e.g. 'node{id}.example.com'.")
+	flag.Int("client-port", 8080, "The base port of the client servers; node i listens on the base port + i.")
+	flag.Int("replication-port", 13370, "The base port of the replication servers.")
+	flag.Int("signing-port", 23370, "The base port of the signing servers.")
+	flag.Int("metrics-port", 9090, "The base port of the metrics servers.")
+	flag.String("passphrase", "prompt", "Where the passphrases the threshold keys are encrypted with are read from: 'prompt' (one per node),\n'fd:N' (one line per node), 'env:NAME' or 'file:PATH' (the same for all nodes) or 'none' to write them unencrypted.")
+	flag.String("import-key", "", "Split the existing RSA key of a CA in this PEM file (PKCS #1 or PKCS #8) instead of generating one; needs --import-cert.")
+	flag.String("import-cert", "", "The certificate of the CA whose key is imported; it becomes the root certificate of the cluster.")
+	flag.Bool("ceremony", false, "Runs a key ceremony: waits for the operator before every step, writes the secrets of each node to\na medium of its own and writes a transcript signed with the new key to destination/transcript.json.")
+	flag.String("media", "", "Where the medium of a node is mounted in a ceremony; {id} is replaced with the ID of the node.\nDefaults to the directories of the nodes in destination.")
 	flag.Parse()
 
-	if flag.NArg() < 1 {
-		usage()
-		os.Exit(1)
-	}
-
 	if *help {
 		flag.Usage()
 		os.Exit(0)
 	}
 
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(1)
+	}
+
 	err := viper.BindPFlags(flag.CommandLine)
 	if err != nil {
 		log.Print(err)
@@ -80,21 +116,239 @@ func parseOptions() (options, string) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		os.Exit(verify(os.Args[2:]))
+	}
 	opts, dest := parseOptions()
 
+	if opts.Num == 0 {
+		log.Fatal("A cluster needs at least one node.")
+	}
+	if quorum := uint16(hc.QuorumSize(int(opts.Num))); opts.Threshold == 0 {
+		opts.Threshold = quorum
+	} else if opts.Threshold != quorum {
+		log.Printf("Warning: the nodes only accept a threshold of %v for %v nodes.", quorum, opts.Num)
+	}
+
+	hashType, err := parseHash(opts.Hash)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var newCA caFunc = func(t, n uint16, keySize int) ([]*crypto.ThresholdKey, *x509.Certificate, error) {
+		return crypto.GenerateCA(t, n, keySize, hashType)
+	}
+	if opts.ImportKey != "" || opts.ImportCert != "" {
+		newCA, opts.KeySize, err = importCA(opts.ImportKey, opts.ImportCert, hashType)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	c := newCluster(dest, int(opts.Num), opts.KeySize, opts.Host, ports{
+		Client:      opts.ClientPort,
+		Replication: opts.ReplicationPort,
+		Signing:     opts.SigningPort,
+		Metrics:     opts.MetricsPort,
+	})
+	passphrase, err := crypto.PassphraseSource(opts.Passphrase, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if passphrase == nil {
+		log.Print("Warning: the threshold keys are written unencrypted.")
+	}
+
+	var cer *ceremony
+	if opts.Ceremony {
+		cer = newCeremony(os.Stdin, os.Stdout, opts.Media, opts.ImportKey != "")
+	} else if opts.Media != "" {
+		log.Fatal("--media is only used in a --ceremony.")
+	}
+
+	if err := generate(c, opts.Threshold, passphrase, newCA, cer); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Wrote the configuration of %v nodes to %v; start them with %v.\n", opts.Num, c.Config, filepath.Join(dest, "run_servers.sh"))
+}
+
+// caFunc returns the n threshold keys of a CA of which t are needed to sign, and its root certificate.
+type caFunc func(t, n uint16, keySize int) ([]*crypto.ThresholdKey, *x509.Certificate, error)
+
+// parseHash returns the hash function called name.
+func parseHash(name string) (stdcrypto.Hash, error) {
+	switch name {
+	case "sha256":
+		return stdcrypto.SHA256, nil
+	case "sha384":
+		return stdcrypto.SHA384, nil
+	case "sha512":
+		return stdcrypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("unknown hash function '%s'; use sha256, sha384 or sha512", name)
+}
+
+// importCA reads the key and certificate of an existing CA and returns the function that splits the key into
+// shares that sign with hashType, and the size of the key.
+func importCA(keyFile, certFile string, hashType stdcrypto.Hash) (caFunc, int, error) {
+	if keyFile == "" || certFile == "" {
+		return nil, 0, fmt.Errorf("--import-key and --import-cert have to be given together")
+	}
+	key, err := crypto.ReadRSAKeyFile(keyFile)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot read the key to import: %w", err)
+	}
+	cert, err := crypto.ReadCertFile(certFile)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot read the certificate to import: %w", err)
+	}
+	newCA := func(t, n uint16, _ int) ([]*crypto.ThresholdKey, *x509.Certificate, error) {
+		thresholdKeys, err := crypto.ImportCA(t, n, key, cert, hashType)
+		return thresholdKeys, cert, err
+	}
+	return newCA, key.N.BitLen(), nil
+}
+
+// generate writes all keys and certificates of the cluster and its configuration. The threshold keys are
+// encrypted with a passphrase per node unless passphrase is nil. If cer isn't nil, the keys are generated in a
+// ceremony.
+func generate(c cluster, threshold uint16, passphrase crypto.Passphrase, newCA caFunc, cer *ceremony) error {
+	err := os.MkdirAll(c.Destination, 0755)
+	if err != nil {
+		return fmt.Errorf("cannot create '%s' directory: %w", c.Destination, err)
+	}
+
+	if cer != nil {
+		err = cer.confirm("Generating the keys of %v nodes with a threshold of %v.", len(c.Nodes), threshold)
+		if err != nil {
+			return err
+		}
+	}
 	fmt.Println("Generating all threshold keys and root certificate.")
 
-	// Generates the threshold keys and a root certificate and writes all to seperate files
-	err := crypto.GenerateConfiguration(opts.Threshold, opts.Num, opts.KeySize, dest)
+	// Generates the threshold keys and a root certificate that a threshold of them signed, or splits an imported key
+	thresholdKeys, rootCA, err := newCA(threshold, uint16(len(c.Nodes)), c.KeySize)
+	if err != nil {
+		return err
+	}
+	err = crypto.WriteCertFile(rootCA, c.RootCA)
 	if err != nil {
-		fmt.Print(err)
+		return err
+	}
+	if cer != nil {
+		if err = cer.start(thresholdKeys, rootCA); err != nil {
+			return err
+		}
+		cer.log("wrote the root certificate to %v", c.RootCA)
 	}
 
 	fmt.Println("Generating all private keys and TLS certificates.")
 
-	// Generates ecdsa private keys for HotStuff/Replication server
-	err = keygen.GenerateConfiguration(dest, false, false, 1, int(opts.Num), "n*", []string{"localhost:8080"})
+	tlsCA, tlsCAKey, err := newTLSCA()
+	if err != nil {
+		return err
+	}
+	err = crypto.WriteCertFile(tlsCA, c.TLSCA)
+	if err != nil {
+		return err
+	}
+
+	for i, n := range c.Nodes {
+		if cer != nil {
+			n = cer.medium(n)
+			err = cer.confirm("Insert the medium of node %v; its secrets are written to %v.", n.ID, n.Dir)
+			if err != nil {
+				return err
+			}
+		}
+		err = os.MkdirAll(n.Dir, 0700)
+		if err != nil {
+			return fmt.Errorf("cannot create '%s' directory: %w", n.Dir, err)
+		}
+		err = writeThresholdKey(thresholdKeys[i], n, passphrase)
+		if err != nil {
+			return err
+		}
+
+		// Generates the ecdsa private key for the HotStuff/Replication server; only the public key is shared
+		err = keygen.GenerateConfiguration(n.Dir, false, false, n.ID, 1, "n*", nil)
+		if err != nil {
+			return err
+		}
+		err = moveFile(n.PrivKey+".pub", n.PubKey)
+		if err != nil {
+			return err
+		}
+
+		pub, err := keygen.ReadPublicKeyFile(n.PubKey)
+		if err != nil {
+			return err
+		}
+		ecdsaPub, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("public key of node %v isn't an ecdsa key", n.ID)
+		}
+		cert, err := newTLSCert(tlsCA, tlsCAKey, n.ID, n.Host, ecdsaPub)
+		if err != nil {
+			return err
+		}
+		err = crypto.WriteCertFile(cert, n.TLSCert)
+		if err != nil {
+			return err
+		}
+
+		if cer != nil {
+			err = cer.transcript.AddShare(uint16(n.ID), n.ThresholdKey)
+			if err != nil {
+				return err
+			}
+			cer.log("wrote the secrets of node %v to its medium", n.ID)
+			err = cer.confirm("Remove the medium of node %v.", n.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = writeConfig(c); err != nil {
+		return err
+	}
+	if cer == nil {
+		return nil
+	}
+
+	cer.log("wrote the configuration to %v", c.Config)
+	hash, err := crypto.WriteTranscriptFile(cer.transcript, thresholdKeys, transcriptFile(c))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote the transcript to %v; publish it with root.crt and its SHA-256 hash %v.\n", transcriptFile(c), hash)
+	return nil
+}
+
+// moveFile moves a file, also to another file system.
+func moveFile(from, to string) error {
+	if os.Rename(from, to) == nil {
+		return nil
+	}
+	raw, err := os.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(to, raw, 0644); err != nil {
+		return err
+	}
+	return os.Remove(from)
+}
+
+func writeThresholdKey(key *crypto.ThresholdKey, n node, passphrase crypto.Passphrase) error {
+	if passphrase == nil {
+		return crypto.WriteThresholdKeyFile(key, n.ThresholdKey)
+	}
+	pass, err := passphrase(fmt.Sprintf("Passphrase of node %v", n.ID))
 	if err != nil {
-		fmt.Print(err)
+		return err
 	}
+	defer crypto.Wipe(pass)
+	return crypto.WriteEncryptedThresholdKeyFile(key, n.ThresholdKey, pass)
 }
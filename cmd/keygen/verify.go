@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+// verify checks a share against the transcript of the ceremony it has been generated in and returns the exit code.
+func verify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Printf("Usage: %s verify [options] thresholdkey\n", os.Args[0])
+		fmt.Println()
+		fmt.Println("Checks that a threshold key share has been handed out in a key ceremony: that the transcript is signed by the")
+		fmt.Println("key of the root certificate, that the file is the one written for the node and that the share matches its")
+		fmt.Println("published verification key. Compare the printed hashes with the ones published out of band.")
+		fmt.Println()
+		fmt.Println("Options:")
+		flags.PrintDefaults()
+	}
+	transcriptPath := flags.String("transcript", "keys/transcript.json", "The transcript of the ceremony.")
+	rootCA := flags.String("root-ca", "keys/root.crt", "The root certificate of the cluster.")
+	passphraseSpec := flags.String("passphrase", "", "Where the passphrase of an encrypted key file is read from. Defaults to $"+crypto.PassphraseEnv+" if it is set and to prompting otherwise.")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 1
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 1
+	}
+
+	if err := verifyShare(flags.Arg(0), *transcriptPath, *rootCA, *passphraseSpec); err != nil {
+		fmt.Fprintf(os.Stderr, "Verification of %v failed: %v\n", flags.Arg(0), err)
+		return 1
+	}
+	return 0
+}
+
+func verifyShare(keyFile, transcriptPath, rootCA, passphraseSpec string) error {
+	root, err := crypto.ReadCertFile(rootCA)
+	if err != nil {
+		return err
+	}
+	transcript, err := crypto.ReadTranscriptFile(transcriptPath, root)
+	if err != nil {
+		return err
+	}
+	passphrase, err := crypto.PassphraseSource(passphraseSpec, false)
+	if err != nil {
+		return err
+	}
+	key, err := crypto.OpenThresholdKeyFile(keyFile, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := transcript.VerifyShare(key, keyFile); err != nil {
+		return err
+	}
+
+	fmt.Printf("The share of node %v has been handed out in the ceremony of %v.\n", key.KeyShare.Id, transcript.Started.Format("2006-01-02 15:04 MST"))
+	fmt.Printf("Root certificate:  SHA-256 %v\n", transcript.RootCertificate)
+	fmt.Printf("Threshold:         %v of %v nodes, %v bits\n", transcript.Threshold, transcript.Nodes, transcript.KeySize)
+	return nil
+}
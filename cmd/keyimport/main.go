@@ -0,0 +1,102 @@
+/*
+	KEY SHARE IMPORT:
+		1. Reads a threshold key file, asking for its passphrase if it is encrypted
+		2. Stores the key share in a PKCS #11 token under the object label of the URI; the token only stores it,
+		   the node still reads it into memory to sign
+		3. Signs a test digest with the share in the token to check that it can be used
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/crypto/keystore"
+)
+
+func usage() {
+	fmt.Printf("Usage: %s [options] thresholdkey pkcs11-uri\n", os.Args[0])
+	fmt.Println()
+	fmt.Println("Imports a threshold key share into a PKCS #11 token, e.g.")
+	fmt.Println()
+	fmt.Println("  keyimport keys/n1/n1.thresholdkey 'pkcs11:token=hotcert;object=n1?module-path=/usr/lib/softhsm/libsofthsm2.so'")
+	fmt.Println()
+	fmt.Println("Set the thresholdkey of the node to the URI afterwards and remove the file.")
+	fmt.Println()
+	fmt.Println("The token only stores the share: the node reads it into memory every time it computes a signature")
+	fmt.Println("share, so it isn't isolated in the token like a key that never leaves it.")
+	fmt.Println()
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+
+	help := flag.BoolP("help", "h", false, "Prints this text.")
+	passphraseSpec := flag.String("passphrase", "", "Where the passphrase of an encrypted key file is read from. Defaults to $"+crypto.PassphraseEnv+" if it is set and to prompting otherwise.")
+	pinSpec := flag.String("pin", "prompt", "Where the PIN of the token is read from: 'prompt', 'env:NAME' or 'file:PATH'.")
+	flag.Parse()
+
+	if *help {
+		usage()
+		os.Exit(0)
+	}
+
+	if flag.NArg() != 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	if err := importKey(flag.Arg(0), flag.Arg(1), *passphraseSpec, *pinSpec); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to import %v: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	fmt.Printf("Imported %v into %v\n", flag.Arg(0), flag.Arg(1))
+}
+
+func importKey(path, location, passphraseSpec, pinSpec string) error {
+	uri, err := keystore.ParsePKCS11URI(location)
+	if err != nil {
+		return err
+	}
+	passphrase, err := crypto.PassphraseSource(passphraseSpec, false)
+	if err != nil {
+		return err
+	}
+	pin, err := crypto.PassphraseSource(pinSpec, false)
+	if err != nil {
+		return err
+	}
+
+	key, err := crypto.OpenThresholdKeyFile(path, passphrase)
+	if err != nil {
+		return err
+	}
+
+	store, err := keystore.OpenPKCS11(uri, pin)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if err := store.Import(key); err != nil {
+		return err
+	}
+
+	// the share in the token has to sign just like the one in the file
+	tokenKey, err := store.Key()
+	if err != nil {
+		return err
+	}
+	h := key.HashType.New()
+	h.Write([]byte("keyimport"))
+	digest := h.Sum(nil)
+	share, err := crypto.SignDigestShare(digest, key.HashType, tokenKey)
+	if err != nil {
+		return fmt.Errorf("imported share can't sign: %w", err)
+	}
+	return crypto.VerifyDigestShare(digest, key.HashType, key, share)
+}
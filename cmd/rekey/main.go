@@ -0,0 +1,89 @@
+/*
+	THRESHOLD KEY REKEYING:
+		1. Reads a threshold key file, asking for the current passphrase if it is encrypted
+		2. Encrypts the key with a new passphrase (or writes it unencrypted with --new none)
+		3. Replaces the file atomically so that the key is never lost halfway
+*/
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	flag "github.com/spf13/pflag"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+func usage() {
+	fmt.Printf("Usage: %s [options] thresholdkey\n", os.Args[0])
+	fmt.Println()
+	fmt.Println("Changes the passphrase a threshold key file is encrypted with; unencrypted files are encrypted.")
+	fmt.Println("Passphrases are read from 'prompt', 'env:NAME', 'file:PATH' or 'fd:N'.")
+	fmt.Println()
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+
+	help := flag.BoolP("help", "h", false, "Prints this text.")
+	oldSpec := flag.String("old", "", "Where the current passphrase is read from. Defaults to $"+crypto.PassphraseEnv+" if it is set and to prompting otherwise.")
+	newSpec := flag.String("new", "prompt", "Where the new passphrase is read from; 'none' writes the key unencrypted.")
+	flag.Parse()
+
+	if *help {
+		usage()
+		os.Exit(0)
+	}
+
+	if flag.NArg() != 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	if err := rekey(flag.Arg(0), *oldSpec, *newSpec); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to rekey %v: %v\n", flag.Arg(0), err)
+		os.Exit(1)
+	}
+	fmt.Printf("Rekeyed %v\n", flag.Arg(0))
+}
+
+func rekey(path, oldSpec, newSpec string) error {
+	oldPassphrase, err := crypto.PassphraseSource(oldSpec, false)
+	if err != nil {
+		return err
+	}
+	newPassphrase, err := crypto.PassphraseSource(newSpec, true)
+	if err != nil {
+		return err
+	}
+
+	key, err := crypto.OpenThresholdKeyFile(path, oldPassphrase)
+	if err != nil {
+		return err
+	}
+
+	// the new file is written next to the old one and only renamed once it is complete
+	tmp := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	defer os.Remove(tmp)
+	if newPassphrase == nil {
+		fmt.Fprintln(os.Stderr, "Warning: the threshold key is written unencrypted.")
+		err = crypto.WriteThresholdKeyFile(key, tmp)
+	} else {
+		var pass []byte
+		pass, err = newPassphrase("New passphrase of " + path)
+		if err != nil {
+			return err
+		}
+		defer crypto.Wipe(pass)
+		err = crypto.WriteEncryptedThresholdKeyFile(key, tmp, pass)
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
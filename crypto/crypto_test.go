@@ -1,6 +1,7 @@
 package crypto_test
 
 import (
+	stdcrypto "crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
@@ -69,7 +70,7 @@ func TestWriteReadEquality(t *testing.T) {
 func TestSigningProcess(t *testing.T) {
 
 	fmt.Println("Generating configuration and writing to files")
-	err := crypto.GenerateConfiguration(threshold, num, keySize, destination)
+	err := crypto.GenerateConfiguration(threshold, num, keySize, stdcrypto.SHA256, destination)
 	if err != nil {
 		panic(fmt.Sprintf("%v", err))
 	}
@@ -92,14 +93,14 @@ func TestSigningProcess(t *testing.T) {
 		panic(fmt.Sprintf("%v", err))
 	}
 
-	fmt.Println("Reading dummy key")
-	dummyKey, err := crypto.ReadThresholdKeyFile(destination + "/n1.thresholdkey")
+	fmt.Println("Reading a key")
+	issuerKey, err := crypto.ReadThresholdKeyFile(destination + "/n1.thresholdkey")
 	if err != nil {
 		panic(fmt.Sprintf("%v", err))
 	}
 
 	fmt.Println("Generating a certificate for signing")
-	cert, err := crypto.GenerateCert(csr, rootCA, dummyKey)
+	cert, err := crypto.GenerateCert(csr, rootCA, issuerKey)
 	if err != nil {
 		panic(fmt.Sprintf("%v", err))
 	}
@@ -137,7 +138,7 @@ func TestSigningProcess(t *testing.T) {
 	}
 
 	fmt.Println("Computing the full signature on the certificate")
-	fullCert, err := crypto.ComputeFullySignedCert(cert, dummyKey, sigShares...)
+	fullCert, err := crypto.ComputeFullySignedCert(cert, issuerKey, sigShares...)
 	if err != nil {
 		panic(fmt.Sprintf("%v", err))
 	}
@@ -148,3 +149,30 @@ func TestSigningProcess(t *testing.T) {
 		t.Errorf("signature verification failed")
 	}
 }
+
+func TestCheckShare(t *testing.T) {
+	thresholdKeys, err := crypto.ComputeTresholdKeys(threshold, num, keySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range thresholdKeys {
+		if err := crypto.CheckShare(key); err != nil {
+			t.Errorf("share of node %v: %v", key.KeyShare.Id, err)
+		}
+	}
+
+	// a share that has been corrupted doesn't pass
+	key := *thresholdKeys[0]
+	key.KeyShare = &tcrsa.KeyShare{Si: append([]byte(nil), key.KeyShare.Si...), Id: key.KeyShare.Id}
+	key.KeyShare.Si[len(key.KeyShare.Si)-1] ^= 1
+	if err := crypto.CheckShare(&key); err == nil {
+		t.Error("corrupted share passes")
+	}
+
+	// nor does a share that is checked against the verification key of another node
+	key = *thresholdKeys[0]
+	key.KeyShare = &tcrsa.KeyShare{Si: key.KeyShare.Si, Id: thresholdKeys[1].KeyShare.Id}
+	if err := crypto.CheckShare(&key); err == nil {
+		t.Error("share passes as the share of another node")
+	}
+}
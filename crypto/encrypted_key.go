@@ -0,0 +1,234 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const encryptedThresholdKeyFileType = "HOTCERTIFICATION ENCRYPTED THRESHOLD KEY"
+
+// scrypt parameters of new key files; the ones a file has been written with are stored in it.
+// N = 2^15 takes about 100ms, which only has to be paid when a node starts.
+const (
+	scryptN  = 1 << 15
+	scryptR  = 8
+	scryptP  = 1
+	saltSize = 16
+)
+
+var (
+	// ErrEncryptedKey is returned by ReadThresholdKeyFile for key files that need a passphrase.
+	ErrEncryptedKey = errors.New("threshold key is encrypted")
+	// ErrWrongPassphrase is returned if an encrypted key can't be decrypted.
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted key file")
+)
+
+// EncryptKey encrypts key with AES-256-GCM under a key that is derived from passphrase with scrypt. The block holds
+// the parameters of scrypt and the nonce in its headers, which are authenticated along with the key.
+func EncryptKey(key *ThresholdKey, passphrase []byte) (*pem.Block, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase is empty")
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	block := &pem.Block{
+		Type: encryptedThresholdKeyFileType,
+		Headers: map[string]string{
+			"KDF":        "scrypt",
+			"KDF-Params": fmt.Sprintf("N=%v,r=%v,p=%v", scryptN, scryptR, scryptP),
+			"Salt":       hex.EncodeToString(salt),
+			"Cipher":     "AES-256-GCM",
+		},
+	}
+
+	aead, err := newAEAD(block, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	block.Headers["Nonce"] = hex.EncodeToString(nonce)
+
+	plaintext, err := KeyToBytes(key)
+	if err != nil {
+		return nil, err
+	}
+	defer Wipe(plaintext)
+	block.Bytes = aead.Seal(nil, nonce, plaintext, additionalData(block))
+	return block, nil
+}
+
+// DecryptKey decrypts a block that has been encrypted by EncryptKey.
+func DecryptKey(block *pem.Block, passphrase []byte) (*ThresholdKey, error) {
+	if block.Type != encryptedThresholdKeyFileType {
+		return nil, fmt.Errorf("file type did not match")
+	}
+	if block.Headers["Cipher"] != "AES-256-GCM" {
+		return nil, fmt.Errorf("unsupported cipher %q", block.Headers["Cipher"])
+	}
+
+	aead, err := newAEAD(block, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(block.Headers["Nonce"])
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce")
+	}
+
+	plaintext, err := aead.Open(nil, nonce, block.Bytes, additionalData(block))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	defer Wipe(plaintext)
+	return KeyFromBytes(plaintext)
+}
+
+// newAEAD derives the key of the cipher from passphrase with the KDF in the headers of block.
+func newAEAD(block *pem.Block, passphrase []byte) (cipher.AEAD, error) {
+	if block.Headers["KDF"] != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation function %q", block.Headers["KDF"])
+	}
+	n, r, p, err := parseScryptParams(block.Headers["KDF-Params"])
+	if err != nil {
+		return nil, err
+	}
+	salt, err := hex.DecodeString(block.Headers["Salt"])
+	if err != nil || len(salt) == 0 {
+		return nil, fmt.Errorf("invalid salt")
+	}
+
+	key, err := scrypt.Key(passphrase, salt, n, r, p, 32)
+	if err != nil {
+		return nil, err
+	}
+	defer Wipe(key)
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c)
+}
+
+// parseScryptParams parses "N=..,r=..,p=..". Larger parameters than the ones new files are written with are
+// accepted up to a limit so that a crafted file can't make a node allocate gigabytes.
+func parseScryptParams(s string) (n, r, p int, err error) {
+	params := make(map[string]int, 3)
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 {
+			return 0, 0, 0, fmt.Errorf("invalid KDF parameters %q", s)
+		}
+		v, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid KDF parameters %q", s)
+		}
+		params[parts[0]] = v
+	}
+	n, r, p = params["N"], params["r"], params["p"]
+	if n < 2 || n > 1<<20 || r < 1 || r > 32 || p < 1 || p > 16 {
+		return 0, 0, 0, fmt.Errorf("KDF parameters %q are out of range", s)
+	}
+	return n, r, p, nil
+}
+
+// additionalData binds the parameters in the headers to the ciphertext so that they can't be swapped.
+func additionalData(block *pem.Block) []byte {
+	var b bytes.Buffer
+	b.WriteString(block.Type)
+	for _, h := range []string{"KDF", "KDF-Params", "Salt", "Cipher"} {
+		fmt.Fprintf(&b, "\n%v: %v", h, block.Headers[h])
+	}
+	return b.Bytes()
+}
+
+// Wipe overwrites b with zeros; passphrases and decrypted keys are wiped once they aren't needed anymore.
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// WriteEncryptedThresholdKeyFile writes key encrypted with passphrase to filePath.
+func WriteEncryptedThresholdKeyFile(key *ThresholdKey, filePath string, passphrase []byte) (err error) {
+	block, err := EncryptKey(key, passphrase)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	return pem.Encode(file, block)
+}
+
+// OpenThresholdKeyFile reads a threshold key file that may be encrypted. passphrase is only asked if it is.
+func OpenThresholdKeyFile(keyFile string, passphrase Passphrase) (*ThresholdKey, error) {
+	block, err := readPEMFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type != encryptedThresholdKeyFileType {
+		return ReadThresholdKeyFile(keyFile)
+	}
+	if passphrase == nil {
+		return nil, fmt.Errorf("%v: %w", keyFile, ErrEncryptedKey)
+	}
+
+	pass, err := passphrase("Passphrase of " + keyFile)
+	if err != nil {
+		return nil, err
+	}
+	defer Wipe(pass)
+	key, err := DecryptKey(block, pass)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", keyFile, err)
+	}
+	return key, nil
+}
+
+// IsEncryptedKeyFile tells whether keyFile holds an encrypted threshold key.
+func IsEncryptedKeyFile(keyFile string) (bool, error) {
+	block, err := readPEMFile(keyFile)
+	if err != nil {
+		return false, err
+	}
+	return block.Type == encryptedThresholdKeyFileType, nil
+}
+
+func readPEMFile(file string) (*pem.Block, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM")
+	}
+	return block, nil
+}
@@ -0,0 +1,64 @@
+package crypto_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+func TestEncryptedKeyFile(t *testing.T) {
+	keys, err := crypto.ComputeTresholdKeys(threshold, num, keySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "n1.thresholdkey")
+	if err := crypto.WriteEncryptedThresholdKeyFile(keys[0], path, []byte("correct horse")); err != nil {
+		t.Fatal(err)
+	}
+
+	passFile := filepath.Join(dir, "pass")
+	passphrase := func(pass string) crypto.Passphrase {
+		if err := os.WriteFile(passFile, []byte(pass+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		p, err := crypto.PassphraseSource("file:"+passFile, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	key, err := crypto.OpenThresholdKeyFile(path, passphrase("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key.KeyShare.Si, keys[0].KeyShare.Si) || !key.KeyMeta.PublicKey.Equal(keys[0].KeyMeta.PublicKey) {
+		t.Error("decrypted key differs from the one that has been written")
+	}
+
+	if _, err := crypto.OpenThresholdKeyFile(path, passphrase("wrong horse")); !errors.Is(err, crypto.ErrWrongPassphrase) {
+		t.Errorf("opened key with the wrong passphrase: %v", err)
+	}
+	if _, err := crypto.ReadThresholdKeyFile(path); !errors.Is(err, crypto.ErrEncryptedKey) {
+		t.Errorf("read encrypted key without a passphrase: %v", err)
+	}
+
+	// the KDF parameters are authenticated, so weakening them breaks the file
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(raw), "N=32768", "N=1024", 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crypto.OpenThresholdKeyFile(path, passphrase("correct horse")); !errors.Is(err, crypto.ErrWrongPassphrase) {
+		t.Errorf("opened key with tampered KDF parameters: %v", err)
+	}
+}
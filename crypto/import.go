@@ -0,0 +1,256 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/niclabs/tcrsa"
+)
+
+/*
+Splitting an existing key:
+
+tcrsa only splits keys it generates itself, whose primes are safe primes p = 2p'+1 and q = 2q'+1, so that
+delta = l! is invertible modulo m = p'q'. It hands out the shares f(i)/delta and combines the signature shares
+with e' = 4. The primes of an existing key are hardly ever safe primes, so delta usually can't be inverted.
+Its shares are split as in Shoup's paper instead: the shares hold delta * f(i) modulo m, where m is the smallest
+number such that x^(4m) = 1 for all x, and the signature shares are combined with e' = 4 * delta^2. Signing and
+verifying signature shares works the same for both kinds of shares, only joining them differs.
+
+Since m generally has small factors, the proofs of correctness that come with the signature shares don't give the
+same guarantees as for keys with safe primes; joined signatures are always checked against the public key.
+*/
+
+// ImportCA splits the existing key of a CA into n shares of which t are needed to sign with hashType. rootCert is
+// the certificate of the CA; it has to be for the public key of caKey and is kept as the root certificate.
+func ImportCA(t uint16, n uint16, caKey *rsa.PrivateKey, rootCert *x509.Certificate, hashType crypto.Hash) ([]*ThresholdKey, error) {
+	pub, ok := rootCert.PublicKey.(*rsa.PublicKey)
+	if !ok || !pub.Equal(&caKey.PublicKey) {
+		return nil, fmt.Errorf("root certificate isn't for the public key of the CA key")
+	}
+	if !rootCert.IsCA {
+		return nil, fmt.Errorf("root certificate isn't the certificate of a CA")
+	}
+
+	keyShares, keyMeta, err := SplitKey(caKey, t, n)
+	if err != nil {
+		return nil, err
+	}
+	thresholdKeys := make([]*ThresholdKey, n)
+	for i, share := range keyShares {
+		thresholdKeys[i], err = NewThresholdKey(share, keyMeta, hashType)
+		if err != nil {
+			return nil, err
+		}
+		thresholdKeys[i].DeltaShares = true
+	}
+	return thresholdKeys, nil
+}
+
+// ReadRSAKeyFile reads an unencrypted RSA private key in PKCS #1 or PKCS #8 PEM format.
+func ReadRSAKeyFile(keyFile string) (*rsa.PrivateKey, error) {
+	raw, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode key")
+	}
+	defer Wipe(block.Bytes)
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%v isn't an RSA key", keyFile)
+		}
+		return rsaKey, nil
+	}
+	return nil, fmt.Errorf("file type did not match")
+}
+
+// SplitKey splits key into l shares of which k are needed to sign. The shares have to be combined with
+// e' = 4 * delta^2; see DeltaShares of ThresholdKey.
+func SplitKey(key *rsa.PrivateKey, k, l uint16) (tcrsa.KeyShareList, *tcrsa.KeyMeta, error) {
+	if err := key.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if len(key.Primes) != 2 {
+		return nil, nil, fmt.Errorf("multi-prime keys can't be split")
+	}
+	if l <= 1 {
+		return nil, nil, fmt.Errorf("l should be greater than 1, but it is %d", l)
+	}
+	if k < (l/2+1) || k > l {
+		return nil, nil, fmt.Errorf("k should be between the %d and %d, but it is %d", (l/2)+1, l, k)
+	}
+	// e' = 4 * delta^2 has to be coprime with e
+	e := big.NewInt(int64(key.E))
+	if !e.ProbablyPrime(20) || key.E <= int(l) {
+		return nil, nil, fmt.Errorf("public exponent %v has to be a prime greater than %v", key.E, l)
+	}
+
+	n := key.N
+	one := big.NewInt(1)
+	pMinus1 := new(big.Int).Sub(key.Primes[0], one)
+	qMinus1 := new(big.Int).Sub(key.Primes[1], one)
+
+	// lambda = lcm(p-1, q-1) and m = lambda / gcd(lambda, 4)
+	gcd := new(big.Int).GCD(nil, nil, pMinus1, qMinus1)
+	lambda := new(big.Int).Mul(pMinus1, qMinus1)
+	lambda.Div(lambda, gcd)
+	m := new(big.Int).Div(lambda, new(big.Int).GCD(nil, nil, lambda, big.NewInt(4)))
+
+	d := new(big.Int).ModInverse(e, m)
+	if d == nil {
+		return nil, nil, fmt.Errorf("public exponent isn't invertible")
+	}
+
+	meta := &tcrsa.KeyMeta{
+		PublicKey:       &rsa.PublicKey{N: new(big.Int).Set(n), E: key.E},
+		K:               k,
+		L:               l,
+		VerificationKey: tcrsa.NewVerificationKey(l),
+	}
+
+	// v generates the squares the verification keys are computed in
+	r, err := coprime(n)
+	if err != nil {
+		return nil, nil, err
+	}
+	v := new(big.Int).Exp(r, big.NewInt(2), n)
+	meta.VerificationKey.V = v.Bytes()
+
+	// documents whose Jacobi symbol is -1 are multiplied with u^e before they are signed
+	u := new(big.Int)
+	for big.Jacobi(u, n) != -1 {
+		if u, err = rand.Int(rand.Reader, n); err != nil {
+			return nil, nil, err
+		}
+	}
+	meta.VerificationKey.U = u.Bytes()
+
+	// f(x) = d + a_1 x + ... + a_(k-1) x^(k-1) modulo m
+	coeffs := make([]*big.Int, k)
+	coeffs[0] = d
+	for i := 1; i < int(k); i++ {
+		if coeffs[i], err = rand.Int(rand.Reader, m); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	delta := new(big.Int).MulRange(1, int64(l))
+	shares := make(tcrsa.KeyShareList, l)
+	for i := uint16(1); i <= l; i++ {
+		x := big.NewInt(int64(i))
+		si := new(big.Int)
+		for j := len(coeffs) - 1; j >= 0; j-- {
+			si.Mul(si, x).Add(si, coeffs[j]).Mod(si, m)
+		}
+		si.Mul(si, delta).Mod(si, m)
+
+		shares[i-1] = &tcrsa.KeyShare{Si: si.Bytes(), Id: i}
+		meta.VerificationKey.I[i-1] = new(big.Int).Exp(v, si, n).Bytes()
+	}
+	return shares, meta, nil
+}
+
+// coprime returns a random number that is coprime with n.
+func coprime(n *big.Int) (*big.Int, error) {
+	one := big.NewInt(1)
+	for {
+		r, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		if r.Sign() > 0 && new(big.Int).GCD(nil, nil, r, n).Cmp(one) == 0 {
+			return r, nil
+		}
+	}
+}
+
+// joinDeltaShares combines the signature shares of a key that has been split by SplitKey, like tcrsa does for
+// its own keys but with e' = 4 * delta^2.
+func joinDeltaShares(paddedHash []byte, meta *tcrsa.KeyMeta, shares tcrsa.SigShareList) ([]byte, error) {
+	k := int(meta.K)
+	if len(shares) < k {
+		return nil, fmt.Errorf("insufficient number of signature shares. provided: %d, needed: %d", len(shares), k)
+	}
+	shares = shares[:k]
+
+	n := meta.PublicKey.N
+	e := big.NewInt(int64(meta.PublicKey.E))
+	u := new(big.Int).SetBytes(meta.VerificationKey.U)
+	delta := new(big.Int).MulRange(1, int64(meta.L))
+
+	x := new(big.Int).SetBytes(paddedHash)
+	nonSquare := big.Jacobi(x, n) == -1
+	if nonSquare {
+		x.Mul(x, new(big.Int).Exp(u, e, n)).Mod(x, n)
+	}
+
+	// w = prod x_i^(2 lambda_i) = x^(4 delta^2 d)
+	w := big.NewInt(1)
+	for _, share := range shares {
+		lambda := lagrange(shares, int64(share.Id), delta)
+		lambda.Mul(lambda, big.NewInt(2))
+		xi := new(big.Int).SetBytes(share.Xi)
+		if lambda.Sign() < 0 {
+			xi.ModInverse(xi, n)
+			lambda.Neg(lambda)
+		}
+		w.Mul(w, new(big.Int).Exp(xi, lambda, n)).Mod(w, n)
+	}
+
+	// y = w^a x^b with e' a + e b = 1 is the e-th root of x
+	ePrime := new(big.Int).Mul(delta, delta)
+	ePrime.Mul(ePrime, big.NewInt(4))
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, ePrime, e).Cmp(big.NewInt(1)) != 0 {
+		return nil, fmt.Errorf("public exponent isn't coprime with 4 delta^2")
+	}
+	y := new(big.Int).Mul(powMod(w, a, n), powMod(x, b, n))
+	if nonSquare {
+		y.Mul(y, new(big.Int).ModInverse(u, n))
+	}
+	y.Mod(y, n)
+
+	signature := make([]byte, meta.PublicKey.Size())
+	y.FillBytes(signature)
+	return signature, nil
+}
+
+// lagrange returns delta times the Lagrange coefficient of share j at 0, which is an integer.
+func lagrange(shares tcrsa.SigShareList, j int64, delta *big.Int) *big.Int {
+	num, den := big.NewInt(1), big.NewInt(1)
+	for _, share := range shares {
+		if id := int64(share.Id); id != j {
+			num.Mul(num, big.NewInt(id))
+			den.Mul(den, big.NewInt(id-j))
+		}
+	}
+	out := new(big.Int).Mul(delta, num)
+	return out.Quo(out, den)
+}
+
+// powMod returns x^y mod n; negative exponents invert x.
+func powMod(x, y, n *big.Int) *big.Int {
+	if y.Sign() < 0 {
+		return new(big.Int).Exp(new(big.Int).ModInverse(x, n), new(big.Int).Neg(y), n)
+	}
+	return new(big.Int).Exp(x, y, n)
+}
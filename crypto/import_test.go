@@ -0,0 +1,91 @@
+package crypto_test
+
+import (
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/niclabs/tcrsa"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+func TestImportCA(t *testing.T) {
+	caKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "existing CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crypto.ImportCA(threshold, num, otherKey, rootCert, stdcrypto.SHA256); err == nil {
+		t.Error("imported a key that doesn't belong to the root certificate")
+	}
+
+	keys, err := crypto.ImportCA(threshold, num, caKey, rootCert, stdcrypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the shares have to survive being written to a file
+	path := filepath.Join(t.TempDir(), "n2.thresholdkey")
+	if err := crypto.WriteThresholdKeyFile(keys[1], path); err != nil {
+		t.Fatal(err)
+	}
+	if keys[1], err = crypto.ReadThresholdKeyFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !keys[1].DeltaShares {
+		t.Fatal("read key doesn't know that its shares have been split from an existing key")
+	}
+
+	// sign with shares other than the first ones, and with enough documents that some aren't squares
+	for i := 0; i < 8; i++ {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("document %d", i)))
+		var shares tcrsa.SigShareList
+		for _, key := range keys[num-threshold:] {
+			share, err := crypto.SignDigestShare(digest[:], stdcrypto.SHA256, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := crypto.VerifyDigestShare(digest[:], stdcrypto.SHA256, keys[0], share); err != nil {
+				t.Fatalf("signature share of node %v is invalid: %v", share.Id, err)
+			}
+			shares = append(shares, share)
+		}
+		signature, err := crypto.JoinDigestShares(digest[:], stdcrypto.SHA256, keys[0], shares...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rsa.VerifyPKCS1v15(&caKey.PublicKey, keys[0].HashType, digest[:], signature); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
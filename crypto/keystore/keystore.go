@@ -0,0 +1,100 @@
+/*
+Package keystore keeps the threshold key share of a node. A share can be kept in a file, in a file encrypted with
+a passphrase or in a PKCS #11 token such as a hardware security module or SoftHSM.
+
+Where the key is kept is given by its location:
+
+	keys/n1/n1.thresholdkey                                   a file; encrypted or not
+	pkcs11:token=hotcert;object=n1?module-path=/usr/lib/softhsm/libsofthsm2.so
+
+A token only stores the share; it doesn't isolate it. The signature shares of a key that is kept in a token are
+computed in the memory of the node, which reads the share from the token while it signs: the proof of correctness
+tcrsa attaches to every signature share needs the share itself, so it can't be computed by the token with the
+standard PKCS #11 mechanisms.
+*/
+package keystore
+
+import (
+	"strings"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+// Store is where the threshold key share of a node is kept.
+type Store interface {
+	// Key returns the threshold key of the node. If the share isn't held in memory, key.Signer computes the
+	// signature shares.
+	Key() (*crypto.ThresholdKey, error)
+	Close() error
+}
+
+// Open opens the store at location. passphrase is asked for the passphrase of an encrypted file or the PIN of a
+// token.
+func Open(location string, passphrase crypto.Passphrase) (Store, error) {
+	if IsPKCS11(location) {
+		uri, err := ParsePKCS11URI(location)
+		if err != nil {
+			return nil, err
+		}
+		s, err := OpenPKCS11(uri, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	path := strings.TrimPrefix(location, "file:")
+	encrypted, err := crypto.IsEncryptedKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		return NewEncryptedFileStore(path, passphrase), nil
+	}
+	return NewFileStore(path), nil
+}
+
+// IsPKCS11 tells whether location is a PKCS #11 URI rather than a file.
+func IsPKCS11(location string) bool {
+	return strings.HasPrefix(location, "pkcs11:")
+}
+
+// fileStore keeps the key in a file that isn't encrypted.
+type fileStore struct {
+	path string
+}
+
+// NewFileStore returns the store of the unencrypted key file at path.
+func NewFileStore(path string) Store {
+	return &fileStore{path: path}
+}
+
+func (s *fileStore) Key() (*crypto.ThresholdKey, error) {
+	return crypto.ReadThresholdKeyFile(s.path)
+}
+
+func (s *fileStore) Close() error {
+	return nil
+}
+
+// encryptedFileStore keeps the key in a file that is encrypted with a passphrase.
+type encryptedFileStore struct {
+	path       string
+	passphrase crypto.Passphrase
+}
+
+// NewEncryptedFileStore returns the store of the encrypted key file at path, which is unlocked with passphrase.
+func NewEncryptedFileStore(path string, passphrase crypto.Passphrase) Store {
+	return &encryptedFileStore{path: path, passphrase: passphrase}
+}
+
+func (s *encryptedFileStore) Key() (*crypto.ThresholdKey, error) {
+	if s.passphrase == nil {
+		return nil, crypto.ErrEncryptedKey
+	}
+	return crypto.OpenThresholdKeyFile(s.path, s.passphrase)
+}
+
+func (s *encryptedFileStore) Close() error {
+	return nil
+}
@@ -0,0 +1,115 @@
+package keystore_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/crypto/keystore"
+)
+
+func thresholdKeys(t *testing.T) []*crypto.ThresholdKey {
+	t.Helper()
+	keys, err := crypto.ComputeTresholdKeys(3, 4, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func pin(p string) crypto.Passphrase {
+	return func(string) ([]byte, error) { return []byte(p), nil }
+}
+
+// signs checks that key signs like the key share it has been stored from.
+func signs(t *testing.T, key, stored *crypto.ThresholdKey) {
+	t.Helper()
+	h := key.HashType.New()
+	h.Write([]byte("keystore"))
+	digest := h.Sum(nil)
+	share, err := crypto.SignDigestShare(digest, key.HashType, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crypto.VerifyDigestShare(digest, stored.HashType, stored, share); err != nil {
+		t.Errorf("signature share of the stored key is invalid: %v", err)
+	}
+	if share.Id != stored.KeyShare.Id {
+		t.Errorf("signature share is from share %v, not %v", share.Id, stored.KeyShare.Id)
+	}
+}
+
+func TestFileStores(t *testing.T) {
+	keys := thresholdKeys(t)
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "n1.thresholdkey")
+	if err := crypto.WriteThresholdKeyFile(keys[0], plain); err != nil {
+		t.Fatal(err)
+	}
+	encrypted := filepath.Join(dir, "n2.thresholdkey")
+	if err := crypto.WriteEncryptedThresholdKeyFile(keys[1], encrypted, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		location   string
+		passphrase crypto.Passphrase
+		stored     *crypto.ThresholdKey
+	}{
+		{plain, nil, keys[0]},
+		{"file:" + plain, nil, keys[0]},
+		{encrypted, pin("secret"), keys[1]},
+	} {
+		store, err := keystore.Open(test.location, test.passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := store.Key()
+		if err != nil {
+			t.Fatalf("%v: %v", test.location, err)
+		}
+		signs(t, key, test.stored)
+		if err := store.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	store, err := keystore.Open(encrypted, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Key(); err == nil {
+		t.Error("encrypted key has been read without a passphrase")
+	}
+	if _, err := keystore.Open(filepath.Join(dir, "missing"), nil); !os.IsNotExist(err) {
+		t.Errorf("opened missing file: %v", err)
+	}
+}
+
+func TestParsePKCS11URI(t *testing.T) {
+	uri, err := keystore.ParsePKCS11URI("pkcs11:token=hot%20cert;object=n1?module-path=/usr/lib/softhsm/libsofthsm2.so")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := keystore.PKCS11URI{Token: "hot cert", Object: "n1", ModulePath: "/usr/lib/softhsm/libsofthsm2.so"}
+	if uri != want {
+		t.Errorf("got %+v, want %+v", uri, want)
+	}
+	if again, err := keystore.ParsePKCS11URI(uri.String()); err != nil || again != uri {
+		t.Errorf("%v doesn't parse back: %+v, %v", uri, again, err)
+	}
+
+	for _, invalid := range []string{
+		"keys/n1.thresholdkey",
+		"pkcs11:object=n1?module-path=/lib/p11.so",
+		"pkcs11:token=hotcert?module-path=/lib/p11.so",
+		"pkcs11:token=hotcert;object=n1",
+		"pkcs11:token=hotcert;object=n1;id=%01?module-path=/lib/p11.so",
+		"pkcs11:token=hotcert;object?module-path=/lib/p11.so",
+	} {
+		if _, err := keystore.ParsePKCS11URI(invalid); err == nil {
+			t.Errorf("%q has been parsed", invalid)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// +build cgo
+
+package keystore
+
+import (
+	gocrypto "crypto"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+	"github.com/niclabs/tcrsa"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+// applications of the two data objects a key is kept in: the share itself, which is private and can only be read
+// once logged in, and the public rest of the key
+const (
+	shareApplication = "hotcertification-share"
+	metaApplication  = "hotcertification-meta"
+)
+
+// PKCS11Store keeps a key in a PKCS #11 token. The token only stores the share: it is read into memory every time a
+// signature share is computed and wiped right after, so it isn't isolated from the node the way a key that never
+// leaves the token is. The PIN and the access control of the token protect it at rest.
+type PKCS11Store struct {
+	mut     sync.Mutex // sessions mustn't be used concurrently
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+	uri     PKCS11URI
+	id      uint16
+}
+
+// OpenPKCS11 opens a session with the token in uri and logs in with the PIN that pin returns; without a PIN the
+// share can't be read.
+func OpenPKCS11(uri PKCS11URI, pin crypto.Passphrase) (s *PKCS11Store, err error) {
+	ctx := pkcs11.New(uri.ModulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("cannot load PKCS #11 module %v", uri.ModulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("cannot initialize PKCS #11 module %v: %w", uri.ModulePath, err)
+	}
+	s = &PKCS11Store{ctx: ctx, uri: uri}
+	defer func() {
+		if err != nil {
+			s.Close()
+		}
+	}()
+
+	slot, err := s.findSlot()
+	if err != nil {
+		return nil, err
+	}
+	s.session, err = ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open session with token %v: %w", uri.Token, err)
+	}
+
+	if pin != nil {
+		p, err := pin("PIN of token " + uri.Token)
+		if err != nil {
+			return nil, err
+		}
+		defer crypto.Wipe(p)
+		if err := ctx.Login(s.session, pkcs11.CKU_USER, string(p)); err != nil {
+			return nil, fmt.Errorf("cannot log in to token %v: %w", uri.Token, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *PKCS11Store) findSlot() (uint, error) {
+	slots, err := s.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, slot := range slots {
+		info, err := s.ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, err
+		}
+		if info.Label == s.uri.Token {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("there is no token %v in %v", s.uri.Token, s.uri.ModulePath)
+}
+
+// Key returns the key without its share; the signature shares are computed by the store.
+func (s *PKCS11Store) Key() (*crypto.ThresholdKey, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	meta, err := s.value(metaApplication)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.KeyFromBytes(meta)
+	if err != nil {
+		return nil, err
+	}
+	// the share has to be there, and readable, when it is needed to sign
+	share, err := s.value(shareApplication)
+	if err != nil {
+		return nil, err
+	}
+	crypto.Wipe(share)
+
+	s.id = key.KeyShare.Id
+	key.KeyShare.Si = nil
+	key.Signer = s
+	return key, nil
+}
+
+// SignShare computes the signature share on paddedHash with the share in the token.
+func (s *PKCS11Store) SignShare(paddedHash []byte, hashType gocrypto.Hash, meta *tcrsa.KeyMeta) (*tcrsa.SigShare, error) {
+	s.mut.Lock()
+	si, err := s.value(shareApplication)
+	s.mut.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	defer crypto.Wipe(si)
+
+	share := tcrsa.KeyShare{Si: si, Id: s.id}
+	return share.Sign(paddedHash, hashType, meta)
+}
+
+// Import stores key in the token; there mustn't be a key with the same label in it yet.
+func (s *PKCS11Store) Import(key *crypto.ThresholdKey) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, app := range []string{metaApplication, shareApplication} {
+		switch _, err := s.find(app); err {
+		case errNotFound:
+		case nil:
+			return fmt.Errorf("token %v already holds object %v", s.uri.Token, s.uri.Object)
+		default:
+			return err
+		}
+	}
+
+	public := *key
+	public.KeyShare = &tcrsa.KeyShare{Id: key.KeyShare.Id}
+	meta, err := crypto.KeyToBytes(&public)
+	if err != nil {
+		return err
+	}
+	if _, err := s.ctx.CreateObject(s.session, s.template(metaApplication, false, meta)); err != nil {
+		return fmt.Errorf("cannot create object %v: %w", s.uri.Object, err)
+	}
+	// the share mustn't be exported from the token wrapped in another key; not every module takes the attribute
+	// for data objects though
+	share := s.template(shareApplication, true, key.KeyShare.Si)
+	_, err = s.ctx.CreateObject(s.session, append(share, pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false)))
+	if errors.Is(err, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_TYPE_INVALID)) || errors.Is(err, pkcs11.Error(pkcs11.CKR_TEMPLATE_INCONSISTENT)) {
+		_, err = s.ctx.CreateObject(s.session, share)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot create object %v: %w", s.uri.Object, err)
+	}
+	return nil
+}
+
+// Close logs out and closes the session.
+func (s *PKCS11Store) Close() error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.ctx == nil {
+		return nil
+	}
+	s.ctx.Logout(s.session)
+	err := s.ctx.CloseSession(s.session)
+	s.ctx.Finalize()
+	s.ctx.Destroy()
+	s.ctx = nil
+	return err
+}
+
+func (s *PKCS11Store) template(app string, private bool, value []byte) []*pkcs11.Attribute {
+	return []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, private),
+		pkcs11.NewAttribute(pkcs11.CKA_MODIFIABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.uri.Object),
+		pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, app),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, value),
+	}
+}
+
+var errNotFound = errors.New("object not found")
+
+// find returns the object of the key with the given application.
+func (s *PKCS11Store) find(app string) (pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, s.uri.Object),
+		pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, app),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, err
+	}
+	objects, _, err := s.ctx.FindObjects(s.session, 2)
+	if ferr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = ferr
+	}
+	switch {
+	case err != nil:
+		return 0, err
+	case len(objects) == 0:
+		return 0, errNotFound
+	case len(objects) > 1:
+		return 0, fmt.Errorf("token %v holds object %v more than once", s.uri.Token, s.uri.Object)
+	}
+	return objects[0], nil
+}
+
+// value reads the value of the object of the key with the given application.
+func (s *PKCS11Store) value(app string) ([]byte, error) {
+	object, err := s.find(app)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find %v of object %v in token %v: %w", app, s.uri.Object, s.uri.Token, err)
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, object, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil)})
+	if err != nil {
+		return nil, err
+	}
+	if len(attrs) != 1 || len(attrs[0].Value) == 0 {
+		return nil, fmt.Errorf("%v of object %v in token %v is empty", app, s.uri.Object, s.uri.Token)
+	}
+	return attrs[0].Value, nil
+}
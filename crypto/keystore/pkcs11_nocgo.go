@@ -0,0 +1,31 @@
+// +build !cgo
+
+package keystore
+
+import (
+	"errors"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+var errNoCgo = errors.New("PKCS #11 tokens are only supported by binaries built with cgo")
+
+// PKCS11Store keeps a key in a PKCS #11 token; it needs cgo.
+type PKCS11Store struct{}
+
+// OpenPKCS11 fails since the binary has been built without cgo.
+func OpenPKCS11(uri PKCS11URI, pin crypto.Passphrase) (*PKCS11Store, error) {
+	return nil, errNoCgo
+}
+
+func (s *PKCS11Store) Key() (*crypto.ThresholdKey, error) {
+	return nil, errNoCgo
+}
+
+func (s *PKCS11Store) Import(key *crypto.ThresholdKey) error {
+	return errNoCgo
+}
+
+func (s *PKCS11Store) Close() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+// +build cgo
+
+package keystore_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/raphasch/hotcertification/crypto/keystore"
+)
+
+// softHSM creates a token in a new SoftHSM store and returns the URI of the object n1 in it. The test is skipped
+// if SoftHSM isn't installed; SOFTHSM2_MODULE overrides where its library is looked for.
+func softHSM(t *testing.T) keystore.PKCS11URI {
+	t.Helper()
+	util, err := exec.LookPath("softhsm2-util")
+	if err != nil {
+		t.Skip("softhsm2-util isn't installed")
+	}
+	module := os.Getenv("SOFTHSM2_MODULE")
+	for _, path := range []string{
+		"/usr/lib/softhsm/libsofthsm2.so",
+		"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+		"/usr/local/lib/softhsm/libsofthsm2.so",
+	} {
+		if module != "" {
+			break
+		}
+		if _, err := os.Stat(path); err == nil {
+			module = path
+		}
+	}
+	if module == "" {
+		t.Skip("libsofthsm2.so isn't installed")
+	}
+
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "softhsm2.conf")
+	err = os.WriteFile(conf, []byte(fmt.Sprintf("directories.tokendir = %v\nobjectstore.backend = file\n", dir)), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, set := os.LookupEnv("SOFTHSM2_CONF")
+	os.Setenv("SOFTHSM2_CONF", conf)
+	t.Cleanup(func() {
+		if set {
+			os.Setenv("SOFTHSM2_CONF", old)
+		} else {
+			os.Unsetenv("SOFTHSM2_CONF")
+		}
+	})
+
+	out, err := exec.Command(util, "--init-token", "--free", "--label", "hotcert", "--pin", "1234", "--so-pin", "5678").CombinedOutput()
+	if err != nil {
+		t.Fatalf("cannot create token: %v: %s", err, out)
+	}
+	return keystore.PKCS11URI{Token: "hotcert", Object: "n1", ModulePath: module}
+}
+
+func TestPKCS11Store(t *testing.T) {
+	uri := softHSM(t)
+	keys := thresholdKeys(t)
+
+	store, err := keystore.OpenPKCS11(uri, pin("1234"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Import(keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Import(keys[0]); err == nil {
+		t.Error("share has been imported twice")
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := keystore.Open(uri.String(), pin("4321")); err == nil {
+		t.Error("logged in with the wrong PIN")
+	}
+
+	opened, err := keystore.Open(uri.String(), pin("1234"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer opened.Close()
+	key, err := opened.Key()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key.KeyShare.Si) != 0 || key.Signer == nil {
+		t.Error("share has been read out of the token")
+	}
+	signs(t, key, keys[0])
+}
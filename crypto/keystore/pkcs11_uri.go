@@ -0,0 +1,76 @@
+package keystore
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PKCS11URI names a key share in a PKCS #11 token, following RFC 7512:
+//
+//	pkcs11:token=LABEL;object=LABEL?module-path=PATH
+type PKCS11URI struct {
+	Token      string // label of the token
+	Object     string // label of the objects the share is kept in
+	ModulePath string // path of the PKCS #11 library of the token
+}
+
+func (u PKCS11URI) String() string {
+	return fmt.Sprintf("pkcs11:token=%v;object=%v?module-path=%v",
+		url.PathEscape(u.Token), url.PathEscape(u.Object), url.QueryEscape(u.ModulePath))
+}
+
+// ParsePKCS11URI parses a PKCS #11 URI; token, object and module-path are required, other attributes are refused.
+func ParsePKCS11URI(s string) (PKCS11URI, error) {
+	var u PKCS11URI
+	if !IsPKCS11(s) {
+		return u, fmt.Errorf("%q isn't a PKCS #11 URI", s)
+	}
+	path, query := strings.TrimPrefix(s, "pkcs11:"), ""
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+
+	attrs := map[string]*string{"token": &u.Token, "object": &u.Object}
+	for _, attr := range strings.Split(path, ";") {
+		if err := setAttr(attrs, attr, url.PathUnescape); err != nil {
+			return u, fmt.Errorf("invalid PKCS #11 URI %q: %w", s, err)
+		}
+	}
+	attrs = map[string]*string{"module-path": &u.ModulePath}
+	for _, attr := range strings.Split(query, "&") {
+		if err := setAttr(attrs, attr, url.QueryUnescape); err != nil {
+			return u, fmt.Errorf("invalid PKCS #11 URI %q: %w", s, err)
+		}
+	}
+
+	switch {
+	case u.Token == "":
+		return u, fmt.Errorf("PKCS #11 URI %q has no token", s)
+	case u.Object == "":
+		return u, fmt.Errorf("PKCS #11 URI %q has no object", s)
+	case u.ModulePath == "":
+		return u, fmt.Errorf("PKCS #11 URI %q has no module-path", s)
+	}
+	return u, nil
+}
+
+func setAttr(attrs map[string]*string, attr string, unescape func(string) (string, error)) error {
+	if attr == "" {
+		return nil
+	}
+	kv := strings.SplitN(attr, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("attribute %q has no value", attr)
+	}
+	dest, ok := attrs[kv[0]]
+	if !ok {
+		return fmt.Errorf("unsupported attribute %q", kv[0])
+	}
+	value, err := unescape(kv[1])
+	if err != nil {
+		return err
+	}
+	*dest = value
+	return nil
+}
@@ -0,0 +1,132 @@
+package crypto
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// PassphraseEnv is the environment variable the passphrase of a threshold key is read from if no other source
+// is given.
+const PassphraseEnv = "HOTCERTIFICATION_PASSPHRASE"
+
+// Passphrase returns a passphrase; prompt tells what it is for in case it is asked on the terminal. The caller
+// should wipe the passphrase once it has been used.
+type Passphrase func(prompt string) ([]byte, error)
+
+// PassphraseSource returns the source of passphrases that spec names:
+//
+//	prompt     asks on the terminal; twice if confirm is set
+//	env:NAME   the environment variable NAME
+//	file:PATH  the first line of the file PATH
+//	fd:N       the next line from the file descriptor N on every call, e.g. one per node
+//	none       no passphrase; PassphraseSource returns nil
+//
+// An empty spec reads PassphraseEnv if it is set and prompts otherwise.
+func PassphraseSource(spec string, confirm bool) (Passphrase, error) {
+	kind, arg := spec, ""
+	if i := strings.IndexByte(spec, ':'); i >= 0 {
+		kind, arg = spec[:i], spec[i+1:]
+	}
+
+	switch kind {
+	case "":
+		if _, ok := os.LookupEnv(PassphraseEnv); ok {
+			return envPassphrase(PassphraseEnv), nil
+		}
+		return promptPassphrase(confirm), nil
+	case "none":
+		return nil, nil
+	case "prompt":
+		return promptPassphrase(confirm), nil
+	case "env":
+		if arg == "" {
+			return nil, fmt.Errorf("passphrase source %q names no environment variable", spec)
+		}
+		return envPassphrase(arg), nil
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("passphrase source %q names no file", spec)
+		}
+		return func(string) ([]byte, error) {
+			raw, err := os.ReadFile(arg)
+			if err != nil {
+				return nil, err
+			}
+			defer Wipe(raw)
+			line := raw
+			if i := bytes.IndexByte(raw, '\n'); i >= 0 {
+				line = raw[:i]
+			}
+			return nonEmpty(bytes.TrimSuffix(line, []byte("\r")), "file "+arg)
+		}, nil
+	case "fd":
+		fd, err := strconv.Atoi(arg)
+		if err != nil || fd < 0 {
+			return nil, fmt.Errorf("passphrase source %q names no file descriptor", spec)
+		}
+		r := bufio.NewReader(os.NewFile(uintptr(fd), "fd"+arg))
+		return func(string) ([]byte, error) {
+			line, err := r.ReadBytes('\n')
+			if err != nil && (err != io.EOF || len(line) == 0) {
+				return nil, fmt.Errorf("cannot read passphrase from file descriptor %v: %w", fd, err)
+			}
+			return nonEmpty(bytes.TrimRight(line, "\r\n"), "file descriptor "+arg)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown passphrase source %q: it must be prompt, env:NAME, file:PATH, fd:N or none", spec)
+	}
+}
+
+func envPassphrase(name string) Passphrase {
+	return func(string) ([]byte, error) {
+		return nonEmpty([]byte(os.Getenv(name)), "environment variable "+name)
+	}
+}
+
+func promptPassphrase(confirm bool) Passphrase {
+	return func(prompt string) ([]byte, error) {
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return nil, fmt.Errorf("cannot prompt for the passphrase: stdin isn't a terminal")
+		}
+		read := func(prompt string) ([]byte, error) {
+			fmt.Fprintf(os.Stderr, "%v: ", prompt)
+			pass, err := term.ReadPassword(fd)
+			fmt.Fprintln(os.Stderr)
+			if err != nil {
+				return nil, err
+			}
+			return nonEmpty(pass, "prompt")
+		}
+
+		pass, err := read(prompt)
+		if err != nil || !confirm {
+			return pass, err
+		}
+		again, err := read("Repeat " + strings.ToLower(prompt[:1]) + prompt[1:])
+		if err != nil {
+			Wipe(pass)
+			return nil, err
+		}
+		defer Wipe(again)
+		if !bytes.Equal(pass, again) {
+			Wipe(pass)
+			return nil, fmt.Errorf("passphrases don't match")
+		}
+		return pass, nil
+	}
+}
+
+func nonEmpty(pass []byte, source string) ([]byte, error) {
+	if len(pass) == 0 {
+		return nil, fmt.Errorf("passphrase from %v is empty", source)
+	}
+	return append([]byte(nil), pass...), nil
+}
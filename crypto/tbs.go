@@ -0,0 +1,197 @@
+package crypto
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"time"
+)
+
+/*
+Building certificates:
+
+"crypto/x509" only creates certificates it can sign itself, with a whole private key. The TBS (to be signed) part
+of the certificates of the CA is therefore built here (RFC 5280, section 4.1) and wrapped into a certificate with
+an empty signature, so that it can be parsed and handed around like any other certificate until a threshold of
+replicas signed it. withSignature then inserts the signature.
+*/
+
+// OIDs of the extensions of certificates (RFC 5280, section 4.2.1) and of the signature algorithms (RFC 4055)
+var (
+	oidExtensionSubjectKeyID     = asn1.ObjectIdentifier{2, 5, 29, 14}
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionSubjectAltName   = asn1.ObjectIdentifier{2, 5, 29, 17}
+	oidExtensionBasicConstraints = asn1.ObjectIdentifier{2, 5, 29, 19}
+	oidExtensionAuthorityKeyID   = asn1.ObjectIdentifier{2, 5, 29, 35}
+	oidSignatureSHA256WithRSA    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}
+	oidSignatureSHA384WithRSA    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 12}
+	oidSignatureSHA512WithRSA    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 13}
+	signatureAlgorithmsByHash    = map[crypto.Hash]x509.SignatureAlgorithm{
+		crypto.SHA256: x509.SHA256WithRSA,
+		crypto.SHA384: x509.SHA384WithRSA,
+		crypto.SHA512: x509.SHA512WithRSA,
+	}
+	signatureAlgorithmOIDs = map[x509.SignatureAlgorithm]asn1.ObjectIdentifier{
+		x509.SHA256WithRSA: oidSignatureSHA256WithRSA,
+		x509.SHA384WithRSA: oidSignatureSHA384WithRSA,
+		x509.SHA512WithRSA: oidSignatureSHA512WithRSA,
+	}
+)
+
+type tbsCertificate struct {
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm pkix.AlgorithmIdentifier
+	Issuer             asn1.RawValue
+	Validity           validity
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue    // SubjectPublicKeyInfo
+	Extensions         []pkix.Extension `asn1:"optional,explicit,tag:3"`
+}
+
+type validity struct {
+	NotBefore, NotAfter time.Time
+}
+
+type basicConstraints struct {
+	IsCA bool `asn1:"optional"`
+}
+
+type authorityKeyID struct {
+	ID []byte `asn1:"optional,tag:0"`
+}
+
+// signatureAlgorithm returns the PKCS #1 v1.5 signature algorithm with hashType.
+func signatureAlgorithm(hashType crypto.Hash) (x509.SignatureAlgorithm, error) {
+	alg, ok := signatureAlgorithmsByHash[hashType]
+	if !ok {
+		return x509.UnknownSignatureAlgorithm, fmt.Errorf("unsupported hash function %v; it has to be SHA-256, SHA-384 or SHA-512", hashType)
+	}
+	return alg, nil
+}
+
+// createCertificate builds the certificate of template for pub, issued by issuer with the key of signer, with an
+// empty signature. If issuer is nil, the certificate is self-signed. Of the template only the serial number,
+// subject, validity, key usage, basic constraints, email addresses and extra extensions are used.
+func createCertificate(template, issuer *x509.Certificate, pub interface{}, signer *ThresholdKey) (*x509.Certificate, error) {
+	alg, err := signatureAlgorithm(signer.HashType)
+	if err != nil {
+		return nil, err
+	}
+	algID := pkix.AlgorithmIdentifier{Algorithm: signatureAlgorithmOIDs[alg], Parameters: asn1.NullRawValue}
+
+	publicKeyInfo, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	subject, err := asn1.Marshal(template.Subject.ToRDNSequence())
+	if err != nil {
+		return nil, err
+	}
+	issuerName, authorityID := subject, []byte(nil)
+	if issuer != nil {
+		issuerName, authorityID = issuer.RawSubject, issuer.SubjectKeyId
+	}
+
+	extensions, err := certExtensions(template, publicKeyInfo, authorityID)
+	if err != nil {
+		return nil, err
+	}
+
+	tbs, err := asn1.Marshal(tbsCertificate{
+		Version:            2,
+		SerialNumber:       template.SerialNumber,
+		SignatureAlgorithm: algID,
+		Issuer:             asn1.RawValue{FullBytes: issuerName},
+		Validity:           validity{template.NotBefore.UTC().Truncate(time.Second), template.NotAfter.UTC().Truncate(time.Second)},
+		Subject:            asn1.RawValue{FullBytes: subject},
+		PublicKey:          asn1.RawValue{FullBytes: publicKeyInfo},
+		Extensions:         extensions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rawAlgID, err := asn1.Marshal(algID)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := asn1.Marshal(signedCertificate{
+		TBSCertificate:     asn1.RawValue{FullBytes: tbs},
+		SignatureAlgorithm: asn1.RawValue{FullBytes: rawAlgID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// certExtensions returns the extensions of a certificate for the key in publicKeyInfo. The key identifier of the
+// issuer is left out if authorityID is empty.
+func certExtensions(template *x509.Certificate, publicKeyInfo []byte, authorityID []byte) ([]pkix.Extension, error) {
+	var exts []pkix.Extension
+	add := func(id asn1.ObjectIdentifier, critical bool, value interface{}) error {
+		raw, err := asn1.Marshal(value)
+		if err == nil {
+			exts = append(exts, pkix.Extension{Id: id, Critical: critical, Value: raw})
+		}
+		return err
+	}
+
+	if template.IsCA {
+		// key identifier of RFC 7093, section 2, method 1
+		var spki struct {
+			Algorithm pkix.AlgorithmIdentifier
+			PublicKey asn1.BitString
+		}
+		if _, err := asn1.Unmarshal(publicKeyInfo, &spki); err != nil {
+			return nil, err
+		}
+		h := sha256.Sum256(spki.PublicKey.Bytes)
+		if err := add(oidExtensionSubjectKeyID, false, h[:20]); err != nil {
+			return nil, err
+		}
+	}
+	if template.KeyUsage != 0 {
+		if err := add(oidExtensionKeyUsage, true, keyUsageBits(template.KeyUsage)); err != nil {
+			return nil, err
+		}
+	}
+	if len(authorityID) > 0 {
+		if err := add(oidExtensionAuthorityKeyID, false, authorityKeyID{ID: authorityID}); err != nil {
+			return nil, err
+		}
+	}
+	if template.BasicConstraintsValid {
+		if err := add(oidExtensionBasicConstraints, true, basicConstraints{IsCA: template.IsCA}); err != nil {
+			return nil, err
+		}
+	}
+	if len(template.EmailAddresses) > 0 {
+		names := make([]asn1.RawValue, len(template.EmailAddresses))
+		for i, email := range template.EmailAddresses {
+			// rfc822Name [1] IA5String
+			names[i] = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 1, Bytes: []byte(email)}
+		}
+		if err := add(oidExtensionSubjectAltName, false, names); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(exts, template.ExtraExtensions...), nil
+}
+
+// keyUsageBits encodes the key usage as the named bit list of RFC 5280, section 4.2.1.3, in which bit 0 is
+// digitalSignature, without trailing zero bits.
+func keyUsageBits(usage x509.KeyUsage) asn1.BitString {
+	raw := []byte{bits.Reverse8(byte(usage)), bits.Reverse8(byte(usage >> 8))}
+	if raw[1] == 0 {
+		raw = raw[:1]
+	}
+	return asn1.BitString{Bytes: raw, BitLength: 8*len(raw) - bits.TrailingZeros8(raw[len(raw)-1])}
+}
@@ -0,0 +1,149 @@
+package crypto_test
+
+import (
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/niclabs/tcrsa"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+// TestKeySizes issues certificates with keys of real sizes and hashes from the root certificate to the client.
+func TestKeySizes(t *testing.T) {
+	for _, tc := range []struct {
+		bits int
+		hash stdcrypto.Hash
+	}{
+		{2048, stdcrypto.SHA256},
+		{2048, stdcrypto.SHA384},
+		{3072, stdcrypto.SHA512},
+		{4096, stdcrypto.SHA512},
+	} {
+		t.Run(fmt.Sprintf("%v-%v", tc.bits, tc.hash), func(t *testing.T) {
+			if tc.bits > 2048 && testing.Short() {
+				t.Skip("large key")
+			}
+			caKey, err := rsa.GenerateKey(rand.Reader, tc.bits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			imported := selfSigned(t, caKey)
+			keys, err := crypto.ImportCA(threshold, num, caKey, imported, tc.hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the key and its hash survive the key file
+			path := filepath.Join(t.TempDir(), "n1.thresholdkey")
+			if err := crypto.WriteThresholdKeyFile(keys[0], path); err != nil {
+				t.Fatal(err)
+			}
+			if keys[0], err = crypto.ReadThresholdKeyFile(path); err != nil {
+				t.Fatal(err)
+			}
+			if keys[0].HashType != tc.hash || keys[0].Size() != tc.bits {
+				t.Fatalf("read a key of %v bits with %v", keys[0].Size(), keys[0].HashType)
+			}
+
+			// a root certificate signed by the shares themselves
+			root, err := crypto.GenerateRootCert(keys[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root = thresholdSign(t, root, keys); root.CheckSignatureFrom(root) != nil {
+				t.Fatalf("root certificate isn't self-signed: %v", root.CheckSignatureFrom(root))
+			}
+
+			for _, issuer := range []*x509.Certificate{root, imported} {
+				clientKey, err := rsa.GenerateKey(rand.Reader, 1024)
+				if err != nil {
+					t.Fatal(err)
+				}
+				csr, err := crypto.GenerateCSR(clientKey)
+				if err != nil {
+					t.Fatal(err)
+				}
+				cert, err := crypto.GenerateCert(csr, issuer, keys[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				cert = thresholdSign(t, cert, keys)
+
+				roots := x509.NewCertPool()
+				roots.AddCert(issuer)
+				if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+					t.Errorf("certificate of %v doesn't verify: %v", issuer.Subject.CommonName, err)
+				}
+				if !reflect.DeepEqual(cert.EmailAddresses, csr.EmailAddresses) || cert.Subject.String() != csr.Subject.String() {
+					t.Errorf("certificate is for %v <%v>, not for the subject of the CSR", cert.Subject, cert.EmailAddresses)
+				}
+				if !cert.PublicKey.(*rsa.PublicKey).Equal(&clientKey.PublicKey) {
+					t.Error("certificate isn't for the key of the client")
+				}
+			}
+		})
+	}
+}
+
+func TestHashTooLarge(t *testing.T) {
+	if _, _, err := crypto.GenerateCA(threshold, num, 512, stdcrypto.SHA512); err == nil {
+		t.Error("512 bit key signs with SHA-512")
+	}
+	if _, _, err := crypto.GenerateCA(threshold, num, 512, stdcrypto.SHA1); err == nil {
+		t.Error("key signs with SHA-1")
+	}
+}
+
+// thresholdSign signs a certificate with the first threshold keys.
+func thresholdSign(t *testing.T, cert *x509.Certificate, keys []*crypto.ThresholdKey) *x509.Certificate {
+	t.Helper()
+	shares := make(tcrsa.SigShareList, threshold)
+	for i, key := range keys[:threshold] {
+		share, err := crypto.ComputePartialSignature(cert, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crypto.VerifyPartialSignature(cert, keys[0], share); err != nil {
+			t.Fatal(err)
+		}
+		shares[i] = share
+	}
+	signed, err := crypto.ComputeFullySignedCert(cert, keys[0], shares...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+// selfSigned returns the certificate of an existing CA.
+func selfSigned(t *testing.T, key *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Existing CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
@@ -1,11 +1,13 @@
 package crypto
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
-	"crypto/sha256"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/asn1"
 	"encoding/pem"
 	"fmt"
 	"math/big"
@@ -21,40 +23,142 @@ import (
 
 const thresholdKeyFileType = "HOTCERTIFICATION THRESHOLD KEY"
 
-const keySize = 512
-
 func ComputePartialSignature(certificate *x509.Certificate, key *ThresholdKey) (partialSig *tcrsa.SigShare, err error) {
 	// extract the RawTBSCertificate and sign that
 	// TBS = to be signed
-	tbsCert := certificate.RawTBSCertificate
+	certHash, err := tbsDigest(certificate, key)
+	if err != nil {
+		return nil, err
+	}
+	return SignDigestShare(certHash, key.HashType, key)
+}
+
+// VerifyPartialSignature checks that share is a valid signature share on the certificate.
+func VerifyPartialSignature(certificate *x509.Certificate, key *ThresholdKey, share *tcrsa.SigShare) error {
+	certHash, err := tbsDigest(certificate, key)
+	if err != nil {
+		return err
+	}
+	return VerifyDigestShare(certHash, key.HashType, key, share)
+}
+
+func ComputeFullySignedCert(certificate *x509.Certificate, key *ThresholdKey, partialSigs ...*tcrsa.SigShare) (*x509.Certificate, error) {
+	// extract the RawTBSCertificate and sign that
+	// TBS = to be signed
+	certHash, err := tbsDigest(certificate, key)
+	if err != nil {
+		return certificate, err
+	}
+
+	signature, err := JoinDigestShares(certHash, key.HashType, key, partialSigs...)
+	if err != nil {
+		return certificate, err
+	}
 
-	// hash the certificate data
-	certHash := sha256.Sum256(tbsCert)
+	// insert the full rsa signature; the DER is encoded again so that the certificate can be handed on as is
+	return withSignature(certificate, signature)
+}
+
+// tbsDigest hashes the TBS part of the certificate with the hash of key. The certificate has to name the signature
+// algorithm of the key, or the signature wouldn't verify.
+func tbsDigest(certificate *x509.Certificate, key *ThresholdKey) ([]byte, error) {
+	alg, err := signatureAlgorithm(key.HashType)
+	if err != nil {
+		return nil, err
+	}
+	if certificate.SignatureAlgorithm != alg {
+		return nil, fmt.Errorf("certificate is to be signed with %v, but the key signs with %v", certificate.SignatureAlgorithm, alg)
+	}
+	h := key.HashType.New()
+	h.Write(certificate.RawTBSCertificate)
+	return h.Sum(nil), nil
+}
+
+// signedCertificate is the outer structure of an X.509 certificate (RFC 5280, section 4.1).
+type signedCertificate struct {
+	TBSCertificate     asn1.RawValue
+	SignatureAlgorithm asn1.RawValue
+	SignatureValue     asn1.BitString
+}
+
+// withSignature returns the certificate with its signature replaced.
+func withSignature(certificate *x509.Certificate, signature []byte) (*x509.Certificate, error) {
+	var signed signedCertificate
+	if _, err := asn1.Unmarshal(certificate.Raw, &signed); err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	signed.SignatureValue = asn1.BitString{Bytes: signature, BitLength: 8 * len(signature)}
+
+	der, err := asn1.Marshal(signed)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// SignDigestShare computes a signature share on the digest of some data, e.g. the TBS part of a certificate, which
+// has been hashed with hashType. Certificates are hashed with key.HashType; other data, like the tree heads of the
+// certificate log, with the hash their format prescribes.
+func SignDigestShare(digest []byte, hashType crypto.Hash, key *ThresholdKey) (*tcrsa.SigShare, error) {
 	// padding hash to conform to PKCS1 standard
-	certPaddedHash, err := tcrsa.PrepareDocumentHash(key.KeyMeta.PublicKey.Size(), key.HashType, certHash[:])
+	paddedHash, err := tcrsa.PrepareDocumentHash(key.KeyMeta.PublicKey.Size(), hashType, digest)
 	if err != nil {
 		return nil, err
 	}
 
-	partialSig, err = key.KeyShare.Sign(certPaddedHash, key.HashType, key.KeyMeta)
+	var partialSig *tcrsa.SigShare
+	if key.Signer != nil {
+		partialSig, err = key.Signer.SignShare(paddedHash, hashType, key.KeyMeta)
+	} else {
+		partialSig, err = key.KeyShare.Sign(paddedHash, hashType, key.KeyMeta)
+	}
+	if err != nil {
+		return nil, err
+	}
 
 	// TODO: Do I need this? Kick out in optimization
-	if err := partialSig.Verify(certPaddedHash, key.KeyMeta); err != nil {
+	if err := partialSig.Verify(paddedHash, key.KeyMeta); err != nil {
 		return nil, err
 	}
 
-	return partialSig, err
+	return partialSig, nil
 }
 
-func ComputeFullySignedCert(certificate *x509.Certificate, key *ThresholdKey, partialSigs ...*tcrsa.SigShare) (*x509.Certificate, error) {
-	// extract the RawTBSCertificate and sign that
-	// TBS = to be signed
-	tbsCert := certificate.RawTBSCertificate
+// VerifyDigestShare checks that share is a valid signature share on the digest, which has been hashed with hashType.
+func VerifyDigestShare(digest []byte, hashType crypto.Hash, key *ThresholdKey, share *tcrsa.SigShare) error {
+	paddedHash, err := tcrsa.PrepareDocumentHash(key.KeyMeta.PublicKey.Size(), hashType, digest)
+	if err != nil {
+		return err
+	}
+	return share.Verify(paddedHash, key.KeyMeta)
+}
 
-	// hash the certificate data
-	certHash := sha256.Sum256(tbsCert)
-	// padding hash to conform to PKCS1 standard
-	certPaddedHash, err := tcrsa.PrepareDocumentHash(key.KeyMeta.PublicKey.Size(), key.HashType, certHash[:])
+// CheckShare checks that the share of key matches its verification key. A share that is kept in a key store is
+// checked by signing a random digest with it and verifying the proof of correctness of the signature share.
+func CheckShare(key *ThresholdKey) error {
+	if len(key.KeyShare.Si) > 0 {
+		if err := VerifyKeyShare(key); err != nil {
+			return err
+		}
+	}
+	digest := make([]byte, key.HashType.Size())
+	if _, err := rand.Read(digest); err != nil {
+		return err
+	}
+	share, err := SignDigestShare(digest, key.HashType, key)
+	if err != nil {
+		return fmt.Errorf("share of node %v can't sign: %w", key.KeyShare.Id, err)
+	}
+	if err := VerifyDigestShare(digest, key.HashType, key, share); err != nil {
+		return fmt.Errorf("signature share of node %v is invalid: %w", key.KeyShare.Id, err)
+	}
+	return nil
+}
+
+// JoinDigestShares combines signature shares on the digest, which has been hashed with hashType, into a PKCS #1 v1.5
+// signature that can be verified with the public key of the CA.
+func JoinDigestShares(digest []byte, hashType crypto.Hash, key *ThresholdKey, partialSigs ...*tcrsa.SigShare) ([]byte, error) {
+	paddedHash, err := tcrsa.PrepareDocumentHash(key.KeyMeta.PublicKey.Size(), hashType, digest)
 	if err != nil {
 		return nil, err
 	}
@@ -64,20 +168,38 @@ func ComputeFullySignedCert(certificate *x509.Certificate, key *ThresholdKey, pa
 	// TODO: This throws an error
 	// verify partial signature
 	for _, share := range signatures {
-		if err := share.Verify(certPaddedHash, key.KeyMeta); err != nil {
+		if err := share.Verify(paddedHash, key.KeyMeta); err != nil {
 			return nil, err
 		}
 	}
 
-	signature, err := signatures.Join(certPaddedHash, key.KeyMeta)
-	// insert the full rsa signature
-	certificate.Signature = signature
-	certificate.SignatureAlgorithm = x509.SHA256WithRSA
-
-	return certificate, err
+	var signature []byte
+	if key.DeltaShares {
+		signature, err = joinDeltaShares(paddedHash, key.KeyMeta, signatures)
+	} else {
+		signature, err = signatures.Join(paddedHash, key.KeyMeta)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := rsa.VerifyPKCS1v15(key.Public(), hashType, digest, signature); err != nil {
+		return nil, fmt.Errorf("joined signature is invalid: %w", err)
+	}
+	return signature, nil
 }
 
+// ComputeTresholdKeys splits a new key of keySize bits that signs with SHA-256 into n shares of which threshold are
+// needed to sign.
 func ComputeTresholdKeys(threshold uint16, n uint16, keySize int) (thresholdKeys []*ThresholdKey, err error) {
+	return computeThresholdKeys(threshold, n, keySize, crypto.SHA256)
+}
+
+func computeThresholdKeys(threshold uint16, n uint16, keySize int, hashType crypto.Hash) (thresholdKeys []*ThresholdKey, err error) {
+	// the hash is checked before the key is generated, which takes long for large keys
+	if _, err := signatureAlgorithm(hashType); err != nil {
+		return nil, err
+	}
+
 	// trusted dealer computes key shares
 	keyShares, keyMeta, err := tcrsa.NewKey(keySize, threshold, n, nil)
 	if err != nil {
@@ -86,7 +208,7 @@ func ComputeTresholdKeys(threshold uint16, n uint16, keySize int) (thresholdKeys
 	thresholdKeys = make([]*ThresholdKey, n)
 	for i, share := range keyShares {
 		// wrapping key in the crypto/signer interface
-		thresholdKeys[i], err = NewThresholdKey(share, keyMeta, keySize)
+		thresholdKeys[i], err = NewThresholdKey(share, keyMeta, hashType)
 		if err != nil {
 			return nil, err
 		}
@@ -94,17 +216,54 @@ func ComputeTresholdKeys(threshold uint16, n uint16, keySize int) (thresholdKeys
 	return thresholdKeys, err
 }
 
-func GenerateCert(csr *x509.CertificateRequest, issuer *x509.Certificate, issuerKey *ThresholdKey) (cert *x509.Certificate, err error) {
+// Extensions of Certificate Transparency (RFC 6962, section 3)
+var (
+	// OIDPrecertificatePoison marks a precertificate, which mustn't be accepted as certificate
+	OIDPrecertificatePoison = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
+	// OIDSCTList holds the SCTs of the logs the precertificate has been submitted to
+	OIDSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+)
+
+// CertOption changes the certificate generated by GenerateCert.
+type CertOption func(cert *x509.Certificate) error
+
+// Precertificate generates a precertificate with the poison extension that can be submitted to CT logs.
+func Precertificate() CertOption {
+	return func(cert *x509.Certificate) error {
+		cert.ExtraExtensions = append(cert.ExtraExtensions, pkix.Extension{
+			Id:       OIDPrecertificatePoison,
+			Critical: true,
+			Value:    asn1.NullBytes,
+		})
+		return nil
+	}
+}
+
+// FromPrecertificate generates the certificate for a precertificate with the SCTs returned by the CT logs embedded.
+// sctList is the SignedCertificateTimestampList (RFC 6962, section 3.3). Apart from the extensions the certificate is
+// the same as the precertificate, which is what the SCTs have been issued for.
+func FromPrecertificate(precert *x509.Certificate, sctList []byte) CertOption {
+	return func(cert *x509.Certificate) error {
+		value, err := asn1.Marshal(sctList)
+		if err != nil {
+			return err
+		}
+		cert.SerialNumber = precert.SerialNumber
+		cert.NotBefore = precert.NotBefore
+		cert.NotAfter = precert.NotAfter
+		cert.ExtraExtensions = append(cert.ExtraExtensions, pkix.Extension{Id: OIDSCTList, Value: value})
+		return nil
+	}
+}
+
+func GenerateCert(csr *x509.CertificateRequest, issuer *x509.Certificate, issuerKey *ThresholdKey, opts ...CertOption) (cert *x509.Certificate, err error) {
 	sn, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
 	if err != nil {
-		fmt.Printf("%v\n", err)
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
 	}
 
 	cert = &x509.Certificate{
 		SerialNumber:          sn,
-		Issuer:                pkix.Name{CommonName: "HotCertification Authority"},
-		SignatureAlgorithm:    csr.SignatureAlgorithm,
-		PublicKeyAlgorithm:    csr.PublicKeyAlgorithm,
 		Subject:               csr.Subject,
 		EmailAddresses:        csr.EmailAddresses,
 		NotBefore:             time.Now(),
@@ -112,13 +271,81 @@ func GenerateCert(csr *x509.CertificateRequest, issuer *x509.Certificate, issuer
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
 		BasicConstraintsValid: true,
 	}
+	for _, opt := range opts {
+		if err := opt(cert); err != nil {
+			return nil, err
+		}
+	}
 
-	bytes, err := x509.CreateCertificate(rand.Reader, cert, issuer, csr.PublicKey, issuerKey.DummyPrivKey)
+	// the certificate isn't signed yet; the replicas sign its TBS part
+	cert, err = createCertificate(cert, issuer, csr.PublicKey, issuerKey)
 	if err != nil {
-		fmt.Println(err)
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// MatchCSR checks that cert has been generated by GenerateCert for csr: that it is for the key, the subject and
+// the email addresses of the CSR and names nothing else.
+func MatchCSR(cert *x509.Certificate, csr *x509.CertificateRequest) error {
+	pub, ok := csr.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !pub.Equal(cert.PublicKey) {
+		return fmt.Errorf("certificate isn't for the key of the CSR")
+	}
+	subject, err := asn1.Marshal(csr.Subject.ToRDNSequence())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(subject, cert.RawSubject) {
+		return fmt.Errorf("certificate is for %v, not for %v", cert.Subject, csr.Subject)
+	}
+	if len(cert.DNSNames) > 0 || len(cert.IPAddresses) > 0 || len(cert.URIs) > 0 || !equalStrings(cert.EmailAddresses, csr.EmailAddresses) {
+		return fmt.Errorf("alternative names of the certificate differ from the CSR")
+	}
+	return nil
+}
+
+// CheckIssuance checks that cert is a certificate or precertificate that GenerateCert generates for csr with
+// issuer: that it matches the CSR, has been issued by issuer, isn't a CA and has no other extensions than those
+// GenerateCert adds.
+func CheckIssuance(cert *x509.Certificate, csr *x509.CertificateRequest, issuer *x509.Certificate) error {
+	if err := MatchCSR(cert, csr); err != nil {
+		return err
+	}
+	if !bytes.Equal(cert.RawIssuer, issuer.RawSubject) {
+		return fmt.Errorf("certificate is issued by %v, not by %v", cert.Issuer, issuer.Subject)
+	}
+	if !cert.BasicConstraintsValid || cert.IsCA {
+		return fmt.Errorf("certificate isn't restricted to end entities")
+	}
+	if cert.KeyUsage != x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment {
+		return fmt.Errorf("certificate has key usage %b", cert.KeyUsage)
+	}
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionKeyUsage), ext.Id.Equal(oidExtensionBasicConstraints),
+			ext.Id.Equal(oidExtensionSubjectAltName), ext.Id.Equal(OIDPrecertificatePoison), ext.Id.Equal(OIDSCTList):
+		case ext.Id.Equal(oidExtensionAuthorityKeyID):
+			if !bytes.Equal(cert.AuthorityKeyId, issuer.SubjectKeyId) {
+				return fmt.Errorf("certificate names another key of the issuer")
+			}
+		default:
+			return fmt.Errorf("certificate has unexpected extension %v", ext.Id)
+		}
 	}
+	return nil
+}
 
-	return x509.ParseCertificate(bytes)
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
 
 func GenerateCSR(clientPrivKey *rsa.PrivateKey) (cert *x509.CertificateRequest, err error) {
@@ -149,7 +376,6 @@ func GenerateRootCert(key *ThresholdKey) (cert *x509.Certificate, err error) {
 	caTmpl := &x509.Certificate{
 		SerialNumber:          sn,
 		Subject:               pkix.Name{CommonName: "HotCertificationAuthority"},
-		Issuer:                pkix.Name{CommonName: "HotCertificationAuthority"},
 		NotBefore:             time.Now(),
 		NotAfter:              time.Now().AddDate(10, 0, 0),
 		KeyUsage:              x509.KeyUsageCertSign,
@@ -157,14 +383,8 @@ func GenerateRootCert(key *ThresholdKey) (cert *x509.Certificate, err error) {
 		BasicConstraintsValid: true,
 	}
 
-	// self-signed with dummyPrivKey
-	// TODO: sign through threshold signing mechanism (tcrsa); see above
-	caBytes, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, key.Public(), key.DummyPrivKey)
-	if err != nil {
-		return nil, err
-	}
-
-	return x509.ParseCertificate(caBytes)
+	// self-signed; the TBS part is signed by a threshold of the shares (see GenerateCA)
+	return createCertificate(caTmpl, nil, key.Public(), key)
 }
 
 func KeyToBytes(key *ThresholdKey) ([]byte, error) {
@@ -188,12 +408,16 @@ func KeyToBytes(key *ThresholdKey) ([]byte, error) {
 				I: key.KeyMeta.VerificationKey.I,
 			},
 		},
+		HashType:    uint32(key.HashType),
+		DeltaShares: key.DeltaShares,
 	}
 
 	return proto.Marshal(protobufKey)
 }
 
-func KeyFromBytes(bytes []byte, keySize int) (*ThresholdKey, error) {
+// KeyFromBytes reads a key written by KeyToBytes. Keys that have been written before the hash was stored sign with
+// SHA-256.
+func KeyFromBytes(bytes []byte) (*ThresholdKey, error) {
 
 	key := &serial.ThresholdKey{}
 	err := proto.Unmarshal(bytes, key)
@@ -223,7 +447,17 @@ func KeyFromBytes(bytes []byte, keySize int) (*ThresholdKey, error) {
 		},
 	}
 
-	return NewThresholdKey(keyShare, keyMeta, keySize)
+	hashType := crypto.Hash(key.HashType)
+	if hashType == 0 {
+		hashType = crypto.SHA256
+	}
+
+	thresholdKey, err := NewThresholdKey(keyShare, keyMeta, hashType)
+	if err != nil {
+		return nil, err
+	}
+	thresholdKey.DeltaShares = key.DeltaShares
+	return thresholdKey, nil
 }
 
 func WriteThresholdKeyFile(key *ThresholdKey, filePath string) (err error) {
@@ -280,21 +514,19 @@ func WriteCertFile(cert *x509.Certificate, filePath string) (err error) {
 }
 
 func ReadThresholdKeyFile(keyFile string) (*ThresholdKey, error) {
-	raw, err := os.ReadFile(keyFile)
+	block, err := readPEMFile(keyFile)
 	if err != nil {
 		return nil, err
 	}
 
-	block, _ := pem.Decode(raw)
-	if block == nil {
-		return nil, fmt.Errorf("failed to decode PEM")
+	if block.Type == encryptedThresholdKeyFileType {
+		return nil, fmt.Errorf("%v: %w", keyFile, ErrEncryptedKey)
 	}
-
 	if block.Type != thresholdKeyFileType {
 		return nil, fmt.Errorf("file type did not match")
 	}
 
-	key, err := KeyFromBytes(block.Bytes, keySize)
+	key, err := KeyFromBytes(block.Bytes)
 	if err != nil {
 		return nil, err
 	}
@@ -343,24 +575,20 @@ This generates the necessary keys and certificates for issuing x509 certificates
 */
 
 // put this function into threshold.go ?
-func GenerateConfiguration(t uint16, n uint16, keySize int, destination string) (err error) {
+func GenerateConfiguration(t uint16, n uint16, keySize int, hashType crypto.Hash, destination string) (err error) {
 	// create public directort that stores open knowledge like certs and public keys
 	err = os.MkdirAll(destination, 0755)
 	if err != nil {
 		return fmt.Errorf("cannot create '%s' directory: %w", destination, err)
 	}
 
-	// trusted dealer computes key shares
-	thresholdKeys, err := ComputeTresholdKeys(t, n, keySize)
+	thresholdKeys, caRootCertificate, err := GenerateCA(t, n, keySize, hashType)
 	if err != nil {
 		return err
 	}
 
 	// Write threshold keys to files
 	for i, key := range thresholdKeys {
-		if err != nil {
-			return fmt.Errorf("cannot create '%s' directory: %w", destination, err)
-		}
 		thresholdKeyPath := filepath.Join(destination, fmt.Sprintf("n%v.thresholdkey", i+1))
 		err = WriteThresholdKeyFile(key, thresholdKeyPath)
 		if err != nil {
@@ -368,20 +596,32 @@ func GenerateConfiguration(t uint16, n uint16, keySize int, destination string)
 		}
 	}
 
+	return WriteCertFile(caRootCertificate, filepath.Join(destination, "root.crt"))
+}
+
+// GenerateCA splits a new key of keySize bits that signs with hashType into n shares of which t are needed to sign and
+// returns the shares, share i+1 at index i, along with the root certificate of the key, which t of the shares signed.
+func GenerateCA(t uint16, n uint16, keySize int, hashType crypto.Hash) (thresholdKeys []*ThresholdKey, caRootCertificate *x509.Certificate, err error) {
+	// trusted dealer computes key shares
+	thresholdKeys, err = computeThresholdKeys(t, n, keySize, hashType)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// wrapping key in the crypto/signer interface
 	caKey := thresholdKeys[0]
 
 	// create a Root certificate for TLS (self-signed)
-	caRootCertificate, err := GenerateRootCert(caKey)
+	caRootCertificate, err = GenerateRootCert(caKey)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	sigShares := make(tcrsa.SigShareList, t)
 	for i := 0; i < int(t); i++ {
 		sigShares[i], err = ComputePartialSignature(caRootCertificate, thresholdKeys[i])
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 
@@ -393,12 +633,7 @@ func GenerateConfiguration(t uint16, n uint16, keySize int, destination string)
 	*/
 	caRootCertificate, err = ComputeFullySignedCert(caRootCertificate, caKey, sigShares...)
 	if err != nil {
-		return err
-	}
-
-	err = WriteCertFile(caRootCertificate, destination+"/root.crt")
-	if err != nil {
-		return err
+		return nil, nil, err
 	}
-	return
+	return thresholdKeys, caRootCertificate, nil
 }
@@ -2,48 +2,62 @@ package crypto
 
 import (
 	"crypto"
-	"crypto/rand"
 	"crypto/rsa"
-	"io"
+	"fmt"
 
 	"github.com/niclabs/tcrsa"
 )
 
 /*
-This data structure has two purposes:
+This data structure holds all the information needed for a replica to generate a partial signature on a
+certificate. The TBS (to be signed) part of a certificate is built in tbs.go without the "crypto/x509" package,
+which can only sign with a whole private key. Its hash is signed through the "github.com/niclabs/tcrsa" library
+and the signature is inserted once a threshold of replicas signed it.
 
-	1. Implementing the "crypto" Signer interface for generating certificates with the "crypto/x509" package
- 	2. Hold all the information needed for a replica to generate a partial signature on a certificate
-
-The dummy private key data and the Sign() method just server purpose 1. This is so I can piggy back on the
-"crypto/x509" library for generating/parsing/marshalling x509 certificates. As part of this the library signs
-the certificate but since I'm using a custom threshold signing scheme I can't use that. So I slice out the
-signature part and TBS(to be signed) part of the certificate and later add it back with the signature
-generated through the "github.com/niclabs/tcrsa" library.
+The size of the key is the size of the modulus in KeyMeta; HashType is the hash the key signs with, which is
+stored along with the key.
 */
 type ThresholdKey struct {
-	KeyShare     *tcrsa.KeyShare
-	KeyMeta      *tcrsa.KeyMeta
-	HashType     crypto.Hash
-	DummyPrivKey *rsa.PrivateKey
+	KeyShare *tcrsa.KeyShare
+	KeyMeta  *tcrsa.KeyMeta
+	HashType crypto.Hash
+
+	// Signer computes the signature shares if the key share is kept in a key store; KeyShare.Si is empty then.
+	Signer ShareSigner
+
+	// DeltaShares is set for keys that have been split from an existing key by SplitKey rather than generated by
+	// tcrsa; their signature shares are joined differently.
+	DeltaShares bool
 }
 
-func NewThresholdKey(keyShare *tcrsa.KeyShare, keyMeta *tcrsa.KeyMeta, keySize int) (*ThresholdKey, error) {
+// ShareSigner computes signature shares with a key share that isn't held by the ThresholdKey itself.
+type ShareSigner interface {
+	// SignShare signs a document hash that has been padded with tcrsa.PrepareDocumentHash.
+	SignShare(paddedHash []byte, hashType crypto.Hash, meta *tcrsa.KeyMeta) (*tcrsa.SigShare, error)
+}
 
-	dummyPrivKey, err := rsa.GenerateKey(rand.Reader, keySize)
+// NewThresholdKey returns the key of a share that signs with hashType. The hash has to be one of SHA-256, SHA-384
+// and SHA-512 and its PKCS #1 v1.5 encoding has to fit into the key.
+func NewThresholdKey(keyShare *tcrsa.KeyShare, keyMeta *tcrsa.KeyMeta, hashType crypto.Hash) (*ThresholdKey, error) {
+	if _, err := signatureAlgorithm(hashType); err != nil {
+		return nil, err
+	}
+	if _, err := tcrsa.PrepareDocumentHash(keyMeta.PublicKey.Size(), hashType, make([]byte, hashType.Size())); err != nil {
+		return nil, fmt.Errorf("a key of %v bits can't sign with %v", keyMeta.PublicKey.N.BitLen(), hashType)
+	}
 
 	return &ThresholdKey{
-		KeyShare:     keyShare,
-		KeyMeta:      keyMeta,
-		HashType:     crypto.SHA256,
-		DummyPrivKey: dummyPrivKey,
-	}, err
+		KeyShare: keyShare,
+		KeyMeta:  keyMeta,
+		HashType: hashType,
+	}, nil
 }
 
 func (key *ThresholdKey) Public() *rsa.PublicKey {
 	return key.KeyMeta.PublicKey
 }
 
-func (key *ThresholdKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) (signature []byte, err error) {
-	return rsa.SignPKCS1v15(rand, key.DummyPrivKey, key.HashType, digest)
+// Size returns the size of the key in bits.
+func (key *ThresholdKey) Size() int {
+	return key.KeyMeta.PublicKey.N.BitLen()
 }
@@ -0,0 +1,270 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/niclabs/tcrsa"
+)
+
+/*
+Key ceremony transcript:
+
+The transcript of a key ceremony records the parameters of the key, its public and verification keys, the
+fingerprint of the root certificate, the hashes of the share files that were written and every step of the ceremony.
+It is signed with the threshold key itself, so it can be checked with the public key of the root certificate and
+proves that the shares that were handed out combine into a valid signature. Node operators check their share
+against it with VerifyShare.
+*/
+
+// Transcript is the record of a key ceremony.
+type Transcript struct {
+	Started   time.Time
+	Finished  time.Time
+	Nodes     uint16
+	Threshold uint16
+	KeySize   int
+	HashType  string
+	// Imported is set if the key of an existing CA has been split rather than a new key generated.
+	Imported        bool
+	PublicKey       []byte // PKIX, ASN.1 DER
+	VerificationKey *tcrsa.VerificationKey
+	RootCertificate string // hex encoded SHA-256 fingerprint
+	Shares          []TranscriptShare
+	Steps           []TranscriptStep
+}
+
+// TranscriptShare is a share file that has been written during the ceremony.
+type TranscriptShare struct {
+	ID     uint16
+	File   string
+	SHA256 string
+}
+
+// TranscriptStep is a step of the ceremony.
+type TranscriptStep struct {
+	Time time.Time
+	Step string
+}
+
+// signedTranscript is the format of transcript files. The signature is on the compact JSON encoding of the
+// transcript, so that the file can be indented.
+type signedTranscript struct {
+	Transcript json.RawMessage
+	Signature  []byte
+}
+
+// NewTranscript starts the transcript of the ceremony that generated keys and the root certificate.
+func NewTranscript(keys []*ThresholdKey, root *x509.Certificate, imported bool) (*Transcript, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("there are no keys")
+	}
+	meta := keys[0].KeyMeta
+	pub, err := x509.MarshalPKIXPublicKey(meta.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := sha256.Sum256(root.Raw)
+	return &Transcript{
+		Started:         time.Now().UTC(),
+		Nodes:           meta.L,
+		Threshold:       meta.K,
+		KeySize:         meta.PublicKey.N.BitLen(),
+		HashType:        keys[0].HashType.String(),
+		Imported:        imported,
+		PublicKey:       pub,
+		VerificationKey: meta.VerificationKey,
+		RootCertificate: hex.EncodeToString(fingerprint[:]),
+	}, nil
+}
+
+// Log records a step of the ceremony.
+func (t *Transcript) Log(format string, args ...interface{}) {
+	t.Steps = append(t.Steps, TranscriptStep{Time: time.Now().UTC(), Step: fmt.Sprintf(format, args...)})
+}
+
+// AddShare records the hash of the share file of node id. Only the name of the file is kept, since the media
+// the shares are written to are mounted in different places.
+func (t *Transcript) AddShare(id uint16, file string) error {
+	hash, err := fileHash(file)
+	if err != nil {
+		return err
+	}
+	t.Shares = append(t.Shares, TranscriptShare{ID: id, File: filepath.Base(file), SHA256: hash})
+	return nil
+}
+
+// WriteTranscriptFile finishes the transcript, signs it with a threshold of keys and writes it to filePath.
+// It returns the SHA-256 hash of the file, which is to be published along with it.
+func WriteTranscriptFile(t *Transcript, keys []*ThresholdKey, filePath string) (string, error) {
+	t.Finished = time.Now().UTC()
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+
+	key := keys[0]
+	h := key.HashType.New()
+	h.Write(raw)
+	digest := h.Sum(nil)
+	var shares []*tcrsa.SigShare
+	for _, k := range keys[:key.KeyMeta.K] {
+		share, err := SignDigestShare(digest, key.HashType, k)
+		if err != nil {
+			return "", err
+		}
+		shares = append(shares, share)
+	}
+	signature, err := JoinDigestShares(digest, key.HashType, key, shares...)
+	if err != nil {
+		return "", fmt.Errorf("cannot sign the transcript: %w", err)
+	}
+
+	out, err := json.MarshalIndent(signedTranscript{Transcript: raw, Signature: signature}, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filePath, append(out, '\n'), 0644); err != nil {
+		return "", err
+	}
+	return fileHash(filePath)
+}
+
+// ReadTranscriptFile reads a transcript and checks that it is signed by the key of root and refers to it.
+func ReadTranscriptFile(filePath string, root *x509.Certificate) (*Transcript, error) {
+	raw, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var signed signedTranscript
+	if err := json.Unmarshal(raw, &signed); err != nil {
+		return nil, fmt.Errorf("cannot parse transcript %v: %w", filePath, err)
+	}
+	t := new(Transcript)
+	if err := json.Unmarshal(signed.Transcript, t); err != nil {
+		return nil, fmt.Errorf("cannot parse transcript %v: %w", filePath, err)
+	}
+
+	pub, ok := root.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("root certificate doesn't have an RSA key")
+	}
+	hashType, err := hashByName(t.HashType)
+	if err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, signed.Transcript); err != nil {
+		return nil, err
+	}
+	h := hashType.New()
+	h.Write(compact.Bytes())
+	if err := rsa.VerifyPKCS1v15(pub, hashType, h.Sum(nil), signed.Signature); err != nil {
+		return nil, fmt.Errorf("transcript %v isn't signed by the key of the root certificate: %w", filePath, err)
+	}
+
+	fingerprint := sha256.Sum256(root.Raw)
+	if t.RootCertificate != hex.EncodeToString(fingerprint[:]) {
+		return nil, fmt.Errorf("transcript %v is for another root certificate", filePath)
+	}
+	return t, nil
+}
+
+// VerifyShare checks that key has been handed out in the ceremony: that file, which key has been read from, is
+// the file that has been written for the node, that key belongs to the published public and verification keys and
+// that its share matches its verification key.
+func (t *Transcript) VerifyShare(key *ThresholdKey, file string) error {
+	id := key.KeyShare.Id
+	var record *TranscriptShare
+	for i := range t.Shares {
+		if t.Shares[i].ID == id {
+			record = &t.Shares[i]
+		}
+	}
+	if record == nil {
+		return fmt.Errorf("transcript has no share of node %v", id)
+	}
+	hash, err := fileHash(file)
+	if err != nil {
+		return err
+	}
+	if hash != record.SHA256 {
+		return fmt.Errorf("%v isn't the file that has been written for node %v", file, id)
+	}
+
+	meta := key.KeyMeta
+	pub, err := x509.MarshalPKIXPublicKey(meta.PublicKey)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !bytes.Equal(pub, t.PublicKey):
+		return fmt.Errorf("share of node %v belongs to another public key", id)
+	case meta.K != t.Threshold || meta.L != t.Nodes:
+		return fmt.Errorf("share of node %v is one of %v with a threshold of %v, not of %v with a threshold of %v",
+			id, meta.L, meta.K, t.Nodes, t.Threshold)
+	case !equalVerificationKeys(meta.VerificationKey, t.VerificationKey):
+		return fmt.Errorf("share of node %v comes with other verification keys", id)
+	}
+	return VerifyKeyShare(key)
+}
+
+// VerifyKeyShare checks that the share of key matches its verification key, v^si = vi.
+func VerifyKeyShare(key *ThresholdKey) error {
+	share, vk := key.KeyShare, key.KeyMeta.VerificationKey
+	if len(share.Si) == 0 {
+		return fmt.Errorf("share of node %v isn't held in memory", share.Id)
+	}
+	if share.Id == 0 || int(share.Id) > len(vk.I) {
+		return fmt.Errorf("there is no verification key for node %v", share.Id)
+	}
+	n := key.KeyMeta.PublicKey.N
+	v := new(big.Int).SetBytes(vk.V)
+	vi := new(big.Int).Exp(v, new(big.Int).SetBytes(share.Si), n)
+	if vi.Cmp(new(big.Int).SetBytes(vk.I[share.Id-1])) != 0 {
+		return fmt.Errorf("share of node %v doesn't match its verification key", share.Id)
+	}
+	return nil
+}
+
+func equalVerificationKeys(a, b *tcrsa.VerificationKey) bool {
+	if a == nil || b == nil || !bytes.Equal(a.V, b.V) || !bytes.Equal(a.U, b.U) || len(a.I) != len(b.I) {
+		return false
+	}
+	for i := range a.I {
+		if !bytes.Equal(a.I[i], b.I[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// hashByName returns the hash function whose String is name.
+func hashByName(name string) (crypto.Hash, error) {
+	for _, h := range []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512} {
+		if h.String() == name {
+			return h, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported hash function %q", name)
+}
+
+// fileHash returns the hex encoded SHA-256 hash of a file.
+func fileHash(file string) (string, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(raw)
+	return hex.EncodeToString(hash[:]), nil
+}
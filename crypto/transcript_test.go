@@ -0,0 +1,88 @@
+package crypto_test
+
+import (
+	"bytes"
+	stdcrypto "crypto"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+func TestTranscript(t *testing.T) {
+	keys, root, err := crypto.GenerateCA(threshold, num, keySize, stdcrypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transcript, err := crypto.NewTranscript(keys, root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := make([]string, num)
+	for i, key := range keys {
+		files[i] = filepath.Join(dir, fmt.Sprintf("n%v.thresholdkey", i+1))
+		if err := crypto.WriteThresholdKeyFile(key, files[i]); err != nil {
+			t.Fatal(err)
+		}
+		if err := transcript.AddShare(key.KeyShare.Id, files[i]); err != nil {
+			t.Fatal(err)
+		}
+		transcript.Log("wrote the share of node %v", key.KeyShare.Id)
+	}
+	path := filepath.Join(dir, "transcript.json")
+	if _, err := crypto.WriteTranscriptFile(transcript, keys, path); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := crypto.ReadTranscriptFile(path, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range keys {
+		key, err := crypto.ReadThresholdKeyFile(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := read.VerifyShare(key, files[i]); err != nil {
+			t.Errorf("share of node %v: %v", i+1, err)
+		}
+	}
+
+	// the share of one node doesn't pass as that of another
+	key, err := crypto.ReadThresholdKeyFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.KeyShare.Id = 2
+	if err := read.VerifyShare(key, files[1]); err == nil {
+		t.Error("share of node 1 passes as the share of node 2")
+	}
+
+	// a share that doesn't match its verification key is refused even if the file is the right one
+	key.KeyShare = keys[1].KeyShare
+	key.KeyShare.Si = append([]byte(nil), key.KeyShare.Si...)
+	key.KeyShare.Si[0] ^= 1
+	if err := crypto.VerifyKeyShare(key); err == nil {
+		t.Error("share that doesn't match its verification key passes")
+	}
+
+	// a transcript that has been changed isn't accepted
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Replace(raw, []byte(`"Threshold": 3`), []byte(`"Threshold": 2`), 1)
+	if bytes.Equal(raw, tampered) {
+		t.Fatal("transcript doesn't hold the threshold")
+	}
+	if err := os.WriteFile(path, tampered, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crypto.ReadTranscriptFile(path, root); err == nil {
+		t.Error("tampered transcript is accepted")
+	}
+}
@@ -0,0 +1,124 @@
+package ctlog
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AddPreChainPath is the path of the endpoint of CT logs precertificates are submitted to (RFC 6962, section 4.1).
+const AddPreChainPath = "/ct/v1/add-pre-chain"
+
+// AddChainRequest is the body of a submission to a CT log.
+type AddChainRequest struct {
+	Chain [][]byte `json:"chain"` // the (pre)certificate followed by its issuers; DER in base64
+}
+
+// AddChainResponse is the SCT returned by a CT log.
+type AddChainResponse struct {
+	SCTVersion uint8  `json:"sct_version"`
+	ID         []byte `json:"id"`
+	Timestamp  uint64 `json:"timestamp"`
+	Extensions []byte `json:"extensions"`
+	Signature  []byte `json:"signature"` // TLS encoded DigitallySigned struct
+}
+
+// Client submits precertificates to a CT log.
+type Client struct {
+	URL       string           // base URL of the log, e.g. https://ct.example.com/2021
+	PublicKey crypto.PublicKey // SCTs aren't verified if nil
+	HTTP      *http.Client
+}
+
+// NewClient returns a client of the CT log at url. The public key of the log is read from the PEM file at
+// publicKeyFile unless it is empty.
+func NewClient(url, publicKeyFile string) (*Client, error) {
+	c := &Client{URL: strings.TrimSuffix(url, "/"), HTTP: http.DefaultClient}
+	if publicKeyFile == "" {
+		return c, nil
+	}
+
+	b, err := os.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in %v", publicKeyFile)
+	}
+	c.PublicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("public key of CT log %v: %w", url, err)
+	}
+	return c, nil
+}
+
+// AddPreChain submits a precertificate issued by issuer and returns the SCT of the log. If the public key of the
+// log is known the SCT is verified.
+func (c *Client) AddPreChain(ctx context.Context, precert, issuer *x509.Certificate) (*SCT, error) {
+	body, err := json.Marshal(AddChainRequest{Chain: [][]byte{precert.Raw, issuer.Raw}})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+AddPreChainPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("CT log %v refused precertificate: %v %s", c.URL, resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var answer AddChainResponse
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return nil, fmt.Errorf("malformed answer of CT log %v: %w", c.URL, err)
+	}
+	sct, err := answer.SCT()
+	if err != nil {
+		return nil, fmt.Errorf("malformed SCT of CT log %v: %w", c.URL, err)
+	}
+
+	if c.PublicKey != nil {
+		if err := VerifySCT(sct, c.PublicKey, precert, issuer); err != nil {
+			return nil, fmt.Errorf("SCT of CT log %v: %w", c.URL, err)
+		}
+	}
+	return sct, nil
+}
+
+// SCT converts the answer of the log.
+func (r *AddChainResponse) SCT() (*SCT, error) {
+	sct := &SCT{Version: r.SCTVersion, Timestamp: r.Timestamp, Extensions: r.Extensions}
+	if len(r.ID) != len(sct.LogID) {
+		return nil, fmt.Errorf("log ID has %v bytes instead of %v", len(r.ID), len(sct.LogID))
+	}
+	copy(sct.LogID[:], r.ID)
+
+	sig := reader(r.Signature)
+	algs, err := sig.next(2)
+	if err != nil {
+		return nil, err
+	}
+	sct.HashAlgorithm, sct.SignatureAlgorithm = algs[0], algs[1]
+	if sct.Signature, err = sig.prefixed16(); err != nil {
+		return nil, err
+	}
+	return sct, nil
+}
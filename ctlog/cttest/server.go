@@ -0,0 +1,149 @@
+// Package cttest provides a local stand-in for a Certificate Transparency log that precertificates can be submitted
+// to in tests and local setups. It issues an SCT for every valid precertificate but doesn't log anything.
+package cttest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	hccrypto "github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/ctlog"
+)
+
+// Log is a CT log stand-in.
+type Log struct {
+	Key *ecdsa.PrivateKey // SCTs are signed with it
+
+	mut       sync.Mutex
+	submitted []*x509.Certificate
+	refuse    bool
+}
+
+// NewLog returns a CT log stand-in with a new key.
+func NewLog() (*Log, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &Log{Key: key}, nil
+}
+
+// NewServer starts a CT log stand-in with a new key. The caller has to close the server.
+func NewServer() (*httptest.Server, *Log, error) {
+	l, err := NewLog()
+	if err != nil {
+		return nil, nil, err
+	}
+	return httptest.NewServer(l), l, nil
+}
+
+// Refuse makes the log refuse all submissions, as if it were down.
+func (l *Log) Refuse(refuse bool) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	l.refuse = refuse
+}
+
+// Submitted returns the precertificates the log issued SCTs for.
+func (l *Log) Submitted() []*x509.Certificate {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return append([]*x509.Certificate(nil), l.submitted...)
+}
+
+// ServeHTTP implements the add-pre-chain endpoint of RFC 6962.
+func (l *Log) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != ctlog.AddPreChainPath || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	l.mut.Lock()
+	refuse := l.refuse
+	l.mut.Unlock()
+	if refuse {
+		http.Error(w, "log is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req ctlog.AddChainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Chain) < 2 {
+		http.Error(w, "malformed chain", http.StatusBadRequest)
+		return
+	}
+	precert, err := x509.ParseCertificate(req.Chain[0])
+	if err != nil {
+		http.Error(w, "malformed precertificate", http.StatusBadRequest)
+		return
+	}
+	issuer, err := x509.ParseCertificate(req.Chain[1])
+	if err != nil {
+		http.Error(w, "malformed issuer", http.StatusBadRequest)
+		return
+	}
+	if !poisoned(precert) {
+		http.Error(w, "precertificate lacks the poison extension", http.StatusBadRequest)
+		return
+	}
+	if err := precert.CheckSignatureFrom(issuer); err != nil {
+		http.Error(w, "precertificate hasn't been signed by issuer", http.StatusBadRequest)
+		return
+	}
+
+	resp, err := l.issue(precert, issuer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	l.mut.Lock()
+	l.submitted = append(l.submitted, precert)
+	l.mut.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (l *Log) issue(precert, issuer *x509.Certificate) (*ctlog.AddChainResponse, error) {
+	id, err := ctlog.LogID(l.Key.Public())
+	if err != nil {
+		return nil, err
+	}
+	tbs, err := ctlog.PrecertTBS(precert)
+	if err != nil {
+		return nil, err
+	}
+
+	sct := &ctlog.SCT{LogID: id, Timestamp: ctlog.Timestamp(time.Now())}
+	digest := sha256.Sum256(ctlog.SignedData(sct, sha256.Sum256(issuer.RawSubjectPublicKeyInfo), tbs))
+	signature, err := ecdsa.SignASN1(rand.Reader, l.Key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// DigitallySigned with SHA-256 and ECDSA
+	signed := append([]byte{4, 3, byte(len(signature) >> 8), byte(len(signature))}, signature...)
+	return &ctlog.AddChainResponse{
+		SCTVersion: sct.Version,
+		ID:         id[:],
+		Timestamp:  sct.Timestamp,
+		Signature:  signed,
+	}, nil
+}
+
+func poisoned(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(hccrypto.OIDPrecertificatePoison) && ext.Critical {
+			return true
+		}
+	}
+	return false
+}
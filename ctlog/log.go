@@ -0,0 +1,445 @@
+// Package ctlog keeps a Certificate Transparency style log of all certificates the CA issued.
+//
+// The log is an append-only Merkle tree as defined in RFC 6962 and RFC 9162. Each replica keeps its own copy of the
+// tree; they stay identical because one replica, the sequencer, decides in which order certificates are added and
+// hands the new entries to all replicas in an extension. Every replica checks the extension, appends it and returns a
+// signature share on the new tree head, so a signed tree head (STH) proves that a quorum of replicas agreed on the
+// tree. Clients verify with an inclusion proof and the public key of the CA that their certificate has been logged.
+package ctlog
+
+import (
+	"bufio"
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxExtension is the max number of certificates added to the log by one extension.
+const MaxExtension = 256
+
+var (
+	// ErrNotFound is returned if a leaf or tree head isn't part of the signed log.
+	ErrNotFound = errors.New("not found in log")
+	// ErrBehind is returned if an extension starts after the end of this replica's log.
+	ErrBehind = errors.New("log is behind")
+	// ErrConflict is returned if an extension contradicts the entries this replica has already logged.
+	ErrConflict = errors.New("extension conflicts with log")
+)
+
+// Entry is a certificate in the log.
+type Entry struct {
+	Timestamp   uint64 // when the certificate has been added in milliseconds since the epoch
+	Certificate []byte // DER
+}
+
+// Extension appends certificates to the log once TreeSize entries have been logged.
+type Extension struct {
+	TreeSize     uint64
+	Timestamp    uint64 // of all entries added by the extension
+	Certificates [][]byte
+}
+
+// record is a line of the file the log is stored in; either an entry or a signed tree head.
+type record struct {
+	Entry *Entry          `json:"entry,omitempty"`
+	STH   *SignedTreeHead `json:"sth,omitempty"`
+}
+
+// Log is the replica's copy of the certificate log. A nil *Log serves no proofs and ignores issued certificates.
+type Log struct {
+	mut      sync.Mutex
+	pub      *rsa.PublicKey // tree heads are only accepted if signed with it
+	file     *os.File       // nil if the log is only kept in memory
+	entries  []Entry
+	leaves   [][]byte          // hashes of the entries
+	index    map[string]uint64 // position of the entries by the SHA-256 hash of the certificate
+	sths     []*SignedTreeHead // in the order of their tree size
+	pending  [][]byte          // certificates that have been issued but haven't been logged yet
+	waiting  map[string]bool   // hashes of the pending certificates
+	inflight *Extension        // extension of the sequencer that hasn't been signed yet
+	signed   chan struct{}     // closed and replaced every time a tree head has been signed
+}
+
+// Open opens the log stored at path, creating it if it doesn't exist. If path is empty the log is only kept in
+// memory. Only tree heads that have been signed with pub are accepted.
+func Open(path string, pub *rsa.PublicKey) (*Log, error) {
+	l := &Log{
+		pub:     pub,
+		index:   make(map[string]uint64),
+		waiting: make(map[string]bool),
+		signed:  make(chan struct{}),
+	}
+	if path == "" {
+		return l, nil
+	}
+
+	if f, err := os.Open(path); err == nil {
+		err = l.replay(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("certificate log %v: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l.file = f
+	return l, nil
+}
+
+// replay restores the log from the records written before.
+func (l *Log) replay(f *os.File) error {
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return fmt.Errorf("line %v: %w", line, err)
+		}
+		switch {
+		case rec.Entry != nil:
+			l.addEntry(*rec.Entry)
+		case rec.STH != nil:
+			if err := l.checkSTH(rec.STH); err != nil {
+				return fmt.Errorf("line %v: %w", line, err)
+			}
+			l.sths = append(l.sths, rec.STH)
+		}
+	}
+	return scanner.Err()
+}
+
+// Close closes the file of the log.
+func (l *Log) Close() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+func certKey(cert []byte) string {
+	h := sha256.Sum256(cert)
+	return string(h[:])
+}
+
+// Submit queues an issued certificate to be added to the log. It does nothing if the certificate has already been
+// logged or the log is nil.
+func (l *Log) Submit(cert *x509.Certificate) {
+	if l == nil {
+		return
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	key := certKey(cert.Raw)
+	if _, ok := l.index[key]; ok || l.waiting[key] {
+		return
+	}
+	l.waiting[key] = true
+	l.pending = append(l.pending, cert.Raw)
+}
+
+// NextExtension returns the extension the sequencer should get signed next, or nil if there is nothing to add.
+// The same extension is returned until a tree head covering it has been signed so that failed attempts can be
+// repeated; the replicas that already appended it just sign again.
+func (l *Log) NextExtension(now time.Time) *Extension {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.inflight != nil {
+		return l.inflight
+	}
+	// the empty tree gets signed as well so that there is a tree head from the beginning; entries that have been
+	// appended but not signed before a restart are covered by an extension without certificates
+	if len(l.pending) == 0 && len(l.sths) > 0 && l.signedSize() == uint64(len(l.entries)) {
+		return nil
+	}
+
+	n := len(l.pending)
+	if n > MaxExtension {
+		n = MaxExtension
+	}
+
+	timestamp := Timestamp(now)
+	if len(l.entries) > 0 && timestamp < l.entries[len(l.entries)-1].Timestamp {
+		timestamp = l.entries[len(l.entries)-1].Timestamp
+	}
+
+	l.inflight = &Extension{
+		TreeSize:     uint64(len(l.entries)),
+		Timestamp:    timestamp,
+		Certificates: append([][]byte(nil), l.pending[:n]...),
+	}
+	return l.inflight
+}
+
+// Extend appends the certificates of ext to the log and returns the tree head to be signed for the extended log.
+// Extensions that have already been appended are accepted again as long as they match the log.
+// The caller has to check that the certificates have been issued by the CA.
+func (l *Log) Extend(ext *Extension) (*SignedTreeHead, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	size := uint64(len(l.entries))
+	if ext.TreeSize > size {
+		return nil, fmt.Errorf("%w: extension starts after %v entries but the log has %v", ErrBehind, ext.TreeSize, size)
+	}
+	if ext.TreeSize == size && size > 0 && ext.Timestamp < l.entries[size-1].Timestamp {
+		return nil, fmt.Errorf("%w: timestamp goes back in time", ErrConflict)
+	}
+
+	for i, cert := range ext.Certificates {
+		entry := Entry{Timestamp: ext.Timestamp, Certificate: cert}
+		pos := ext.TreeSize + uint64(i)
+
+		if pos < uint64(len(l.entries)) {
+			if string(HashLeaf(LeafInput(entry.Timestamp, cert))) != string(l.leaves[pos]) {
+				return nil, fmt.Errorf("%w: entry %v differs", ErrConflict, pos)
+			}
+			continue
+		}
+		if _, ok := l.index[certKey(cert)]; ok {
+			return nil, fmt.Errorf("%w: certificate has already been logged", ErrConflict)
+		}
+
+		// the entry is only part of the log once it has been stored
+		if err := l.write(record{Entry: &entry}); err != nil {
+			return nil, err
+		}
+		l.addEntry(entry)
+	}
+
+	end := ext.TreeSize + uint64(len(ext.Certificates))
+	return &SignedTreeHead{
+		TreeSize:  end,
+		Timestamp: ext.Timestamp,
+		RootHash:  RootHash(l.leaves[:end]),
+	}, nil
+}
+
+// addEntry appends an entry to the tree. The caller must hold l.mut.
+func (l *Log) addEntry(entry Entry) {
+	key := certKey(entry.Certificate)
+	l.index[key] = uint64(len(l.entries))
+	l.entries = append(l.entries, entry)
+	l.leaves = append(l.leaves, HashLeaf(LeafInput(entry.Timestamp, entry.Certificate)))
+
+	if l.waiting[key] {
+		delete(l.waiting, key)
+		for i, cert := range l.pending {
+			if certKey(cert) == key {
+				l.pending = append(l.pending[:i], l.pending[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// AddSTH records a signed tree head of the log. Tree heads that are older than the latest one are ignored.
+func (l *Log) AddSTH(sth *SignedTreeHead) error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if err := l.checkSTH(sth); err != nil {
+		return err
+	}
+	if latest := l.latest(); latest != nil && (sth.TreeSize < latest.TreeSize || sth.Timestamp <= latest.Timestamp) {
+		return nil
+	}
+
+	if err := l.write(record{STH: sth}); err != nil {
+		return err
+	}
+	l.sths = append(l.sths, sth)
+
+	if l.inflight != nil && l.inflight.TreeSize+uint64(len(l.inflight.Certificates)) <= sth.TreeSize {
+		l.inflight = nil
+	}
+	close(l.signed)
+	l.signed = make(chan struct{})
+	return nil
+}
+
+// checkSTH checks that the tree head has been signed by the CA and matches the log. The caller must hold l.mut.
+func (l *Log) checkSTH(sth *SignedTreeHead) error {
+	if err := sth.Verify(l.pub); err != nil {
+		return err
+	}
+	if sth.TreeSize > uint64(len(l.leaves)) {
+		return fmt.Errorf("%w: tree head covers %v entries but the log has %v", ErrBehind, sth.TreeSize, len(l.leaves))
+	}
+	if string(RootHash(l.leaves[:sth.TreeSize])) != string(sth.RootHash) {
+		return fmt.Errorf("%w: root hash of tree head of size %v differs", ErrConflict, sth.TreeSize)
+	}
+	return nil
+}
+
+func (l *Log) latest() *SignedTreeHead {
+	if len(l.sths) == 0 {
+		return nil
+	}
+	return l.sths[len(l.sths)-1]
+}
+
+// STH returns the latest signed tree head, or nil if none has been signed yet.
+func (l *Log) STH() *SignedTreeHead {
+	if l == nil {
+		return nil
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.latest()
+}
+
+// STHOfSize returns the signed tree head of the given size.
+func (l *Log) STHOfSize(size uint64) (*SignedTreeHead, bool) {
+	if l == nil {
+		return nil, false
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	for i := len(l.sths) - 1; i >= 0; i-- {
+		if l.sths[i].TreeSize == size {
+			return l.sths[i], true
+		}
+	}
+	return nil, false
+}
+
+// signedSize returns the number of entries covered by the latest signed tree head. The caller must hold l.mut.
+func (l *Log) signedSize() uint64 {
+	if latest := l.latest(); latest != nil {
+		return latest.TreeSize
+	}
+	return 0
+}
+
+// ProofByHash returns the index of the leaf with the given hash and its audit path in the tree of the given size,
+// which mustn't be larger than the latest signed tree head.
+func (l *Log) ProofByHash(leafHash []byte, treeSize uint64) (uint64, [][]byte, error) {
+	if l == nil {
+		return 0, nil, fmt.Errorf("%w: the certificate log is disabled", ErrNotFound)
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if treeSize > l.signedSize() {
+		return 0, nil, fmt.Errorf("%w: no signed tree head of size %v", ErrNotFound, treeSize)
+	}
+	for i, leaf := range l.leaves[:treeSize] {
+		if string(leaf) == string(leafHash) {
+			path, err := InclusionProof(uint64(i), l.leaves[:treeSize])
+			return uint64(i), path, err
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: no leaf with hash %x in tree of size %v", ErrNotFound, leafHash, treeSize)
+}
+
+// Consistency returns the proof that the tree of size first is a prefix of the tree of size second, which mustn't be
+// larger than the latest signed tree head.
+func (l *Log) Consistency(first, second uint64) ([][]byte, error) {
+	if l == nil {
+		return nil, fmt.Errorf("%w: the certificate log is disabled", ErrNotFound)
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if second > l.signedSize() {
+		return nil, fmt.Errorf("%w: no signed tree head of size %v", ErrNotFound, second)
+	}
+	return ConsistencyProof(first, l.leaves[:second])
+}
+
+// Proof returns the proof that the certificate in DER is part of the tree of the latest signed tree head.
+func (l *Log) Proof(cert []byte) (*Proof, bool) {
+	if l == nil {
+		return nil, false
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+	return l.proof(cert)
+}
+
+func (l *Log) proof(cert []byte) (*Proof, bool) {
+	index, ok := l.index[certKey(cert)]
+	sth := l.latest()
+	if !ok || sth == nil || index >= sth.TreeSize {
+		return nil, false
+	}
+
+	path, err := InclusionProof(index, l.leaves[:sth.TreeSize])
+	if err != nil {
+		return nil, false
+	}
+	return &Proof{
+		LeafIndex: index,
+		Timestamp: l.entries[index].Timestamp,
+		AuditPath: path,
+		STH:       sth,
+	}, true
+}
+
+// WaitProof waits until the certificate in DER is covered by a signed tree head and returns the proof of it.
+func (l *Log) WaitProof(ctx context.Context, cert []byte) (*Proof, error) {
+	if l == nil {
+		return nil, fmt.Errorf("the certificate log is disabled")
+	}
+
+	for {
+		l.mut.Lock()
+		proof, ok := l.proof(cert)
+		signed := l.signed
+		l.mut.Unlock()
+
+		if ok {
+			return proof, nil
+		}
+
+		select {
+		case <-signed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// write stores a record in the file of the log. The caller must hold l.mut.
+func (l *Log) write(rec record) error {
+	if l.file == nil {
+		return nil
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
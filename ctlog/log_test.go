@@ -0,0 +1,256 @@
+package ctlog_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/niclabs/tcrsa"
+
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/ctlog"
+)
+
+var (
+	keysOnce sync.Once
+	keys     []*crypto.ThresholdKey
+)
+
+func thresholdKeys(t *testing.T) []*crypto.ThresholdKey {
+	t.Helper()
+	keysOnce.Do(func() {
+		var err error
+		keys, err = crypto.ComputeTresholdKeys(3, 4, 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	return keys
+}
+
+// sign threshold signs a tree head like the replicas do.
+func sign(t *testing.T, sth *ctlog.SignedTreeHead) *ctlog.SignedTreeHead {
+	t.Helper()
+	keys := thresholdKeys(t)
+
+	var shares tcrsa.SigShareList
+	for _, key := range keys[:3] {
+		share, err := crypto.SignDigestShare(sth.Digest(), ctlog.DigestHash, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, share)
+	}
+	signature, err := crypto.JoinDigestShares(sth.Digest(), ctlog.DigestHash, keys[0], shares...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed := *sth
+	signed.Signature = signature
+	return &signed
+}
+
+func cert(i int) *x509.Certificate {
+	// the log only looks at the DER of certificates
+	return &x509.Certificate{Raw: []byte(fmt.Sprintf("certificate %v", i))}
+}
+
+func openLog(t *testing.T, path string) *ctlog.Log {
+	t.Helper()
+	l, err := ctlog.Open(path, thresholdKeys(t)[0].Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// extend appends the next extension of the sequencer to all logs and adds the signed tree head.
+func extend(t *testing.T, sequencer *ctlog.Log, replicas ...*ctlog.Log) *ctlog.SignedTreeHead {
+	t.Helper()
+
+	ext := sequencer.NextExtension(time.Now())
+	if ext == nil {
+		t.Fatal("no extension although certificates are pending")
+	}
+
+	var sth *ctlog.SignedTreeHead
+	for _, l := range append([]*ctlog.Log{sequencer}, replicas...) {
+		head, err := l.Extend(ext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sth != nil && string(head.RootHash) != string(sth.RootHash) {
+			t.Fatal("replicas computed different roots for the same extension")
+		}
+		sth = head
+	}
+
+	sth = sign(t, sth)
+	for _, l := range append([]*ctlog.Log{sequencer}, replicas...) {
+		if err := l.AddSTH(sth); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sth
+}
+
+func TestExtend(t *testing.T) {
+	sequencer, replica := openLog(t, ""), openLog(t, "")
+
+	// the empty tree is signed first
+	empty := extend(t, sequencer, replica)
+	if empty.TreeSize != 0 || string(empty.RootHash) != string(ctlog.EmptyRoot()) {
+		t.Errorf("got tree head of size %v; want the empty tree", empty.TreeSize)
+	}
+	if sequencer.NextExtension(time.Now()) != nil {
+		t.Error("extension although no certificate is pending")
+	}
+
+	for i := 0; i < 5; i++ {
+		sequencer.Submit(cert(i))
+		// certificates are only logged once
+		sequencer.Submit(cert(i))
+	}
+	first := sequencer.NextExtension(time.Now())
+	if again := sequencer.NextExtension(time.Now()); again != first {
+		t.Error("extension changed before it has been signed")
+	}
+	sth := extend(t, sequencer, replica)
+	if sth.TreeSize != 5 {
+		t.Errorf("got tree head of size %v; want 5", sth.TreeSize)
+	}
+
+	pub := thresholdKeys(t)[0].Public()
+	for i := 0; i < 5; i++ {
+		proof, ok := replica.Proof(cert(i).Raw)
+		if !ok {
+			t.Fatalf("no proof for certificate %v", i)
+		}
+		if err := ctlog.VerifyCertificate(pub, cert(i).Raw, proof); err != nil {
+			t.Errorf("proof for certificate %v: %v", i, err)
+		}
+		if err := ctlog.VerifyCertificate(pub, cert(i+1).Raw, proof); err == nil {
+			t.Errorf("proof for certificate %v accepted for another certificate", i)
+		}
+	}
+
+	// a replica that missed the first certificates can't take part
+	sequencer.Submit(cert(5))
+	late := openLog(t, "")
+	if _, err := late.Extend(sequencer.NextExtension(time.Now())); !errors.Is(err, ctlog.ErrBehind) {
+		t.Errorf("got error %v; want %v", err, ctlog.ErrBehind)
+	}
+}
+
+func TestExtendConflict(t *testing.T) {
+	a, b := openLog(t, ""), openLog(t, "")
+	a.Submit(cert(1))
+	b.Submit(cert(2))
+
+	if _, err := a.Extend(a.NextExtension(time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	// the replica has already logged another certificate at this position
+	if _, err := a.Extend(b.NextExtension(time.Now())); !errors.Is(err, ctlog.ErrConflict) {
+		t.Errorf("got error %v; want %v", err, ctlog.ErrConflict)
+	}
+}
+
+func TestAddSTH(t *testing.T) {
+	l := openLog(t, "")
+	l.Submit(cert(1))
+	sth, err := l.Extend(l.NextExtension(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.AddSTH(sth); err == nil {
+		t.Error("unsigned tree head accepted")
+	}
+
+	wrongRoot := *sth
+	wrongRoot.RootHash = ctlog.EmptyRoot()
+	if err := l.AddSTH(sign(t, &wrongRoot)); !errors.Is(err, ctlog.ErrConflict) {
+		t.Errorf("got error %v for tree head with another root; want %v", err, ctlog.ErrConflict)
+	}
+
+	if err := l.AddSTH(sign(t, sth)); err != nil {
+		t.Fatal(err)
+	}
+	if got := l.STH(); got.TreeSize != 1 {
+		t.Errorf("got tree head of size %v; want 1", got.TreeSize)
+	}
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ct.log")
+	l := openLog(t, path)
+	extend(t, l)
+	for i := 0; i < 3; i++ {
+		l.Submit(cert(i))
+	}
+	first := extend(t, l)
+	l.Submit(cert(3))
+	second := extend(t, l)
+	l.Close()
+
+	l = openLog(t, path)
+	defer l.Close()
+
+	if got := l.STH(); got.TreeSize != 4 || string(got.RootHash) != string(second.RootHash) {
+		t.Errorf("got tree head of size %v after reopening; want 4", got.TreeSize)
+	}
+	if _, ok := l.Proof(cert(1).Raw); !ok {
+		t.Error("no proof for certificate after reopening")
+	}
+
+	proof, err := l.Consistency(first.TreeSize, second.TreeSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctlog.VerifyConsistency(first.TreeSize, second.TreeSize, first.RootHash, second.RootHash, proof); err != nil {
+		t.Error(err)
+	}
+
+	leaf := ctlog.HashLeaf(ctlog.LeafInput(first.Timestamp, cert(2).Raw))
+	index, auditPath, err := l.ProofByHash(leaf, second.TreeSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ctlog.VerifyInclusion(leaf, index, second.TreeSize, auditPath, second.RootHash); err != nil {
+		t.Error(err)
+	}
+	if _, _, err := l.ProofByHash(leaf, second.TreeSize+1); !errors.Is(err, ctlog.ErrNotFound) {
+		t.Errorf("proof served for unsigned tree: %v", err)
+	}
+}
+
+func TestWaitProof(t *testing.T) {
+	l := openLog(t, "")
+	l.Submit(cert(1))
+
+	proof := make(chan error)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := l.WaitProof(ctx, cert(1).Raw)
+		proof <- err
+	}()
+
+	extend(t, l)
+	if err := <-proof; err != nil {
+		t.Errorf("no proof after the certificate has been logged: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.WaitProof(ctx, cert(2).Raw); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v for certificate that isn't logged; want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,206 @@
+package ctlog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidProof is returned if an inclusion or consistency proof doesn't verify.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// HashSize is the size of the hashes in the tree.
+const HashSize = sha256.Size
+
+// Hashes of the leaves and interior nodes are domain separated as in RFC 6962, section 2.1.
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// HashLeaf returns the hash of a leaf with the given content.
+func HashLeaf(leaf []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(leaf)
+	return h.Sum(nil)
+}
+
+func hashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// EmptyRoot is the root hash of the tree without any leaves.
+func EmptyRoot() []byte {
+	h := sha256.Sum256(nil)
+	return h[:]
+}
+
+// split returns the largest power of two smaller than n, where n > 1.
+func split(n uint64) uint64 {
+	k := uint64(1)
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
+
+// RootHash returns the Merkle tree hash of the given leaf hashes (MTH in RFC 6962, section 2.1).
+func RootHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		return EmptyRoot()
+	case 1:
+		return leaves[0]
+	}
+	k := split(uint64(len(leaves)))
+	return hashChildren(RootHash(leaves[:k]), RootHash(leaves[k:]))
+}
+
+// InclusionProof returns the audit path of the leaf at index in the tree of the given leaf hashes
+// (PATH in RFC 6962, section 2.1.1).
+func InclusionProof(index uint64, leaves [][]byte) ([][]byte, error) {
+	if index >= uint64(len(leaves)) {
+		return nil, fmt.Errorf("leaf %v isn't part of a tree of size %v", index, len(leaves))
+	}
+	return inclusionProof(index, leaves), nil
+}
+
+func inclusionProof(index uint64, leaves [][]byte) [][]byte {
+	n := uint64(len(leaves))
+	if n <= 1 {
+		return nil
+	}
+	k := split(n)
+	if index < k {
+		return append(inclusionProof(index, leaves[:k]), RootHash(leaves[k:]))
+	}
+	return append(inclusionProof(index-k, leaves[k:]), RootHash(leaves[:k]))
+}
+
+// ConsistencyProof returns the proof that the tree of the first size leaves is a prefix of the tree of the given
+// leaf hashes (PROOF in RFC 6962, section 2.1.2).
+func ConsistencyProof(first uint64, leaves [][]byte) ([][]byte, error) {
+	if first > uint64(len(leaves)) {
+		return nil, fmt.Errorf("tree of size %v isn't a prefix of a tree of size %v", first, len(leaves))
+	}
+	if first == 0 || first == uint64(len(leaves)) {
+		return nil, nil
+	}
+	return subproof(first, leaves, true), nil
+}
+
+func subproof(m uint64, leaves [][]byte, complete bool) [][]byte {
+	n := uint64(len(leaves))
+	if m == n {
+		if complete {
+			return nil
+		}
+		return [][]byte{RootHash(leaves)}
+	}
+	k := split(n)
+	if m <= k {
+		return append(subproof(m, leaves[:k], complete), RootHash(leaves[k:]))
+	}
+	return append(subproof(m-k, leaves[k:], false), RootHash(leaves[:k]))
+}
+
+// VerifyInclusion checks that the leaf with the given hash is at index in the tree of the given size and root hash
+// (RFC 9162, section 2.1.3.2).
+func VerifyInclusion(leafHash []byte, index, size uint64, proof [][]byte, root []byte) error {
+	if index >= size {
+		return fmt.Errorf("%w: leaf %v isn't part of a tree of size %v", ErrInvalidProof, index, size)
+	}
+
+	fn, sn := index, size-1
+	r := leafHash
+	for _, p := range proof {
+		if sn == 0 {
+			return fmt.Errorf("%w: audit path is too long", ErrInvalidProof)
+		}
+		if fn&1 == 1 || fn == sn {
+			r = hashChildren(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = hashChildren(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 {
+		return fmt.Errorf("%w: audit path is too short", ErrInvalidProof)
+	}
+	if !bytes.Equal(r, root) {
+		return fmt.Errorf("%w: audit path leads to a different root", ErrInvalidProof)
+	}
+	return nil
+}
+
+// VerifyConsistency checks that the tree of size first and root hash firstRoot is a prefix of the tree of size
+// second and root hash secondRoot (RFC 9162, section 2.1.4.2).
+func VerifyConsistency(first, second uint64, firstRoot, secondRoot []byte, proof [][]byte) error {
+	switch {
+	case first > second:
+		return fmt.Errorf("%w: tree of size %v can't be a prefix of a tree of size %v", ErrInvalidProof, first, second)
+	case first == second:
+		if len(proof) != 0 || !bytes.Equal(firstRoot, secondRoot) {
+			return fmt.Errorf("%w: trees of the same size differ", ErrInvalidProof)
+		}
+		return nil
+	case first == 0:
+		// the empty tree is a prefix of every tree
+		if len(proof) != 0 {
+			return fmt.Errorf("%w: proof for the empty tree isn't empty", ErrInvalidProof)
+		}
+		return nil
+	case len(proof) == 0:
+		return fmt.Errorf("%w: proof is empty", ErrInvalidProof)
+	}
+
+	// the root of the first tree is a node of the second one if its size is a power of two
+	if first&(first-1) == 0 {
+		proof = append([][]byte{firstRoot}, proof...)
+	}
+
+	fn, sn := first-1, second-1
+	for fn&1 == 1 {
+		fn >>= 1
+		sn >>= 1
+	}
+
+	fr, sr := proof[0], proof[0]
+	for _, c := range proof[1:] {
+		if sn == 0 {
+			return fmt.Errorf("%w: proof is too long", ErrInvalidProof)
+		}
+		if fn&1 == 1 || fn == sn {
+			fr = hashChildren(c, fr)
+			sr = hashChildren(c, sr)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			sr = hashChildren(sr, c)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	if sn != 0 {
+		return fmt.Errorf("%w: proof is too short", ErrInvalidProof)
+	}
+	if !bytes.Equal(fr, firstRoot) || !bytes.Equal(sr, secondRoot) {
+		return fmt.Errorf("%w: proof leads to different roots", ErrInvalidProof)
+	}
+	return nil
+}
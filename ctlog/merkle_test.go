@@ -0,0 +1,107 @@
+package ctlog_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/raphasch/hotcertification/ctlog"
+)
+
+// test vectors of the reference implementation of RFC 6962
+var (
+	testLeaves = []string{"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f"}
+	testRoots  = []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+)
+
+func leafHashes(t *testing.T, n int) [][]byte {
+	t.Helper()
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		b, err := hex.DecodeString(testLeaves[i%len(testLeaves)])
+		if err != nil {
+			t.Fatal(err)
+		}
+		// leaves repeat after the test vectors but are still distinct
+		leaves[i] = ctlog.HashLeaf(append(b, byte(i/len(testLeaves))))
+		if i < len(testLeaves) {
+			leaves[i] = ctlog.HashLeaf(b)
+		}
+	}
+	return leaves
+}
+
+func TestRootHash(t *testing.T) {
+	if got := hex.EncodeToString(ctlog.RootHash(nil)); got != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("got root %v of empty tree", got)
+	}
+
+	leaves := leafHashes(t, len(testLeaves))
+	for i, want := range testRoots {
+		if got := hex.EncodeToString(ctlog.RootHash(leaves[:i+1])); got != want {
+			t.Errorf("got root %v of tree of size %v; want %v", got, i+1, want)
+		}
+	}
+}
+
+func TestInclusionProof(t *testing.T) {
+	leaves := leafHashes(t, 20)
+	for size := 1; size <= len(leaves); size++ {
+		root := ctlog.RootHash(leaves[:size])
+		for index := 0; index < size; index++ {
+			proof, err := ctlog.InclusionProof(uint64(index), leaves[:size])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ctlog.VerifyInclusion(leaves[index], uint64(index), uint64(size), proof, root); err != nil {
+				t.Errorf("proof of leaf %v in tree of size %v: %v", index, size, err)
+			}
+
+			// the proof is bound to the leaf, its position and the tree
+			other := leaves[(index+1)%len(leaves)]
+			if err := ctlog.VerifyInclusion(other, uint64(index), uint64(size), proof, root); !errors.Is(err, ctlog.ErrInvalidProof) {
+				t.Errorf("proof of leaf %v in tree of size %v accepted for another leaf", index, size)
+			}
+			if size > 1 {
+				wrong := (index + 1) % size
+				if err := ctlog.VerifyInclusion(leaves[index], uint64(wrong), uint64(size), proof, root); err == nil {
+					t.Errorf("proof of leaf %v in tree of size %v accepted at index %v", index, size, wrong)
+				}
+			}
+		}
+	}
+}
+
+func TestConsistencyProof(t *testing.T) {
+	leaves := leafHashes(t, 20)
+	for second := 1; second <= len(leaves); second++ {
+		secondRoot := ctlog.RootHash(leaves[:second])
+		for first := 0; first <= second; first++ {
+			firstRoot := ctlog.RootHash(leaves[:first])
+			proof, err := ctlog.ConsistencyProof(uint64(first), leaves[:second])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ctlog.VerifyConsistency(uint64(first), uint64(second), firstRoot, secondRoot, proof); err != nil {
+				t.Errorf("proof from size %v to %v: %v", first, second, err)
+			}
+
+			// a tree whose first entries differ isn't consistent
+			if first > 0 && first < second {
+				forked := ctlog.RootHash(append([][]byte{leaves[len(leaves)-1]}, leaves[1:first]...))
+				if err := ctlog.VerifyConsistency(uint64(first), uint64(second), forked, secondRoot, proof); err == nil {
+					t.Errorf("proof from size %v to %v accepted for a forked tree", first, second)
+				}
+			}
+		}
+	}
+}
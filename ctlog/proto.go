@@ -0,0 +1,58 @@
+package ctlog
+
+import (
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// Proto converts the tree head into the message sent to clients.
+func (sth *SignedTreeHead) Proto() *protocol.SignedTreeHead {
+	if sth == nil {
+		return nil
+	}
+	return &protocol.SignedTreeHead{
+		TreeSize:  sth.TreeSize,
+		Timestamp: sth.Timestamp,
+		RootHash:  sth.RootHash,
+		Signature: sth.Signature,
+	}
+}
+
+// STHFromProto converts a tree head received from a node.
+func STHFromProto(sth *protocol.SignedTreeHead) *SignedTreeHead {
+	if sth == nil {
+		return nil
+	}
+	return &SignedTreeHead{
+		TreeSize:  sth.GetTreeSize(),
+		Timestamp: sth.GetTimestamp(),
+		RootHash:  sth.GetRootHash(),
+		Signature: sth.GetSignature(),
+	}
+}
+
+// Proto converts the proof into the message sent to clients.
+func (p *Proof) Proto() *protocol.InclusionProof {
+	if p == nil {
+		return nil
+	}
+	return &protocol.InclusionProof{
+		LeafIndex: p.LeafIndex,
+		Timestamp: p.Timestamp,
+		AuditPath: p.AuditPath,
+		STH:       p.STH.Proto(),
+		TreeSize:  p.STH.TreeSize,
+	}
+}
+
+// ProofFromProto converts a proof received from a node.
+func ProofFromProto(p *protocol.InclusionProof) *Proof {
+	if p == nil {
+		return nil
+	}
+	return &Proof{
+		LeafIndex: p.GetLeafIndex(),
+		Timestamp: p.GetTimestamp(),
+		AuditPath: p.GetAuditPath(),
+		STH:       STHFromProto(p.GetSTH()),
+	}
+}
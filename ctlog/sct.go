@@ -0,0 +1,251 @@
+package ctlog
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+
+	hccrypto "github.com/raphasch/hotcertification/crypto"
+)
+
+// Values of the structures defined in RFC 6962, section 3.2 and RFC 5246, section 7.4.1.4.1
+const (
+	signatureTypeSCT    = 0 // certificate_timestamp
+	entryTypePrecert    = 1 // precert_entry
+	hashAlgorithmSHA256 = 4
+	signatureAlgRSA     = 1
+	signatureAlgECDSA   = 3
+)
+
+// SCT is a signed certificate timestamp, the promise of a CT log to add a (pre)certificate (RFC 6962, section 3.2).
+type SCT struct {
+	Version            uint8
+	LogID              [sha256.Size]byte // SHA-256 hash of the public key of the log
+	Timestamp          uint64
+	Extensions         []byte
+	HashAlgorithm      uint8
+	SignatureAlgorithm uint8
+	Signature          []byte
+}
+
+// Serialize returns the TLS encoding of the SCT.
+func (sct *SCT) Serialize() []byte {
+	b := make([]byte, 0, 1+sha256.Size+8+2+len(sct.Extensions)+4+len(sct.Signature))
+	b = append(b, sct.Version)
+	b = append(b, sct.LogID[:]...)
+	b = appendUint64(b, sct.Timestamp)
+	b = appendUint16Prefixed(b, sct.Extensions)
+	b = append(b, sct.HashAlgorithm, sct.SignatureAlgorithm)
+	b = appendUint16Prefixed(b, sct.Signature)
+	return b
+}
+
+func appendUint16Prefixed(b, v []byte) []byte {
+	b = append(b, byte(len(v)>>8), byte(len(v)))
+	return append(b, v...)
+}
+
+// reader reads the TLS encoding of SCTs.
+type reader []byte
+
+var errTruncated = errors.New("truncated SCT")
+
+func (r *reader) next(n int) ([]byte, error) {
+	if len(*r) < n {
+		return nil, errTruncated
+	}
+	b := (*r)[:n]
+	*r = (*r)[n:]
+	return b, nil
+}
+
+func (r *reader) prefixed16() ([]byte, error) {
+	l, err := r.next(2)
+	if err != nil {
+		return nil, err
+	}
+	return r.next(int(l[0])<<8 | int(l[1]))
+}
+
+// ParseSCT parses the TLS encoding of an SCT.
+func ParseSCT(b []byte) (*SCT, error) {
+	r := reader(b)
+	head, err := r.next(1 + sha256.Size + 8)
+	if err != nil {
+		return nil, err
+	}
+	sct := &SCT{Version: head[0]}
+	copy(sct.LogID[:], head[1:])
+	for _, v := range head[1+sha256.Size:] {
+		sct.Timestamp = sct.Timestamp<<8 | uint64(v)
+	}
+
+	if sct.Extensions, err = r.prefixed16(); err != nil {
+		return nil, err
+	}
+	algs, err := r.next(2)
+	if err != nil {
+		return nil, err
+	}
+	sct.HashAlgorithm, sct.SignatureAlgorithm = algs[0], algs[1]
+	if sct.Signature, err = r.prefixed16(); err != nil {
+		return nil, err
+	}
+	if len(r) != 0 {
+		return nil, fmt.Errorf("%v bytes left after SCT", len(r))
+	}
+	return sct, nil
+}
+
+// SerializeSCTList returns the SignedCertificateTimestampList (RFC 6962, section 3.3) of the SCTs, which is
+// embedded in the certificate.
+func SerializeSCTList(scts []*SCT) []byte {
+	var list []byte
+	for _, sct := range scts {
+		list = appendUint16Prefixed(list, sct.Serialize())
+	}
+	return appendUint16Prefixed(nil, list)
+}
+
+// ParseSCTList parses a SignedCertificateTimestampList.
+func ParseSCTList(b []byte) ([]*SCT, error) {
+	r := reader(b)
+	list, err := r.prefixed16()
+	if err != nil {
+		return nil, err
+	}
+	if len(r) != 0 {
+		return nil, fmt.Errorf("%v bytes left after SCT list", len(r))
+	}
+
+	var scts []*SCT
+	for lr := reader(list); len(lr) > 0; {
+		b, err := lr.prefixed16()
+		if err != nil {
+			return nil, err
+		}
+		sct, err := ParseSCT(b)
+		if err != nil {
+			return nil, err
+		}
+		scts = append(scts, sct)
+	}
+	return scts, nil
+}
+
+// EmbeddedSCTs returns the SCTs embedded in a certificate.
+func EmbeddedSCTs(cert *x509.Certificate) ([]*SCT, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(hccrypto.OIDSCTList) {
+			continue
+		}
+		var list []byte
+		if _, err := asn1.Unmarshal(ext.Value, &list); err != nil {
+			return nil, err
+		}
+		return ParseSCTList(list)
+	}
+	return nil, nil
+}
+
+// tbsCertificate is the part of a certificate that is signed (RFC 5280, section 4.1) with only the extensions parsed.
+type tbsCertificate struct {
+	Raw                asn1.RawContent
+	Version            int `asn1:"optional,explicit,default:0,tag:0"`
+	SerialNumber       *big.Int
+	SignatureAlgorithm asn1.RawValue
+	Issuer             asn1.RawValue
+	Validity           asn1.RawValue
+	Subject            asn1.RawValue
+	PublicKey          asn1.RawValue
+	UniqueID           asn1.BitString   `asn1:"optional,tag:1"`
+	SubjectUniqueID    asn1.BitString   `asn1:"optional,tag:2"`
+	Extensions         []pkix.Extension `asn1:"omitempty,optional,explicit,tag:3"`
+}
+
+// PrecertTBS returns the TBS part of a precertificate or of the certificate issued for it without the poison and SCT
+// list extensions. This is what SCTs for precertificates are signed on; it is the same for both.
+func PrecertTBS(cert *x509.Certificate) ([]byte, error) {
+	var tbs tbsCertificate
+	if _, err := asn1.Unmarshal(cert.RawTBSCertificate, &tbs); err != nil {
+		return nil, fmt.Errorf("failed to parse TBS certificate: %w", err)
+	}
+
+	extensions := tbs.Extensions[:0]
+	for _, ext := range tbs.Extensions {
+		if !ext.Id.Equal(hccrypto.OIDPrecertificatePoison) && !ext.Id.Equal(hccrypto.OIDSCTList) {
+			extensions = append(extensions, ext)
+		}
+	}
+	tbs.Extensions = extensions
+	tbs.Raw = nil
+
+	return asn1.Marshal(tbs)
+}
+
+// SignedData returns what the log signs for an SCT on a precertificate (RFC 6962, section 3.2).
+// tbs is the TBS part without the poison extension and issuerKeyHash the SHA-256 hash of the issuer's public key.
+func SignedData(sct *SCT, issuerKeyHash [sha256.Size]byte, tbs []byte) []byte {
+	b := make([]byte, 0, 2+8+2+sha256.Size+3+len(tbs)+2+len(sct.Extensions))
+	b = append(b, sct.Version, signatureTypeSCT)
+	b = appendUint64(b, sct.Timestamp)
+	b = append(b, 0, entryTypePrecert)
+	b = append(b, issuerKeyHash[:]...)
+	b = append(b, byte(len(tbs)>>16), byte(len(tbs)>>8), byte(len(tbs)))
+	b = append(b, tbs...)
+	return appendUint16Prefixed(b, sct.Extensions)
+}
+
+// LogID returns the ID of the CT log with the given public key.
+func LogID(pub crypto.PublicKey) ([sha256.Size]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(der), nil
+}
+
+// VerifySCT checks that the SCT has been issued by the CT log with the given public key for the precertificate cert,
+// or the certificate issued for it, that has been issued by issuer.
+func VerifySCT(sct *SCT, pub crypto.PublicKey, cert, issuer *x509.Certificate) error {
+	id, err := LogID(pub)
+	if err != nil {
+		return err
+	}
+	if id != sct.LogID {
+		return fmt.Errorf("SCT has been issued by another log")
+	}
+	if sct.HashAlgorithm != hashAlgorithmSHA256 {
+		return fmt.Errorf("unsupported hash algorithm %v", sct.HashAlgorithm)
+	}
+
+	tbs, err := PrecertTBS(cert)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(SignedData(sct, sha256.Sum256(issuer.RawSubjectPublicKeyInfo), tbs))
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if sct.SignatureAlgorithm != signatureAlgECDSA || !ecdsa.VerifyASN1(key, digest[:], sct.Signature) {
+			return fmt.Errorf("invalid signature on SCT")
+		}
+	case *rsa.PublicKey:
+		if sct.SignatureAlgorithm != signatureAlgRSA {
+			return fmt.Errorf("invalid signature on SCT")
+		}
+		if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sct.Signature); err != nil {
+			return fmt.Errorf("invalid signature on SCT: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T of CT log", pub)
+	}
+	return nil
+}
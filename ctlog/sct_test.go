@@ -0,0 +1,162 @@
+package ctlog_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/niclabs/tcrsa"
+
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/ctlog"
+	"github.com/raphasch/hotcertification/ctlog/cttest"
+)
+
+// issue returns a precertificate and the issuer it has been signed by.
+func issue(t *testing.T) (precert, issuer *x509.Certificate, csr *x509.CertificateRequest) {
+	t.Helper()
+	key := thresholdKeys(t)[0]
+
+	issuer, err := crypto.GenerateRootCert(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer = thresholdSign(t, issuer)
+
+	clientKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if csr, err = crypto.GenerateCSR(clientKey); err != nil {
+		t.Fatal(err)
+	}
+	if precert, err = crypto.GenerateCert(csr, issuer, key, crypto.Precertificate()); err != nil {
+		t.Fatal(err)
+	}
+	return thresholdSign(t, precert), issuer, csr
+}
+
+// thresholdSign signs a certificate with a threshold of the keys like the replicas do.
+func thresholdSign(t *testing.T, cert *x509.Certificate) *x509.Certificate {
+	t.Helper()
+	keys := thresholdKeys(t)
+
+	var shares tcrsa.SigShareList
+	for _, key := range keys[:3] {
+		share, err := crypto.ComputePartialSignature(cert, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, share)
+	}
+	signed, err := crypto.ComputeFullySignedCert(cert, keys[0], shares...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAddPreChain(t *testing.T) {
+	srv, stub, err := cttest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	client := &ctlog.Client{URL: srv.URL, PublicKey: stub.Key.Public(), HTTP: srv.Client()}
+
+	precert, issuer, csr := issue(t)
+	sct, err := client.AddPreChain(context.Background(), precert, issuer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stub.Submitted()) != 1 {
+		t.Errorf("log got %v precertificates; want 1", len(stub.Submitted()))
+	}
+
+	// the SCT is embedded in the certificate and still valid for it
+	cert, err := crypto.GenerateCert(csr, issuer, thresholdKeys(t)[0],
+		crypto.FromPrecertificate(precert, ctlog.SerializeSCTList([]*ctlog.SCT{sct})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	precertTBS, err := ctlog.PrecertTBS(precert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certTBS, err := ctlog.PrecertTBS(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(precertTBS, certTBS) {
+		t.Fatal("certificate differs from its precertificate in more than the CT extensions")
+	}
+
+	embedded, err := ctlog.EmbeddedSCTs(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(embedded) != 1 {
+		t.Fatalf("got %v embedded SCTs; want 1", len(embedded))
+	}
+	if err := ctlog.VerifySCT(embedded[0], stub.Key.Public(), cert, issuer); err != nil {
+		t.Errorf("embedded SCT: %v", err)
+	}
+
+	// the SCT is bound to the precertificate
+	other, _, _ := issue(t)
+	if err := ctlog.VerifySCT(embedded[0], stub.Key.Public(), other, issuer); err == nil {
+		t.Error("SCT accepted for another certificate")
+	}
+}
+
+func TestAddPreChainRefused(t *testing.T) {
+	srv, stub, err := cttest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	client := &ctlog.Client{URL: srv.URL, HTTP: srv.Client()}
+
+	precert, issuer, csr := issue(t)
+	stub.Refuse(true)
+	if _, err := client.AddPreChain(context.Background(), precert, issuer); err == nil {
+		t.Error("got SCT from a log that is down")
+	}
+	stub.Refuse(false)
+
+	// only precertificates are accepted
+	cert, err := crypto.GenerateCert(csr, issuer, thresholdKeys(t)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AddPreChain(context.Background(), cert, issuer); err == nil {
+		t.Error("got SCT for a certificate without the poison extension")
+	}
+}
+
+func TestSCTListRoundTrip(t *testing.T) {
+	scts := []*ctlog.SCT{
+		{Timestamp: 1, HashAlgorithm: 4, SignatureAlgorithm: 3, Signature: []byte("first")},
+		{Timestamp: 2, Extensions: []byte{1, 2}, HashAlgorithm: 4, SignatureAlgorithm: 1, Signature: []byte("second")},
+	}
+	scts[1].LogID[0] = 1
+
+	parsed, err := ctlog.ParseSCTList(ctlog.SerializeSCTList(scts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(scts) {
+		t.Fatalf("got %v SCTs; want %v", len(parsed), len(scts))
+	}
+	for i := range scts {
+		if !bytes.Equal(parsed[i].Serialize(), scts[i].Serialize()) {
+			t.Errorf("SCT %v changed in the round trip", i)
+		}
+	}
+	if _, err := ctlog.ParseSCTList(ctlog.SerializeSCTList(scts)[:10]); err == nil {
+		t.Error("truncated SCT list accepted")
+	}
+}
@@ -0,0 +1,99 @@
+package ctlog
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Values of the structures defined in RFC 6962, section 3
+const (
+	version          = 0 // v1
+	leafTypeEntry    = 0 // timestamped_entry
+	entryTypeX509    = 0 // x509_entry
+	signatureTypeSTH = 1 // tree_hash
+)
+
+// LeafInput returns the MerkleTreeLeaf (RFC 6962, section 3.4) of a certificate that has been added to the log at
+// the given time in milliseconds since the epoch. Its hash is the leaf hash the proofs refer to.
+func LeafInput(timestamp uint64, cert []byte) []byte {
+	b := make([]byte, 0, 2+8+2+3+len(cert)+2)
+	b = append(b, version, leafTypeEntry)
+	b = appendUint64(b, timestamp)
+	b = append(b, 0, entryTypeX509)
+	b = append(b, byte(len(cert)>>16), byte(len(cert)>>8), byte(len(cert)))
+	b = append(b, cert...)
+	// no extensions
+	b = append(b, 0, 0)
+	return b
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
+
+// Timestamp returns t in milliseconds since the epoch as used by the log.
+func Timestamp(t time.Time) uint64 {
+	return uint64(t.UnixNano() / int64(time.Millisecond))
+}
+
+// SignedTreeHead commits the log to the tree of the first TreeSize entries (RFC 6962, section 3.5).
+// It is threshold signed with the key of the CA.
+type SignedTreeHead struct {
+	TreeSize  uint64
+	Timestamp uint64 // in milliseconds since the epoch
+	RootHash  []byte
+	Signature []byte // PKCS #1 v1.5 signature on Digest
+}
+
+// DigestHash is the hash of Digest; tree heads are always signed with SHA-256 (RFC 6962, section 2.1.4).
+const DigestHash = crypto.SHA256
+
+// Digest returns the SHA-256 hash of the TreeHeadSignature structure, which is what the CA signs.
+func (sth *SignedTreeHead) Digest() []byte {
+	b := make([]byte, 0, 2+8+8+HashSize)
+	b = append(b, version, signatureTypeSTH)
+	b = appendUint64(b, sth.Timestamp)
+	b = appendUint64(b, sth.TreeSize)
+	b = append(b, sth.RootHash...)
+
+	h := sha256.Sum256(b)
+	return h[:]
+}
+
+// Verify checks that the tree head has been signed with the key of the CA.
+func (sth *SignedTreeHead) Verify(pub *rsa.PublicKey) error {
+	if len(sth.RootHash) != HashSize {
+		return fmt.Errorf("root hash has %v bytes instead of %v", len(sth.RootHash), HashSize)
+	}
+	if err := rsa.VerifyPKCS1v15(pub, DigestHash, sth.Digest(), sth.Signature); err != nil {
+		return fmt.Errorf("invalid signature on tree head of size %v: %w", sth.TreeSize, err)
+	}
+	return nil
+}
+
+// Proof proves that a certificate is part of the tree signed by STH.
+type Proof struct {
+	LeafIndex uint64
+	Timestamp uint64 // when the certificate has been added to the log; part of the leaf
+	AuditPath [][]byte
+	STH       *SignedTreeHead
+}
+
+// VerifyCertificate checks that the certificate in DER is part of the log according to proof and that the tree head
+// of the proof has been signed with the key of the CA.
+func VerifyCertificate(pub *rsa.PublicKey, cert []byte, proof *Proof) error {
+	if proof.STH == nil {
+		return fmt.Errorf("%w: proof doesn't contain a tree head", ErrInvalidProof)
+	}
+	if err := proof.STH.Verify(pub); err != nil {
+		return err
+	}
+	leaf := HashLeaf(LeafInput(proof.Timestamp, cert))
+	return VerifyInclusion(leaf, proof.LeafIndex, proof.STH.TreeSize, proof.AuditPath, proof.STH.RootHash)
+}
@@ -0,0 +1,54 @@
+package hotcertification
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// RequestError is the error of a request that failed somewhere in the certification process.
+// Its Reason is handed to the client so that it can tell whether trying again makes sense.
+type RequestError struct {
+	Reason protocol.ErrorReason
+	Msg    string
+	Err    error // the underlying cause; might be nil
+}
+
+// NewRequestError returns a RequestError with the given reason that wraps err.
+func NewRequestError(reason protocol.ErrorReason, err error, format string, args ...interface{}) *RequestError {
+	return &RequestError{
+		Reason: reason,
+		Msg:    fmt.Sprintf(format, args...),
+		Err:    err,
+	}
+}
+
+func (e *RequestError) Error() string {
+	if e.Err == nil {
+		return e.Msg
+	}
+	return fmt.Sprintf("%v: %v", e.Msg, e.Err)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// ReasonOf returns the reason of the first RequestError in the chain of err or OVERLOADED for an OverloadedError.
+// Other errors are internal ones; a nil error has no reason.
+func ReasonOf(err error) protocol.ErrorReason {
+	if err == nil {
+		return protocol.ErrorReason_NONE
+	}
+
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.Reason
+	}
+	var overloaded *OverloadedError
+	if errors.As(err, &overloaded) {
+		return protocol.ErrorReason_OVERLOADED
+	}
+	return protocol.ErrorReason_INTERNAL
+}
@@ -0,0 +1,106 @@
+package hotcertification
+
+import (
+	"fmt"
+
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// number of events a watcher can fall behind before new events are dropped for it
+const watcherBuffer = 32
+
+// emit records an event for a request and passes it on to everyone watching the request.
+// The event's ID and State are filled in from info. The caller must hold c.Mut.
+func (c *Coordinator) emit(hash string, info *RequestInfo, event *protocol.RequestEvent) {
+	event.ID = hash
+	event.State = info.State()
+	info.events = append(info.events, event)
+	if info.span != nil {
+		info.span.AddEvent(event.Message)
+	}
+
+	for _, w := range info.watchers {
+		select {
+		case w <- event:
+		default:
+			c.logFor(hash).Warn("Dropping event for slow watcher")
+		}
+	}
+
+	// nothing will happen to a finished request anymore
+	if info.finished() {
+		info.endSpan()
+		for _, w := range info.watchers {
+			close(w)
+		}
+		info.watchers = nil
+	}
+}
+
+// Watch returns a channel that first replays all past events of a request and then delivers new ones as they happen.
+// The channel is closed after the request has been rejected or its signing session finished.
+// The returned function stops watching; ok is false if the request is unknown.
+func (c *Coordinator) Watch(hash string) (events <-chan *protocol.RequestEvent, cancel func(), ok bool) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	info := c.Database[hash]
+	if info == nil {
+		return nil, nil, false
+	}
+
+	w := make(chan *protocol.RequestEvent, len(info.events)+watcherBuffer)
+	for _, event := range info.events {
+		w <- event
+	}
+
+	if info.finished() {
+		close(w)
+		return w, func() {}, true
+	}
+
+	info.watchers = append(info.watchers, w)
+	cancel = func() {
+		c.Mut.Lock()
+		defer c.Mut.Unlock()
+
+		for i, other := range info.watchers {
+			if other == w {
+				info.watchers = append(info.watchers[:i], info.watchers[i+1:]...)
+				return
+			}
+		}
+	}
+
+	return w, cancel, true
+}
+
+// Events returns the events of a request so far; ok is false if the request is unknown.
+func (c *Coordinator) Events(hash string) (events []*protocol.RequestEvent, ok bool) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	info := c.Database[hash]
+	if info == nil {
+		return nil, false
+	}
+	return append([]*protocol.RequestEvent(nil), info.events...), true
+}
+
+// Progress reports that shares out of the threshold signature shares needed for a request have been collected on
+// what is signed, which is either the certificate or the precertificate that is submitted to the CT logs first.
+func (c *Coordinator) Progress(hash, what string, shares, threshold int) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	info := c.Database[hash]
+	if info == nil {
+		return
+	}
+
+	c.emit(hash, info, &protocol.RequestEvent{
+		Message:   fmt.Sprintf("collected %v of %v signature shares on the %v", shares, threshold, what),
+		Shares:    uint32(shares),
+		Threshold: uint32(threshold),
+	})
+}
@@ -0,0 +1,142 @@
+package hotcertification_test
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// drain returns the events of w until it is closed, failing the test if that doesn't happen within a second.
+func drain(t *testing.T, w <-chan *protocol.RequestEvent) []*protocol.RequestEvent {
+	t.Helper()
+	var events []*protocol.RequestEvent
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event, ok := <-w:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatalf("watch channel hasn't been closed after %v events", len(events))
+		}
+	}
+}
+
+// messages returns the messages of events.
+func messages(events []*protocol.RequestEvent) []string {
+	msgs := make([]string, len(events))
+	for i, event := range events {
+		msgs[i] = event.Message
+	}
+	return msgs
+}
+
+func TestWatchIssued(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	hash := add(t, c, newTestCSR())
+
+	before, cancel, ok := c.Watch(hash)
+	if !ok {
+		t.Fatal("request is unknown")
+	}
+	defer cancel()
+
+	replicate(t, c)
+	<-c.SigningQueue
+	c.Progress(hash, "certificate", 3, 3)
+	c.Finish(hash, &x509.Certificate{Raw: []byte{1}}, nil)
+
+	events := drain(t, before)
+	want := []struct {
+		message string
+		state   protocol.RequestState
+	}{
+		{"queued for replication", protocol.RequestState_RECEIVED},
+		{"proposed for replication", protocol.RequestState_PROPOSED},
+		{"accepted by replica", protocol.RequestState_PROPOSED},
+		{"committed in view", protocol.RequestState_REPLICATED},
+		{"collected 3 of 3 signature shares on the certificate", protocol.RequestState_REPLICATED},
+		{"certificate issued", protocol.RequestState_SIGNED},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got events %q", messages(events))
+	}
+	for i, event := range events {
+		if !strings.HasPrefix(event.Message, want[i].message) || event.State != want[i].state || event.ID != hash {
+			t.Errorf("event %v: got %q in state %v, want %q in state %v", i, event.Message, event.State, want[i].message, want[i].state)
+		}
+	}
+
+	// a watcher that comes after the request has finished gets the same events and a closed channel
+	after, _, ok := c.Watch(hash)
+	if !ok {
+		t.Fatal("finished request is unknown")
+	}
+	if replayed := drain(t, after); len(replayed) != len(events) {
+		t.Errorf("late watcher got events %q", messages(replayed))
+	}
+}
+
+func TestWatchWithdrawn(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	ctx, cancel := context.WithCancel(context.Background())
+	hash, err := c.AddRequest(ctx, newTestCSR())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, _, _ := c.Watch(hash)
+	cancel()
+
+	events := drain(t, w)
+	if last := events[len(events)-1]; !strings.HasPrefix(last.Message, "withdrawn") {
+		t.Errorf("last event is %q", last.Message)
+	}
+}
+
+func TestWatchExpired(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{RequestTimeout: 50})
+	hash := add(t, c, newTestCSR())
+	c.Get(context.Background())
+	w, _, _ := c.Watch(hash)
+
+	c.Collect(time.Now().Add(time.Second))
+
+	events := drain(t, w)
+	if last := events[len(events)-1]; last.Message != "expired" {
+		t.Errorf("last event is %q", last.Message)
+	}
+}
+
+// TestSlowWatcher checks that a watcher that doesn't read its events doesn't hold up the request.
+func TestSlowWatcher(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	hash := add(t, c, newTestCSR())
+	if _, _, ok := c.Watch(hash); !ok {
+		t.Fatal("request is unknown")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 1000; i++ {
+			c.Progress(hash, "certificate", i, 1000)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("events block on a watcher that doesn't read them")
+	}
+
+	// the coordinator is still usable
+	if _, ok := c.Lookup(hash); !ok {
+		t.Error("request is unknown")
+	}
+}
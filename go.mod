@@ -4,14 +4,26 @@ go 1.16
 
 require (
 	github.com/mattn/go-isatty v0.0.12
+	github.com/miekg/pkcs11 v1.0.3
 	github.com/niclabs/tcrsa v0.0.5
+	github.com/prometheus/client_golang v1.10.0
 	github.com/relab/gorums v0.5.0
 	github.com/relab/hotstuff v0.2.2
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.1
+	go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc v0.20.0
+	go.opentelemetry.io/otel v0.20.0
+	go.opentelemetry.io/otel/exporters/otlp v0.20.0
+	go.opentelemetry.io/otel/exporters/stdout v0.20.0
+	go.opentelemetry.io/otel/sdk v0.20.0
+	go.opentelemetry.io/otel/trace v0.20.0
 	go.uber.org/zap v1.16.0
+	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a
+	golang.org/x/term v0.0.0-20210503060354-a79de5458b56
+	google.golang.org/genproto v0.0.0-20210510173355-fb37daa5cd7a
 	google.golang.org/grpc v1.37.0
 	google.golang.org/protobuf v1.26.0
+	gopkg.in/natefinch/lumberjack.v2 v2.0.0
 )
 
 replace github.com/relab/hotstuff v0.2.2 => ./hotstuff
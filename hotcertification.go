@@ -6,12 +6,20 @@ import (
 	"crypto/x509"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/relab/hotstuff"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/raphasch/hotcertification/audit"
+	"github.com/raphasch/hotcertification/ctlog"
 	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/metrics"
 	"github.com/raphasch/hotcertification/protocol"
+	"github.com/raphasch/hotcertification/tracing"
 )
 
 // Information other replicas in network have to know about each other (public knowledge)
@@ -23,6 +31,18 @@ type Node struct {
 	ClientSrvAddr      string `mapstructure:"client-srv-address"`
 	ReplicationSrvAddr string `mapstructure:"replication-srv-address"`
 	SigningSrvAddr     string `mapstructure:"signing-srv-address"`
+	MetricsAddr        string `mapstructure:"metrics-address"` // metrics aren't served if empty
+	AuditLog           string `mapstructure:"audit-log"`       // path of the audit log; nothing is audited if empty
+	CTLog              string `mapstructure:"ct-log"`          // path of the certificate log; only kept in memory if empty
+	AdminAddr          string `mapstructure:"admin-address"`   // the admin service isn't served if empty
+	AdminCert          string `mapstructure:"admin-cert"`      // TLS certificate of the admin service
+	AdminKey           string `mapstructure:"admin-key"`       // private key of the admin certificate
+}
+
+// CTLogEndpoint is a Certificate Transparency log precertificates are submitted to.
+type CTLogEndpoint struct {
+	URL       string `mapstructure:"url"`
+	PublicKey string `mapstructure:"public-key"` // PEM file with the key SCTs are verified with; not verified if empty
 }
 
 type Options struct {
@@ -40,46 +60,186 @@ type Options struct {
 	ViewTimeout int         `mapstructure:"view-timeout"`
 
 	// HotCertification and miscellaneous configs
-	ThresholdKey string `mapstructure:"thresholdkey"`
-	KeySize      int    `mapstructure:"key-size"`
-	ConfigFile   string `mapstructure:"config"`
-	Nodes        []Node
+	ThresholdKey   string `mapstructure:"thresholdkey"`
+	Passphrase     string `mapstructure:"passphrase"` // where the passphrase of an encrypted thresholdkey is read from; see crypto.PassphraseSource
+	KeySize        int    `mapstructure:"key-size"`
+	SigningWorkers int    `mapstructure:"signing-workers"` // number of threshold signing sessions run concurrently
+	SigningTimeout int    `mapstructure:"signing-timeout"` // in milliseconds; upper bound for one signing session
+
+	// Admission control configs
+	QueueLimit        int     `mapstructure:"queue-limit"`         // max number of requests waiting for replication
+	SigningQueueLimit int     `mapstructure:"signing-queue-limit"` // max number of requests waiting for a signing session
+	ClientQueueLimit  int     `mapstructure:"client-queue-limit"`  // max number of requests of one client waiting for replication; unlimited if zero
+	ClientRate        float64 `mapstructure:"client-rate"`         // requests per second one client may submit; unlimited if zero
+	ClientBurst       int     `mapstructure:"client-burst"`        // requests one client may submit at once before the rate limit applies
+	RetryAfter        int     `mapstructure:"retry-after"`         // in milliseconds; hint for clients rejected because a queue is full
+
+	// Tracing configs
+	TraceExporter string `mapstructure:"trace-exporter"` // "otlp", "file" or empty to disable tracing
+	TraceEndpoint string `mapstructure:"trace-endpoint"` // address of the OTLP collector or path of the trace file
+
+	// Audit log configs
+	CheckpointInterval int `mapstructure:"checkpoint-interval"` // number of commits between threshold signed checkpoints
+
+	// Certificate log configs
+	LogSequencer  hotstuff.ID `mapstructure:"log-sequencer"`  // node that decides the order of the certificate log; node 1 if zero
+	STHInterval   int         `mapstructure:"sth-interval"`   // in milliseconds; how often new certificates are added to the log
+	InclusionWait int         `mapstructure:"inclusion-wait"` // in milliseconds; how long a certificate is held back for its inclusion proof; zero doesn't wait
+
+	// Certificate Transparency configs
+	CTLogs  []CTLogEndpoint `mapstructure:"ct-logs"`  // logs precertificates are submitted to; certificates carry no SCTs if empty
+	MinSCTs int             `mapstructure:"min-scts"` // number of SCTs embedded in every certificate; one if zero
+
+	// Admin service configs
+	AdminCA string `mapstructure:"admin-ca"` // CA that issues the client certificates of operators
+
+	// Connection configs
+	ConnectTimeout int `mapstructure:"connect-timeout"` // in milliseconds; how long one attempt to connect to another node may take
+	MaxBackoff     int `mapstructure:"max-backoff"`     // in milliseconds; max time between two attempts to connect to another node
+
+	// Logging configs
+	Logging logging.Config `mapstructure:"log"`
+
+	// Request lifetime configs
+	RequestTimeout int `mapstructure:"request-timeout"` // in milliseconds; max time a request may take until its certificate is issued
+	Retention      int `mapstructure:"retention"`       // in milliseconds; time completed requests are kept before they are removed
+	ReplayWindow   int `mapstructure:"replay-window"`   // number of commits a replicated CSR is remembered for to reject replays of it
+	DrainTimeout   int `mapstructure:"drain-timeout"`   // in milliseconds; how long requests in flight are waited for when shutting down
+
+	ConfigFile string `mapstructure:"config"`
+	Nodes      []Node
 }
 
 type RequestInfo struct {
 	CSR         *protocol.CSR
 	Certificate *x509.Certificate
+	Err         error // set if the signing session for this request failed
 	Received    bool
 	Validated   bool
 	Proposed    bool
 	Replicated  bool
 	Signed      bool
 	Returned    bool
+	Rejected    bool
+	Submitted   time.Time     // when a client submitted the request to this node
+	Committed   time.Time     // when the request has been committed by HotStuff
+	Deadline    time.Time     // the request expires if it hasn't been completed by then
+	Completed   time.Time     // when the request has been rejected or its signing session finished
+	done        chan struct{} // closed when the request has been rejected or its signing session finished
+	holders     int           // number of submissions that still wait for the request
+	span        trace.Span    // spans the request on the node it has been submitted to; nil on the other nodes
+	events      []*protocol.RequestEvent
+	watchers    []chan *protocol.RequestEvent
+}
+
+func newRequestInfo(csr *protocol.CSR) *RequestInfo {
+	return &RequestInfo{
+		CSR:  csr,
+		done: make(chan struct{}),
+	}
+}
+
+// State returns the furthest stage of the certification process the request has reached.
+func (info *RequestInfo) State() protocol.RequestState {
+	switch {
+	case info.Rejected:
+		return protocol.RequestState_REJECTED
+	case info.Returned:
+		return protocol.RequestState_RETURNED
+	case info.Certificate != nil:
+		return protocol.RequestState_SIGNED
+	case info.Replicated:
+		return protocol.RequestState_REPLICATED
+	case info.Proposed:
+		return protocol.RequestState_PROPOSED
+	case info.Validated:
+		return protocol.RequestState_VALIDATED
+	case info.Received:
+		return protocol.RequestState_RECEIVED
+	default:
+		return protocol.RequestState_UNKNOWN
+	}
+}
+
+// finish marks the request as completed and wakes up everyone waiting for it.
+func (info *RequestInfo) finish() {
+	if !info.finished() {
+		info.Completed = time.Now()
+		close(info.done)
+	}
+}
+
+// endSpan ends the span of the request, recording its error if it failed.
+func (info *RequestInfo) endSpan() {
+	if info.span == nil {
+		return
+	}
+	if info.Err != nil {
+		info.span.RecordError(info.Err)
+		info.span.SetStatus(otelcodes.Error, ReasonOf(info.Err).String())
+	}
+	info.span.End()
+	info.span = nil
+}
+
+func (info *RequestInfo) finished() bool {
+	select {
+	case <-info.done:
+		return true
+	default:
+		return false
+	}
 }
 
 type Coordinator struct {
 	Mut              sync.Mutex
-	ReplicationQueue chan *protocol.CSR
+	ReplicationQueue *FairQueue
 	SigningQueue     chan *protocol.CSR
-	FinishedCerts    chan *protocol.Certificate
 	Database         map[string]*RequestInfo // simulating a basic database; the key the hash of the CSR
-	Marshaler        proto.MarshalOptions    // for translating into hotstuff.Command
-	Unmarshaler      proto.UnmarshalOptions  // for checking semantics of a request
+	replicated       map[string]uint64       // commit of each CSR committed within the replay window; outlives the entries
+	replayed         []string                // hashes of replicated in the order they have been committed
+	commits          uint64                  // number of commands executed so far
+	replayWindow     uint64
+	Marshaler        proto.MarshalOptions   // for translating into hotstuff.Command
+	Unmarshaler      proto.UnmarshalOptions // for checking semantics of a request
 	HS               *hotstuff.HotStuff
 	Log              logging.Logger
+	Policy           func(csr *protocol.CSR, req *x509.CertificateRequest) error // decides whether a well-formed CSR is signed; nil signs all
+	Audit            *audit.Log                                                  // records the operations of the CA; nil disables auditing
+	CTLog            *ctlog.Log                                                  // log of the issued certificates; nil disables it
+	Readiness        *Readiness                                                  // reports whether the node is ready; nil disables it
+	admission        *admission
+	paused           bool   // new requests are refused while paused
+	pauseReason      string // why an operator paused issuance
+	draining         bool   // the node is shutting down and doesn't take requests anymore
+	requestTimeout   time.Duration
+	retention        time.Duration
 	c                chan struct{}
 }
 
-func NewCoordinator() *Coordinator {
-	// TODO: How to pick good channel size?
+func NewCoordinator(opts *Options) *Coordinator {
+	signingQueueLimit := opts.SigningQueueLimit
+	if signingQueueLimit <= 0 {
+		signingQueueLimit = defaultSigningQueueLimit
+	}
+
+	replayWindow := opts.ReplayWindow
+	if replayWindow <= 0 {
+		replayWindow = defaultReplayWindow
+	}
+
 	return &Coordinator{
-		ReplicationQueue: make(chan *protocol.CSR, 100000),
-		SigningQueue:     make(chan *protocol.CSR, 100000),
-		FinishedCerts:    make(chan *protocol.Certificate, 100000),
+		ReplicationQueue: NewFairQueue(),
+		SigningQueue:     make(chan *protocol.CSR, signingQueueLimit),
+		admission:        newAdmission(opts),
+		requestTimeout:   durationOrDefault(opts.RequestTimeout, defaultRequestTimeout),
+		retention:        durationOrDefault(opts.Retention, defaultRetention),
 		Database:         make(map[string]*RequestInfo),
+		replicated:       make(map[string]uint64),
+		replayWindow:     uint64(replayWindow),
 		Marshaler:        proto.MarshalOptions{Deterministic: true},
 		Unmarshaler:      proto.UnmarshalOptions{DiscardUnknown: true},
-		Log:              logging.New("HOT LOG"),
+		Log:              logging.New("coordinator"),
 		c:                make(chan struct{}),
 	}
 }
@@ -89,7 +249,13 @@ func (c *Coordinator) InitModule(hs *hotstuff.HotStuff, _ *hotstuff.OptionsBuild
 	c.HS = hs
 }
 
-func (c *Coordinator) AddRequest(csr *protocol.CSR) {
+// AddRequest stores the request in the database and queues it for replication.
+// The returned hash of the CSR identifies the request.
+// Resubmitting a known CSR doesn't start a new certification process; the caller is attached to the existing request.
+// A new request is refused with an *OverloadedError if the queues are full or the client exceeded its limits.
+// The request expires at the deadline of ctx, but no later than the configured request timeout. If ctx is
+// cancelled while the request is still waiting to be proposed and no other submission waits for it, it is withdrawn.
+func (c *Coordinator) AddRequest(ctx context.Context, csr *protocol.CSR) (string, error) {
 	/*
 		0. ?Validate Request or do this in protocolServer struct?
 		1. Wrap protocol.CSR into RequestInfo struct
@@ -97,24 +263,190 @@ func (c *Coordinator) AddRequest(csr *protocol.CSR) {
 		3. Add to Queue
 	*/
 
-	c.ReplicationQueue <- csr
+	hash := HashCSR(csr)
+	log := c.logFor(hash)
 
-	c.Log.Info("Added to CSR to Replication Queue")
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
 
-	hash := HashCSR(csr)
-	info := &RequestInfo{
-		CSR:        csr,
-		Received:   true,
-		Validated:  false,
-		Proposed:   false,
-		Replicated: false,
-		Signed:     false,
-		Returned:   false,
+	info := c.Database[hash]
+	if _, ok := c.replicated[hash]; ok && info == nil {
+		// the request has been completed and collected, so its certificate isn't known anymore
+		log.Info("Refusing CSR that has already been replicated")
+		metrics.CSRs.WithLabelValues("refused", protocol.ErrorReason_INVALID_CSR.String()).Inc()
+		return hash, NewRequestError(protocol.ErrorReason_INVALID_CSR, ErrCollected, "CSR has already been certified")
+	}
+	if c.paused && (info == nil || info.Replicated && info.Err != nil) {
+		log.Info("Refusing CSR because issuance is paused")
+		metrics.CSRs.WithLabelValues("refused", protocol.ErrorReason_PAUSED.String()).Inc()
+		return hash, NewRequestError(protocol.ErrorReason_PAUSED, nil, "issuance is paused: %v", c.pauseReason)
+	}
+	if c.draining && (info == nil || info.Replicated && info.Err != nil) {
+		log.Info("Refusing CSR because the node is shutting down")
+		metrics.CSRs.WithLabelValues("refused", protocol.ErrorReason_NOT_READY.String()).Inc()
+		return hash, NewRequestError(protocol.ErrorReason_NOT_READY, nil, "node is shutting down")
+	}
+	// new requests are only taken once the node can get them replicated and signed
+	if info == nil {
+		if err := c.Readiness.Check(SubsystemReplication, SubsystemSigning); err != nil {
+			log.Infof("Refusing CSR: %v", err)
+			metrics.CSRs.WithLabelValues("refused", protocol.ErrorReason_NOT_READY.String()).Inc()
+			return hash, NewRequestError(protocol.ErrorReason_NOT_READY, err, "node isn't ready")
+		}
 	}
 
+	switch {
+	case info == nil:
+		if err := c.admission.admit(c.ReplicationQueue, csr.ClientID); err != nil {
+			log.Infof("Refusing CSR: %v", err)
+			metrics.CSRs.WithLabelValues("refused", protocol.ErrorReason_OVERLOADED.String()).Inc()
+			return hash, err
+		}
+
+		info = newRequestInfo(csr)
+		info.Received = true
+		info.Submitted = time.Now()
+		info.span = c.startRequestSpan(ctx, hash, csr)
+		c.Database[hash] = info
+		c.hold(ctx, hash, info)
+		c.emit(hash, info, &protocol.RequestEvent{Message: "queued for replication"})
+
+		c.ReplicationQueue.Push(csr)
+
+		log.Info("Added CSR to Replication Queue")
+
+	case info.Replicated && info.Err != nil:
+		// the request has already been replicated and only the signing session failed so it is signed again
+		if err := c.admission.take(csr.ClientID); err != nil {
+			log.Infof("Refusing CSR: %v", err)
+			metrics.CSRs.WithLabelValues("refused", protocol.ErrorReason_OVERLOADED.String()).Inc()
+			return hash, err
+		}
+
+		select {
+		case c.SigningQueue <- csr:
+		default:
+			metrics.CSRs.WithLabelValues("refused", protocol.ErrorReason_OVERLOADED.String()).Inc()
+			return hash, &OverloadedError{Reason: "signing queue is full", RetryAfter: c.admission.retryAfter}
+		}
+
+		info.Err = nil
+		info.Received = true
+		info.Completed = time.Time{}
+		info.done = make(chan struct{})
+		info.holders = 0
+		info.span = c.startRequestSpan(ctx, hash, csr)
+		c.hold(ctx, hash, info)
+		c.emit(hash, info, &protocol.RequestEvent{Message: "queued for signing"})
+
+		log.Info("Added CSR to Signing Queue again")
+
+	default:
+		// the request is in flight, issued or rejected; a retry must not replicate and sign it again
+		if !info.finished() {
+			c.hold(ctx, hash, info)
+		}
+		log.Info("CSR is already known; attaching to existing request")
+	}
+
+	return hash, nil
+}
+
+// Finish records the outcome of the signing session of a request and wakes up everyone waiting for it.
+func (c *Coordinator) Finish(hash string, cert *x509.Certificate, err error) {
 	c.Mut.Lock()
-	c.Database[hash] = info
-	c.Mut.Unlock()
+	defer c.Mut.Unlock()
+
+	info := c.Database[hash]
+	if info == nil {
+		c.logFor(hash).Info("Couldn't find CSR in database.")
+		return
+	}
+
+	// the certificate is handed to every node, including the one that issued it
+	if info.finished() && info.Certificate != nil {
+		return
+	}
+
+	if err != nil {
+		info.Err = err
+		metrics.FailedRequests.WithLabelValues(ReasonOf(err).String()).Inc()
+		c.audited(c.Audit.Failed(hash, ReasonOf(err).String()))
+	} else {
+		info.Certificate = cert
+		info.Signed = true
+		info.Err = nil
+		c.audited(c.Audit.Issued(hash, cert.SerialNumber))
+		c.CTLog.Submit(cert)
+	}
+
+	info.finish()
+
+	if err != nil {
+		c.emit(hash, info, &protocol.RequestEvent{Message: fmt.Sprintf("signing failed: %v", err)})
+	} else {
+		c.emit(hash, info, &protocol.RequestEvent{Message: "certificate issued"})
+	}
+}
+
+// Wait blocks until the request identified by hash has been rejected or signed, or ctx is done.
+func (c *Coordinator) Wait(ctx context.Context, hash string) (RequestInfo, error) {
+	for {
+		c.Mut.Lock()
+		info := c.Database[hash]
+		if info == nil {
+			c.Mut.Unlock()
+			return RequestInfo{}, fmt.Errorf("unknown request %v", hash)
+		}
+		if info.finished() {
+			defer c.Mut.Unlock()
+			return *info, nil
+		}
+		// a failed request that is signed again gets a new done channel, so it is read under the lock and
+		// checked again once it has been closed
+		done := info.done
+		c.Mut.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return RequestInfo{}, ctx.Err()
+		}
+	}
+}
+
+// Lookup returns a copy of the stored information about a request.
+func (c *Coordinator) Lookup(hash string) (RequestInfo, bool) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	info := c.Database[hash]
+	if info == nil {
+		return RequestInfo{}, false
+	}
+	return *info, true
+}
+
+// Requests returns a copy of the stored information about all requests, keyed by the hash of their CSR.
+func (c *Coordinator) Requests() map[string]RequestInfo {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	requests := make(map[string]RequestInfo, len(c.Database))
+	for hash, info := range c.Database {
+		requests[hash] = *info
+	}
+	return requests
+}
+
+// MarkReturned records that the certificate of a request has been handed to the client.
+func (c *Coordinator) MarkReturned(hash string) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	if info := c.Database[hash]; info != nil {
+		info.Returned = true
+	}
 }
 
 // Implements the CommandQueue for HotStuff to get next requests to replicate
@@ -130,13 +462,25 @@ func (c *Coordinator) Get(ctx context.Context) (cmd hotstuff.Command, ok bool) {
 
 	// This will probably lock?
 
-	var csr *protocol.CSR
-
-	select {
-	case csr = <-c.ReplicationQueue:
-	case <-ctx.Done():
+	if ctx.Err() != nil {
 		return "", false
-	default: // default makes this non-blocking (https://gobyexample.com/non-blocking-channel-operations)
+	}
+
+	// non-blocking; clients with pending requests take turns
+	csr := c.ReplicationQueue.Pop()
+
+	// don't propose a request again that has already been replicated through another node
+	if csr != nil {
+		hash := HashCSR(csr)
+		c.Mut.Lock()
+		if _, ok := c.replicated[hash]; ok {
+			csr = nil
+		} else if info := c.Database[hash]; info != nil {
+			// from now on the request can't be withdrawn anymore
+			info.Proposed = true
+			c.emit(hash, info, &protocol.RequestEvent{Message: "proposed for replication"})
+		}
+		c.Mut.Unlock()
 	}
 
 	bytes, err := c.Marshaler.Marshal(csr)
@@ -173,37 +517,81 @@ func (c *Coordinator) Accept(cmd hotstuff.Command) bool {
 	}
 
 	hash := HashCSR(csr)
+	view := c.currentView()
+	log := logging.With(c.logFor(hash), "view", view)
+
+	log.Info("Validating CSR")
+
+	_, span := tracing.Start(context.Background(), hash, "accept",
+		trace.WithAttributes(attribute.Int64("hotstuff.view", int64(view))))
+	defer span.End()
 
 	// get certificate so that it can be validated
-	_, err = x509.ParseCertificateRequest(csr.CertificateRequest)
-	if err != nil {
-		c.Log.Error(err)
+	rejection := c.Validate(csr)
+	validated := rejection == nil
+	if !validated {
+		log.Error(rejection)
+		span.RecordError(rejection)
+		span.SetStatus(otelcodes.Error, ReasonOf(rejection).String())
 	}
 
-	c.Log.Infof("Validating CSR %v", hash[:6])
-	validated := true
-
 	c.Mut.Lock()
 	defer c.Mut.Unlock()
 
+	// every replica has executed the command already so all of them reject the replay, even if they have
+	// collected the request in the meantime
+	if _, ok := c.replicated[hash]; ok {
+		log.Info("Rejecting replayed CSR")
+		return false
+	}
+
 	if c.Database[hash] == nil {
-		c.Log.Info("Adding to database")
-		info := &RequestInfo{
-			CSR:        csr,
-			Received:   false,
-			Validated:  validated,
-			Proposed:   false,
-			Replicated: false,
-			Signed:     false,
-			Returned:   false,
-		}
-		c.Database[hash] = info
+		log.Info("Adding to database")
+		c.Database[hash] = newRequestInfo(csr)
+		c.Database[hash].Deadline = time.Now().Add(c.requestTimeout)
+	}
 
+	info := c.Database[hash]
+	info.Validated = validated
+
+	if !validated {
+		metrics.CSRs.WithLabelValues("rejected", ReasonOf(rejection).String()).Inc()
+		c.audited(c.Audit.Rejected(hash, ReasonOf(rejection).String()))
+		info.Rejected = true
+		info.Err = rejection
+		info.finish()
+		c.emit(hash, info, &protocol.RequestEvent{Message: fmt.Sprintf("rejected by replica: %v", rejection)})
+		return false
 	}
 
-	c.Database[hash].Validated = validated
+	metrics.CSRs.WithLabelValues("accepted", protocol.ErrorReason_NONE.String()).Inc()
+	c.emit(hash, info, &protocol.RequestEvent{Message: "accepted by replica"})
 
-	return !c.Database[hash].Proposed
+	return true
+}
+
+// Validate checks that the CSR is well-formed, that it has been signed with the key it contains and that the policy
+// allows to sign it. It returns a *RequestError telling why the CSR isn't acceptable, or nil.
+// All replicas have to come to the same decision, so the policy must be deterministic.
+func (c *Coordinator) Validate(csr *protocol.CSR) error {
+	req, err := x509.ParseCertificateRequest(csr.CertificateRequest)
+	if err != nil {
+		return NewRequestError(protocol.ErrorReason_INVALID_CSR, err, "malformed certificate request")
+	}
+	// proof that the client owns the private key
+	err = req.CheckSignature()
+	if err != nil {
+		return NewRequestError(protocol.ErrorReason_INVALID_CSR, err, "invalid signature on certificate request")
+	}
+
+	if c.Policy != nil {
+		err = c.Policy(csr, req)
+		if err != nil {
+			return NewRequestError(protocol.ErrorReason_POLICY_REJECTED, err, "certificate request violates policy")
+		}
+	}
+
+	return nil
 }
 
 // Tells the coordinator that the request/batch of requests have succesfully been proposed to other nodes
@@ -254,28 +642,106 @@ func (c *Coordinator) Exec(cmd hotstuff.Command) {
 	}
 
 	hash := HashCSR(csr)
+	view := c.currentView()
+	log := logging.With(c.logFor(hash), "view", view)
+
+	_, span := tracing.Start(context.Background(), hash, "exec",
+		trace.WithAttributes(attribute.Int64("hotstuff.view", int64(view))))
+	defer span.End()
 
 	c.Mut.Lock()
 	defer c.Mut.Unlock()
 
+	// all replicas execute the same commands in the same order, so each of them is recorded, including those that
+	// are ignored below, to keep the commit digest the same on all replicas
+	c.audited(c.Audit.Committed(hash))
+	c.commits++
+	c.forgetReplicated()
+
+	// the same CSR might have been committed twice if it was proposed again before the first commit;
+	// since all replicas execute in the same order they all ignore the second one
+	if _, ok := c.replicated[hash]; ok {
+		log.Info("CSR has already been replicated.")
+		return
+	}
+	c.replicated[hash] = c.commits
+	c.replayed = append(c.replayed, hash)
+
 	reqInfo := c.Database[hash]
 	if reqInfo == nil {
-		c.Log.Infof("Couldn't find CSR %v in database.", hash[:6])
+		log.Info("Couldn't find CSR in database.")
 		return
 	}
 
-	// if this is server handling client request then initiates signing sesshion
-	if reqInfo.Received {
-		c.Log.Info("Replication finished.")
-
-		// TODO: make this channel send non-blocking with select+default
-		c.SigningQueue <- csr
+	reqInfo.Replicated = true
+	reqInfo.Committed = time.Now()
+
+	c.emit(hash, reqInfo, &protocol.RequestEvent{
+		Message: fmt.Sprintf("committed in view %v", view),
+		View:    uint64(view),
+	})
+
+	// if this is server handling client request then initiates signing sesshion;
+	// nobody is interested in the certificate anymore if the request has expired in the meantime
+	if reqInfo.Received && !reqInfo.finished() {
+		log.Info("Replication finished.")
+
+		metrics.ObserveStage(metrics.StageCommit, reqInfo.Submitted)
+
+		// execution mustn't wait for the signing sessions while holding the lock; a request that doesn't fit into
+		// the queue fails and is queued for signing again when the client retries
+		select {
+		case c.SigningQueue <- csr:
+		default:
+			log.Warn("Signing queue is full")
+			reqInfo.Err = &OverloadedError{Reason: "signing queue is full", RetryAfter: c.admission.retryAfter}
+			metrics.FailedRequests.WithLabelValues(protocol.ErrorReason_OVERLOADED.String()).Inc()
+			c.audited(c.Audit.Failed(hash, protocol.ErrorReason_OVERLOADED.String()))
+			reqInfo.finish()
+			c.emit(hash, reqInfo, &protocol.RequestEvent{Message: "signing queue is full"})
+		}
 	}
-	c.Database[hash].Replicated = true
 }
 
 // helper functions
 
+// startRequestSpan starts the span covering a request from its submission until it has been completed.
+func (c *Coordinator) startRequestSpan(ctx context.Context, hash string, csr *protocol.CSR) trace.Span {
+	_, span := tracing.Start(ctx, hash, "request",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.Int64("hotcertification.client_id", int64(csr.ClientID))))
+	return span
+}
+
+// Consensus returns the current view of HotStuff and its leader; both are zero until HotStuff has been started.
+func (c *Coordinator) Consensus() (view hotstuff.View, leader hotstuff.ID) {
+	if c.HS == nil {
+		return 0, 0
+	}
+	view = c.HS.ViewSynchronizer().View()
+	return view, c.HS.LeaderRotation().GetLeader(view)
+}
+
+func (c *Coordinator) currentView() hotstuff.View {
+	if c.HS == nil {
+		return 0
+	}
+	return c.HS.ViewSynchronizer().View()
+}
+
+// audited logs the error of writing to the audit log. A replica that can't keep its audit trail still takes part
+// in consensus; a missing commit shows as a gap when the log is verified.
+func (c *Coordinator) audited(err error) {
+	if err != nil {
+		c.Log.Errorf("Failed to write audit log: %v", err)
+	}
+}
+
+// logFor returns the logger for messages about the request identified by hash.
+func (c *Coordinator) logFor(hash string) logging.Logger {
+	return logging.With(c.Log, "csr", hash[:6])
+}
+
 func HashCSR(csr *protocol.CSR) string {
 	h := sha256.New()
 	h.Write([]byte(fmt.Sprintf("%v", csr.ClientID)))
@@ -0,0 +1,305 @@
+package hotcertification_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/relab/hotstuff"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/audit"
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+func newTestCSR() *protocol.CSR {
+	clientPrivKey, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		panic(fmt.Sprintf("%v", err))
+	}
+
+	csr, err := crypto.GenerateCSR(clientPrivKey)
+	if err != nil {
+		panic(fmt.Sprintf("%v", err))
+	}
+
+	return &protocol.CSR{
+		ClientID:           8,
+		CertificateRequest: csr.Raw,
+		ValidationInfo:     make([]byte, 100),
+	}
+}
+
+// add submits csr and fails the test if it hasn't been admitted.
+func add(t *testing.T, c *hc.Coordinator, csr *protocol.CSR) string {
+	hash, err := c.AddRequest(context.Background(), csr)
+	if err != nil {
+		t.Fatalf("request has been refused: %v", err)
+	}
+	return hash
+}
+
+// replicate runs the command of a queued request through Accept and Exec like HotStuff would.
+func replicate(t *testing.T, c *hc.Coordinator) hotstuff.Command {
+	cmd, ok := c.Get(context.Background())
+	if !ok || cmd == "" {
+		t.Fatalf("no command to replicate")
+	}
+	if !c.Accept(cmd) {
+		t.Fatalf("command has been rejected")
+	}
+	c.Exec(cmd)
+	return cmd
+}
+
+func TestResubmitAfterTimeout(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	csr := newTestCSR()
+
+	hash := add(t, c, csr)
+
+	// the client gives up before the certificate has been issued
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Wait(ctx, hash); err != context.DeadlineExceeded {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+
+	// and tries again
+	if retry := add(t, c, csr); retry != hash {
+		t.Errorf("resubmission got a different request ID")
+	}
+	if c.ReplicationQueue.Len() != 1 {
+		t.Errorf("resubmission was queued for replication again")
+	}
+
+	replicate(t, c)
+	if len(c.SigningQueue) != 1 {
+		t.Fatalf("expected exactly one signing session, got %v", len(c.SigningQueue))
+	}
+
+	cert := &x509.Certificate{Raw: []byte{1}}
+	c.Finish(hash, cert, nil)
+
+	info, err := c.Wait(context.Background(), hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Certificate != cert {
+		t.Errorf("retry didn't get the issued certificate")
+	}
+}
+
+func TestResubmitIssued(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	csr := newTestCSR()
+
+	hash := add(t, c, csr)
+	replicate(t, c)
+	<-c.SigningQueue
+	c.Finish(hash, &x509.Certificate{Raw: []byte{1}}, nil)
+
+	add(t, c, csr)
+	if c.ReplicationQueue.Len() != 0 || len(c.SigningQueue) != 0 {
+		t.Errorf("issued request has been processed again")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	info, err := c.Wait(ctx, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Certificate == nil {
+		t.Errorf("expected stored certificate")
+	}
+}
+
+func TestResubmitAfterFailedSigning(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	csr := newTestCSR()
+
+	hash := add(t, c, csr)
+	replicate(t, c)
+	<-c.SigningQueue
+	c.Finish(hash, nil, fmt.Errorf("failed to get enough partial signatures"))
+
+	// the request has already been replicated so a retry only repeats the signing session
+	add(t, c, csr)
+	if c.ReplicationQueue.Len() != 0 {
+		t.Errorf("failed request has been queued for replication again")
+	}
+	if len(c.SigningQueue) != 1 {
+		t.Errorf("failed request hasn't been queued for signing again")
+	}
+}
+
+func TestReplayedCommandRejected(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	add(t, c, newTestCSR())
+
+	cmd := replicate(t, c)
+	if c.Accept(cmd) {
+		t.Errorf("replayed command has been accepted")
+	}
+
+	// a replica that only learned about the request through replication rejects the replay as well
+	other := hc.NewCoordinator(&hc.Options{})
+	if !other.Accept(cmd) {
+		t.Fatalf("command has been rejected")
+	}
+	other.Exec(cmd)
+	if other.Accept(cmd) {
+		t.Errorf("replayed command has been accepted by other replica")
+	}
+}
+
+func TestRejectionReason(t *testing.T) {
+	malformed := newTestCSR()
+	malformed.CertificateRequest = malformed.CertificateRequest[:10]
+
+	tampered := newTestCSR()
+	tampered.CertificateRequest = append([]byte{}, tampered.CertificateRequest...)
+	tampered.CertificateRequest[len(tampered.CertificateRequest)-1] ^= 0xff
+
+	for _, test := range []struct {
+		name   string
+		csr    *protocol.CSR
+		policy func(*protocol.CSR, *x509.CertificateRequest) error
+		reason protocol.ErrorReason
+	}{
+		{"malformed", malformed, nil, protocol.ErrorReason_INVALID_CSR},
+		{"bad signature", tampered, nil, protocol.ErrorReason_INVALID_CSR},
+		{"policy", newTestCSR(), func(*protocol.CSR, *x509.CertificateRequest) error {
+			return fmt.Errorf("no certificates for this subject")
+		}, protocol.ErrorReason_POLICY_REJECTED},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := hc.NewCoordinator(&hc.Options{})
+			c.Policy = test.policy
+
+			if reason := hc.ReasonOf(c.Validate(test.csr)); reason != test.reason {
+				t.Errorf("Validate: got reason %v, want %v", reason, test.reason)
+			}
+
+			// the replicas record the reason of a rejection for the client
+			hash := add(t, c, test.csr)
+			cmd, _ := c.Get(context.Background())
+			if c.Accept(cmd) {
+				t.Fatalf("command has been accepted")
+			}
+			info, err := c.Wait(context.Background(), hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !info.Rejected || hc.ReasonOf(info.Err) != test.reason {
+				t.Errorf("Accept: got reason %v, want %v", hc.ReasonOf(info.Err), test.reason)
+			}
+		})
+	}
+}
+
+// TestWaitDuringRetry checks that clients that wait for a request while it is signed again get the outcome of a
+// signing session, never the state in between.
+func TestWaitDuringRetry(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	csr := newTestCSR()
+
+	hash := add(t, c, csr)
+	replicate(t, c)
+	<-c.SigningQueue
+
+	cert := &x509.Certificate{Raw: []byte{1}}
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			for {
+				info, err := c.Wait(context.Background(), hash)
+				switch {
+				case err != nil:
+					errs <- err
+					return
+				case info.Certificate == cert:
+					errs <- nil
+					return
+				case info.Err == nil:
+					errs <- fmt.Errorf("waiter got request without error or certificate in state %v", info.State())
+					return
+				}
+			}
+		}()
+	}
+
+	// every signing session but the last one fails
+	for i := 0; i < 100; i++ {
+		c.Finish(hash, nil, fmt.Errorf("failed to get enough partial signatures"))
+		add(t, c, csr)
+		<-c.SigningQueue
+	}
+	c.Finish(hash, cert, nil)
+
+	for i := 0; i < cap(errs); i++ {
+		select {
+		case err := <-errs:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("waiter didn't get the certificate")
+		}
+	}
+}
+
+// TestCommitDigest checks that replicas record every command they execute in their audit log, even those they
+// ignore, so that they agree on the commit digest.
+func TestCommitDigest(t *testing.T) {
+	replica := func(name string) *hc.Coordinator {
+		c := hc.NewCoordinator(&hc.Options{})
+		l, err := audit.Open(filepath.Join(t.TempDir(), name), 3, []byte("config"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { l.Close() })
+		c.Audit = l
+		return c
+	}
+	leader, other := replica("leader.log"), replica("other.log")
+
+	add(t, leader, newTestCSR())
+	cmd, _ := leader.Get(context.Background())
+	if !leader.Accept(cmd) {
+		t.Fatal("command has been rejected")
+	}
+	unknown, err := leader.Marshaler.Marshal(newTestCSR())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the other replica hasn't accepted the command, and both of them execute it twice
+	for _, c := range []*hc.Coordinator{leader, other} {
+		c.Exec(cmd)
+		c.Exec(cmd)
+		c.Exec(hotstuff.Command(unknown))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	want, err := leader.Audit.CommitDigest(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := other.Audit.CommitDigest(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("replicas disagree on the commit digest")
+	}
+}
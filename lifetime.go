@@ -0,0 +1,152 @@
+package hotcertification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/raphasch/hotcertification/metrics"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// defaults for the lifetime of requests if they aren't set in the config
+const (
+	defaultRequestTimeout = time.Minute
+	defaultRetention      = time.Hour
+	defaultReplayWindow   = 100000 // commits
+	gcInterval            = time.Second
+)
+
+// ErrExpired is the error of a request that hasn't been completed before its deadline.
+var ErrExpired = errors.New("request expired before its certificate was issued")
+
+// ErrCollected is the error of a request that is submitted again after it has been completed and collected.
+var ErrCollected = errors.New("request has been completed and is no longer kept by the node")
+
+func durationOrDefault(ms int, def time.Duration) time.Duration {
+	if ms <= 0 {
+		return def
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// hold registers a submission of a request. The deadline of the request is extended to the deadline of ctx
+// (but no further than the request timeout) and the request is withdrawn once all submissions have been cancelled
+// while it still waits to be proposed. The caller must hold c.Mut.
+func (c *Coordinator) hold(ctx context.Context, hash string, info *RequestInfo) {
+	deadline := time.Now().Add(c.requestTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if deadline.After(info.Deadline) {
+		info.Deadline = deadline
+	}
+
+	info.holders++
+
+	// submissions that can't be cancelled hold the request until it expires
+	if ctx.Done() == nil {
+		return
+	}
+
+	done := info.done
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+
+		c.Mut.Lock()
+		defer c.Mut.Unlock()
+
+		info.holders--
+		if info.holders > 0 || info.finished() || c.Database[hash] != info {
+			return
+		}
+		if c.withdraw(hash, info, ctx.Err()) {
+			c.logFor(hash).Infof("Withdrew CSR: %v", ctx.Err())
+		}
+	}()
+}
+
+// withdraw removes a request that hasn't been proposed yet from the replication queue and the database
+// and completes it with err. It returns false if the request has already been proposed. The caller must hold c.Mut.
+func (c *Coordinator) withdraw(hash string, info *RequestInfo, err error) bool {
+	if info.Proposed || !c.ReplicationQueue.Remove(info.CSR) {
+		return false
+	}
+
+	info.Err = err
+	info.finish()
+	c.emit(hash, info, &protocol.RequestEvent{Message: "withdrawn: " + err.Error()})
+
+	delete(c.Database, hash)
+	return true
+}
+
+// Collect expires the requests whose deadline has passed and removes the completed requests that have been
+// kept longer than the retention period. Since every replica collects on its own clock, whether a CSR has been
+// replicated is remembered apart from that for the replay window; see forgetReplicated.
+func (c *Coordinator) Collect(now time.Time) {
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	for hash, info := range c.Database {
+		if info.finished() {
+			if now.Sub(info.Completed) > c.retention {
+				delete(c.Database, hash)
+			}
+			continue
+		}
+
+		if info.Deadline.IsZero() || now.Before(info.Deadline) {
+			continue
+		}
+
+		c.logFor(hash).Info("CSR expired")
+
+		var expired error
+		if info.Replicated {
+			expired = NewRequestError(protocol.ErrorReason_INSUFFICIENT_SHARES, ErrExpired, "signing session didn't finish in time")
+		} else {
+			expired = NewRequestError(protocol.ErrorReason_CONSENSUS_TIMEOUT, ErrExpired, "request hasn't been replicated in time")
+		}
+		metrics.FailedRequests.WithLabelValues(ReasonOf(expired).String()).Inc()
+		c.audited(c.Audit.Failed(hash, ReasonOf(expired).String()))
+
+		// once proposed the request can't be taken back, so the entry is kept in case it is committed after all
+		if c.withdraw(hash, info, expired) {
+			continue
+		}
+
+		info.Err = expired
+		info.finish()
+		c.emit(hash, info, &protocol.RequestEvent{Message: "expired"})
+	}
+}
+
+// forgetReplicated forgets the CSRs that have been committed more than the replay window of commits ago. All
+// replicas forget them after the same commit, so they all ignore a second commit of a CSR within the window and
+// all of them sign it again after it. The caller must hold c.Mut.
+func (c *Coordinator) forgetReplicated() {
+	for len(c.replayed) > 0 && c.commits-c.replicated[c.replayed[0]] >= c.replayWindow {
+		delete(c.replicated, c.replayed[0])
+		c.replayed = c.replayed[1:]
+	}
+}
+
+// RunGC calls Collect periodically until ctx is cancelled.
+func (c *Coordinator) RunGC(ctx context.Context) {
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			c.Collect(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package hotcertification_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// eventually polls cond until it holds or a second has passed.
+func eventually(t *testing.T, cond func() bool) bool {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if cond() {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCancelWithdrawsQueuedRequest(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hash, err := c.AddRequest(ctx, newTestCSR())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	if !eventually(t, func() bool { return c.ReplicationQueue.Len() == 0 }) {
+		t.Fatalf("cancelled request is still queued")
+	}
+	if _, ok := c.Lookup(hash); ok {
+		t.Errorf("cancelled request is still stored")
+	}
+}
+
+func TestCancelKeepsRequestOfOtherSubmission(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+	csr := newTestCSR()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hash, err := c.AddRequest(ctx, csr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// an asynchronous submission of the same CSR is still interested in it
+	add(t, c, csr)
+	cancel()
+
+	time.Sleep(10 * time.Millisecond)
+	if c.ReplicationQueue.Len() != 1 {
+		t.Errorf("request has been withdrawn although it is still wanted")
+	}
+	if _, ok := c.Lookup(hash); !ok {
+		t.Errorf("request has been removed although it is still wanted")
+	}
+}
+
+func TestCancelAfterProposal(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hash, err := c.AddRequest(ctx, newTestCSR())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd, _ := c.Get(context.Background())
+	cancel()
+
+	// the proposal can't be taken back so the request is processed like any other
+	time.Sleep(10 * time.Millisecond)
+	if !c.Accept(cmd) {
+		t.Fatalf("command has been rejected")
+	}
+	c.Exec(cmd)
+	if len(c.SigningQueue) != 1 {
+		t.Errorf("committed request hasn't been queued for signing")
+	}
+	if info, _ := c.Lookup(hash); info.State() != protocol.RequestState_REPLICATED {
+		t.Errorf("unexpected state %v", info.State())
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{RequestTimeout: 50})
+
+	// the requests of a single client are proposed in order
+	proposed := add(t, c, newTestCSR())
+	queued := add(t, c, newTestCSR())
+	c.Get(context.Background())
+
+	c.Collect(time.Now())
+	if _, ok := c.Lookup(queued); !ok {
+		t.Fatalf("request expired before its deadline")
+	}
+
+	c.Collect(time.Now().Add(time.Second))
+
+	if _, ok := c.Lookup(queued); ok || c.ReplicationQueue.Len() != 0 {
+		t.Errorf("expired request hasn't been withdrawn")
+	}
+
+	info, ok := c.Lookup(proposed)
+	if !ok {
+		t.Fatalf("expired request that has already been proposed has been removed")
+	}
+	if !errors.Is(info.Err, hc.ErrExpired) || hc.ReasonOf(info.Err) != protocol.ErrorReason_CONSENSUS_TIMEOUT {
+		t.Errorf("expected consensus timeout, got %v", info.Err)
+	}
+	if _, err := c.Wait(context.Background(), proposed); err != nil {
+		t.Errorf("waiting for expired request: %v", err)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{Retention: 1000})
+
+	hash := add(t, c, newTestCSR())
+	replicate(t, c)
+	<-c.SigningQueue
+	c.Finish(hash, &x509.Certificate{Raw: []byte{1}}, nil)
+
+	c.Collect(time.Now())
+	if _, ok := c.Lookup(hash); !ok {
+		t.Fatalf("completed request removed before the end of the retention period")
+	}
+
+	c.Collect(time.Now().Add(2 * time.Second))
+	if _, ok := c.Lookup(hash); ok {
+		t.Errorf("completed request kept after the end of the retention period")
+	}
+}
+
+// TestReplayAfterRetention checks that a replica rejects a replayed CSR and ignores a second commit of it after it
+// has collected the request, like the replicas that haven't collected it yet.
+func TestReplayAfterRetention(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{Retention: 1000})
+
+	csr := newTestCSR()
+	hash := add(t, c, csr)
+	cmd := replicate(t, c)
+	<-c.SigningQueue
+	c.Finish(hash, &x509.Certificate{Raw: []byte{1}}, nil)
+	c.Collect(time.Now().Add(2 * time.Second))
+
+	if c.Accept(cmd) {
+		t.Errorf("replayed command has been accepted after the request has been collected")
+	}
+	c.Exec(cmd)
+	select {
+	case <-c.SigningQueue:
+		t.Errorf("request has been signed again after it has been committed twice")
+	default:
+	}
+
+	_, err := c.AddRequest(context.Background(), csr)
+	if !errors.Is(err, hc.ErrCollected) || hc.ReasonOf(err) != protocol.ErrorReason_INVALID_CSR {
+		t.Errorf("expected collected request to be refused, got %v", err)
+	}
+	if _, ok := c.Lookup(hash); ok {
+		t.Errorf("collected request has been added again")
+	}
+}
+
+// TestReplayWindow checks that a replica only remembers a replicated CSR for the replay window of commits.
+func TestReplayWindow(t *testing.T) {
+	c := hc.NewCoordinator(&hc.Options{Retention: 1000, ReplayWindow: 2})
+
+	csr := newTestCSR()
+	hash := add(t, c, csr)
+	replicate(t, c)
+	<-c.SigningQueue
+	c.Finish(hash, &x509.Certificate{Raw: []byte{1}}, nil)
+	c.Collect(time.Now().Add(2 * time.Second))
+
+	add(t, c, newTestCSR())
+	replicate(t, c)
+	<-c.SigningQueue
+	if _, err := c.AddRequest(context.Background(), csr); !errors.Is(err, hc.ErrCollected) {
+		t.Errorf("expected replay within the window to be refused, got %v", err)
+	}
+
+	add(t, c, newTestCSR())
+	replicate(t, c)
+	<-c.SigningQueue
+	if _, err := c.AddRequest(context.Background(), csr); err != nil {
+		t.Errorf("CSR has been refused after the replay window: %v", err)
+	}
+}
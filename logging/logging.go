@@ -1,12 +1,15 @@
 package logging
 
 import (
+	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/mattn/go-isatty"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Logger is the logging interface used by hotstuff. It is based on zap.SugaredLogger
@@ -27,34 +30,166 @@ type Logger interface {
 	Warnf(template string, args ...interface{})
 }
 
-// New returns a new logger with the given name.
-func New(name string) Logger {
-	var config zap.Config
-	if strings.ToLower(os.Getenv("HOTSTUFF_LOG_TYPE")) == "json" {
-		config = zap.NewProductionConfig()
-	} else {
-		config = zap.NewDevelopmentConfig()
-		if isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd()) {
-			config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+// Config configures where the logs of a node go and how verbose each component is.
+type Config struct {
+	Level      string            `mapstructure:"level"`       // level of components without an own level; "debug", "info", "warn" or "error"
+	Format     string            `mapstructure:"format"`      // "console" or "json"
+	File       string            `mapstructure:"file"`        // logs are written to stderr if empty
+	MaxSize    int               `mapstructure:"max-size"`    // in megabytes; the file is rotated once it is this large
+	MaxBackups int               `mapstructure:"max-backups"` // number of rotated files that are kept; all if zero
+	MaxAge     int               `mapstructure:"max-age"`     // in days; rotated files are removed once they are this old; never if zero
+	Levels     map[string]string `mapstructure:"levels"`      // levels of single components, by the name of their logger
+}
+
+// the pipeline all loggers write to
+var (
+	mut          sync.Mutex
+	encoder      zapcore.Encoder
+	sink         zapcore.WriteSyncer = zapcore.Lock(os.Stderr)
+	defaultLevel                     = zap.NewAtomicLevelAt(zap.ErrorLevel)
+	levels                           = make(map[string]zap.AtomicLevel)
+	fields       []interface{}
+)
+
+func init() {
+	// without a config the environment decides, as it does for hotstuff
+	encoder = newEncoder(os.Getenv("HOTSTUFF_LOG_TYPE"), isTerminal())
+	if level, err := parseLevel(os.Getenv("HOTSTUFF_LOG")); err == nil {
+		defaultLevel.SetLevel(level)
+	}
+}
+
+// Configure sets up the pipeline from the config. The fields are added to every log entry, e.g. the ID of the node.
+// Loggers created before write to the previous output, but their levels are updated.
+func Configure(cfg Config, contextFields ...interface{}) error {
+	level := defaultLevel.Level()
+	if cfg.Level != "" {
+		var err error
+		level, err = parseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+	}
+
+	componentLevels := make(map[string]zapcore.Level, len(cfg.Levels))
+	for name, l := range cfg.Levels {
+		level, err := parseLevel(l)
+		if err != nil {
+			return fmt.Errorf("level of logger '%v': %w", name, err)
 		}
+		componentLevels[name] = level
+	}
+
+	format := cfg.Format
+	switch strings.ToLower(format) {
+	case "":
+		format = os.Getenv("HOTSTUFF_LOG_TYPE")
+	case "console", "json":
+	default:
+		return fmt.Errorf("unknown log format '%v'", cfg.Format)
+	}
+
+	mut.Lock()
+	defer mut.Unlock()
+
+	defaultLevel.SetLevel(level)
+	for _, l := range levels {
+		l.SetLevel(level)
+	}
+	for name, l := range componentLevels {
+		componentLevel(name).SetLevel(l)
+	}
+
+	// no colors in files
+	encoder = newEncoder(format, cfg.File == "" && isTerminal())
+
+	if cfg.File != "" {
+		sink = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+		})
+	} else {
+		sink = zapcore.Lock(os.Stderr)
 	}
-	l, err := config.Build()
+
+	fields = contextFields
+	return nil
+}
+
+// New returns a new logger with the given name. Its level is the one configured for the name, if any,
+// and can be changed with SetLevel.
+func New(name string) Logger {
+	mut.Lock()
+	defer mut.Unlock()
+
+	core := zapcore.NewCore(encoder.Clone(), sink, componentLevel(name))
+	l := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zap.DPanicLevel))
+	return l.Sugar().Named(name).With(fields...)
+}
+
+// With returns a logger that adds the key-value pairs to every entry, e.g. "csr", hash[:6].
+// Loggers that don't support fields are returned unchanged.
+func With(logger Logger, keysAndValues ...interface{}) Logger {
+	if l, ok := logger.(*zap.SugaredLogger); ok {
+		return l.With(keysAndValues...)
+	}
+	return logger
+}
+
+// SetLevel changes the level of the component with the given name while it is running.
+func SetLevel(name, level string) error {
+	l, err := parseLevel(level)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	switch strings.ToLower(os.Getenv("HOTSTUFF_LOG")) {
-	case "1":
-		fallthrough
-	case "debug":
-		config.Level.SetLevel(zap.DebugLevel)
+
+	mut.Lock()
+	defer mut.Unlock()
+	componentLevel(name).SetLevel(l)
+	return nil
+}
+
+// componentLevel returns the level of the component with the given name and creates it if it doesn't exist yet.
+// The caller must hold mut.
+func componentLevel(name string) zap.AtomicLevel {
+	name = strings.ToLower(name)
+	level, ok := levels[name]
+	if !ok {
+		level = zap.NewAtomicLevelAt(defaultLevel.Level())
+		levels[name] = level
+	}
+	return level
+}
+
+func newEncoder(format string, colored bool) zapcore.Encoder {
+	if strings.ToLower(format) == "json" {
+		return zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	}
+
+	config := zap.NewDevelopmentEncoderConfig()
+	if colored {
+		config.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	return zapcore.NewConsoleEncoder(config)
+}
+
+func isTerminal() bool {
+	return isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd())
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(level) {
+	case "1", "debug":
+		return zap.DebugLevel, nil
 	case "info":
-		config.Level.SetLevel(zap.InfoLevel)
+		return zap.InfoLevel, nil
 	case "warn":
-		config.Level.SetLevel(zap.WarnLevel)
+		return zap.WarnLevel, nil
 	case "error":
-		fallthrough
+		return zap.ErrorLevel, nil
 	default:
-		config.Level.SetLevel(zap.ErrorLevel)
+		return zap.ErrorLevel, fmt.Errorf("unknown log level '%v'", level)
 	}
-	return l.Sugar().Named(name)
 }
@@ -0,0 +1,83 @@
+package logging_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raphasch/hotcertification/logging"
+)
+
+func TestConfigure(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "node.log")
+
+	err := logging.Configure(logging.Config{
+		Level:  "info",
+		Format: "json",
+		File:   file,
+		Levels: map[string]string{"quiet": "error", "verbose": "debug"},
+	}, "node", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logging.New("quiet").Info("dropped")
+	logging.New("verbose").Debug("kept")
+	logging.With(logging.New("other"), "csr", "abcdef").Info("request")
+	logging.New("other").Debug("dropped")
+
+	if err := logging.SetLevel("quiet", "info"); err != nil {
+		t.Fatal(err)
+	}
+	logging.New("quiet").Info("kept after changing the level")
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry := make(map[string]interface{})
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("entry isn't JSON: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	want := []struct{ logger, msg string }{
+		{"verbose", "kept"},
+		{"other", "request"},
+		{"quiet", "kept after changing the level"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %v entries; want %v: %v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		if entries[i]["logger"] != w.logger || entries[i]["msg"] != w.msg {
+			t.Errorf("entry %v is %v; want %v from %v", i, entries[i], w.msg, w.logger)
+		}
+		if entries[i]["node"] != 1.0 {
+			t.Errorf("entry %v lacks the node ID: %v", i, entries[i])
+		}
+	}
+	if entries[1]["csr"] != "abcdef" {
+		t.Errorf("entry lacks the field added with With: %v", entries[1])
+	}
+}
+
+func TestConfigureInvalid(t *testing.T) {
+	if err := logging.Configure(logging.Config{Level: "loud"}); err == nil {
+		t.Error("unknown level accepted")
+	}
+	if err := logging.Configure(logging.Config{Levels: map[string]string{"signing": "verbose"}}); err == nil {
+		t.Error("unknown level of a component accepted")
+	}
+	if err := logging.Configure(logging.Config{Format: "xml"}); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
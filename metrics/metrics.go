@@ -0,0 +1,119 @@
+// Package metrics holds the Prometheus metrics of a HotCertification node and serves them over HTTP.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "hotcertification"
+
+// Stages of the certification process whose latency is measured
+const (
+	StageCommit  = "receive_to_commit" // from receiving the CSR until it has been committed by HotStuff
+	StageShares  = "commit_to_shares"  // from the commit until a threshold of signature shares has been collected
+	StageCombine = "combine"           // combining the signature shares into the full signature
+	StageSCTs    = "submit_precert"    // submitting the precertificate to the CT logs until enough SCTs have been returned
+)
+
+// Registry contains all metrics of the node.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// StageLatency is the time a request spends in each stage of the certification process.
+	StageLatency = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "stage_duration_seconds",
+		Help:      "Time a request spends in a stage of the certification process.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+	}, []string{"stage"})
+
+	// CSRs counts the CSRs that have been accepted or rejected by this replica and refused by the admission control.
+	CSRs = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "csrs_total",
+		Help:      "CSRs by outcome (accepted, rejected, refused) and reason.",
+	}, []string{"outcome", "reason"})
+
+	// FailedRequests counts the requests that have been accepted but no certificate has been issued for.
+	FailedRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failed_requests_total",
+		Help:      "Accepted requests that failed later on, by reason.",
+	}, []string{"reason"})
+
+	// InvalidShares counts the signature shares that didn't verify, by the ID of the share.
+	InvalidShares = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "invalid_shares_total",
+		Help:      "Signature shares that didn't verify, by the ID of the peer's key share.",
+	}, []string{"peer"})
+
+	// CertificatesIssued counts the certificates this node has combined the full signature for.
+	CertificatesIssued = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "certificates_issued_total",
+		Help:      "Certificates this node has issued.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveStage records that a request spent the time since start in stage.
+func ObserveStage(stage string, start time.Time) {
+	StageLatency.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+}
+
+// RegisterQueue exports the number of requests waiting in the queue with the given name.
+func RegisterQueue(name string, depth func() int) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Requests waiting in a queue.",
+		ConstLabels: prometheus.Labels{"queue": name},
+	}, func() float64 { return float64(depth()) })
+}
+
+// RegisterHotStuff exports the current view of HotStuff and the ID of its leader.
+func RegisterHotStuff(view func() uint64, leader func() uint32) {
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "hotstuff_view",
+		Help:      "Current view of HotStuff.",
+	}, func() float64 { return float64(view()) })
+	factory.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "hotstuff_leader",
+		Help:      "ID of the leader of the current view.",
+	}, func() float64 { return float64(leader()) })
+}
+
+// Serve serves the metrics at addr/metrics until ctx is cancelled.
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+
+	err := srv.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package metrics_test
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/raphasch/hotcertification/metrics"
+)
+
+// gauge returns the value of the gauge with the given name and label in the registry of the node.
+func gauge(t *testing.T, name, label, value string) (float64, bool) {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if label == "" || hasLabel(m, label, value) {
+				return m.GetGauge().GetValue(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+func hasLabel(m *dto.Metric, name, value string) bool {
+	for _, pair := range m.GetLabel() {
+		if pair.GetName() == name && pair.GetValue() == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRegister(t *testing.T) {
+	replication, signing := 3, 1
+	metrics.RegisterQueue("replication", func() int { return replication })
+	metrics.RegisterQueue("signing", func() int { return signing })
+	metrics.RegisterHotStuff(func() uint64 { return 42 }, func() uint32 { return 2 })
+
+	for queue, want := range map[string]int{"replication": replication, "signing": signing} {
+		if got, ok := gauge(t, "hotcertification_queue_depth", "queue", queue); !ok || got != float64(want) {
+			t.Errorf("depth of %v queue: got %v (exported: %v), want %v", queue, got, ok, want)
+		}
+	}
+	// the gauges are read when the metrics are scraped
+	replication = 7
+	if got, _ := gauge(t, "hotcertification_queue_depth", "queue", "replication"); got != 7 {
+		t.Errorf("depth of replication queue hasn't been updated: got %v", got)
+	}
+
+	if got, ok := gauge(t, "hotcertification_hotstuff_view", "", ""); !ok || got != 42 {
+		t.Errorf("view: got %v (exported: %v)", got, ok)
+	}
+	if got, ok := gauge(t, "hotcertification_hotstuff_leader", "", ""); !ok || got != 2 {
+		t.Errorf("leader: got %v (exported: %v)", got, ok)
+	}
+}
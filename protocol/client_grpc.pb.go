@@ -19,6 +19,13 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CertificationClient interface {
 	GetCertificate(ctx context.Context, in *CSR, opts ...grpc.CallOption) (*Certificate, error)
+	SubmitCSR(ctx context.Context, in *CSR, opts ...grpc.CallOption) (*RequestID, error)
+	GetRequestStatus(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*RequestStatus, error)
+	FetchCertificate(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*Certificate, error)
+	WatchRequest(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (Certification_WatchRequestClient, error)
+	GetSTH(ctx context.Context, in *STHRequest, opts ...grpc.CallOption) (*SignedTreeHead, error)
+	GetProofByHash(ctx context.Context, in *ProofByHashRequest, opts ...grpc.CallOption) (*InclusionProof, error)
+	GetConsistency(ctx context.Context, in *ConsistencyRequest, opts ...grpc.CallOption) (*ConsistencyProof, error)
 }
 
 type certificationClient struct {
@@ -38,11 +45,104 @@ func (c *certificationClient) GetCertificate(ctx context.Context, in *CSR, opts
 	return out, nil
 }
 
+func (c *certificationClient) SubmitCSR(ctx context.Context, in *CSR, opts ...grpc.CallOption) (*RequestID, error) {
+	out := new(RequestID)
+	err := c.cc.Invoke(ctx, "/protocol.Certification/SubmitCSR", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *certificationClient) GetRequestStatus(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*RequestStatus, error) {
+	out := new(RequestStatus)
+	err := c.cc.Invoke(ctx, "/protocol.Certification/GetRequestStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *certificationClient) FetchCertificate(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (*Certificate, error) {
+	out := new(Certificate)
+	err := c.cc.Invoke(ctx, "/protocol.Certification/FetchCertificate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *certificationClient) WatchRequest(ctx context.Context, in *RequestID, opts ...grpc.CallOption) (Certification_WatchRequestClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Certification_ServiceDesc.Streams[0], "/protocol.Certification/WatchRequest", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &certificationWatchRequestClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Certification_WatchRequestClient interface {
+	Recv() (*RequestEvent, error)
+	grpc.ClientStream
+}
+
+type certificationWatchRequestClient struct {
+	grpc.ClientStream
+}
+
+func (x *certificationWatchRequestClient) Recv() (*RequestEvent, error) {
+	m := new(RequestEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *certificationClient) GetSTH(ctx context.Context, in *STHRequest, opts ...grpc.CallOption) (*SignedTreeHead, error) {
+	out := new(SignedTreeHead)
+	err := c.cc.Invoke(ctx, "/protocol.Certification/GetSTH", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *certificationClient) GetProofByHash(ctx context.Context, in *ProofByHashRequest, opts ...grpc.CallOption) (*InclusionProof, error) {
+	out := new(InclusionProof)
+	err := c.cc.Invoke(ctx, "/protocol.Certification/GetProofByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *certificationClient) GetConsistency(ctx context.Context, in *ConsistencyRequest, opts ...grpc.CallOption) (*ConsistencyProof, error) {
+	out := new(ConsistencyProof)
+	err := c.cc.Invoke(ctx, "/protocol.Certification/GetConsistency", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CertificationServer is the server API for Certification service.
 // All implementations must embed UnimplementedCertificationServer
 // for forward compatibility
 type CertificationServer interface {
 	GetCertificate(context.Context, *CSR) (*Certificate, error)
+	SubmitCSR(context.Context, *CSR) (*RequestID, error)
+	GetRequestStatus(context.Context, *RequestID) (*RequestStatus, error)
+	FetchCertificate(context.Context, *RequestID) (*Certificate, error)
+	WatchRequest(*RequestID, Certification_WatchRequestServer) error
+	GetSTH(context.Context, *STHRequest) (*SignedTreeHead, error)
+	GetProofByHash(context.Context, *ProofByHashRequest) (*InclusionProof, error)
+	GetConsistency(context.Context, *ConsistencyRequest) (*ConsistencyProof, error)
 	mustEmbedUnimplementedCertificationServer()
 }
 
@@ -53,6 +153,27 @@ type UnimplementedCertificationServer struct {
 func (UnimplementedCertificationServer) GetCertificate(context.Context, *CSR) (*Certificate, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetCertificate not implemented")
 }
+func (UnimplementedCertificationServer) SubmitCSR(context.Context, *CSR) (*RequestID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitCSR not implemented")
+}
+func (UnimplementedCertificationServer) GetRequestStatus(context.Context, *RequestID) (*RequestStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRequestStatus not implemented")
+}
+func (UnimplementedCertificationServer) FetchCertificate(context.Context, *RequestID) (*Certificate, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchCertificate not implemented")
+}
+func (UnimplementedCertificationServer) WatchRequest(*RequestID, Certification_WatchRequestServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchRequest not implemented")
+}
+func (UnimplementedCertificationServer) GetSTH(context.Context, *STHRequest) (*SignedTreeHead, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSTH not implemented")
+}
+func (UnimplementedCertificationServer) GetProofByHash(context.Context, *ProofByHashRequest) (*InclusionProof, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetProofByHash not implemented")
+}
+func (UnimplementedCertificationServer) GetConsistency(context.Context, *ConsistencyRequest) (*ConsistencyProof, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConsistency not implemented")
+}
 func (UnimplementedCertificationServer) mustEmbedUnimplementedCertificationServer() {}
 
 // UnsafeCertificationServer may be embedded to opt out of forward compatibility for this service.
@@ -84,6 +205,135 @@ func _Certification_GetCertificate_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Certification_SubmitCSR_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CSR)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CertificationServer).SubmitCSR(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protocol.Certification/SubmitCSR",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CertificationServer).SubmitCSR(ctx, req.(*CSR))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Certification_GetRequestStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CertificationServer).GetRequestStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protocol.Certification/GetRequestStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CertificationServer).GetRequestStatus(ctx, req.(*RequestID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Certification_FetchCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CertificationServer).FetchCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protocol.Certification/FetchCertificate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CertificationServer).FetchCertificate(ctx, req.(*RequestID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Certification_WatchRequest_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RequestID)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CertificationServer).WatchRequest(m, &certificationWatchRequestServer{stream})
+}
+
+type Certification_WatchRequestServer interface {
+	Send(*RequestEvent) error
+	grpc.ServerStream
+}
+
+type certificationWatchRequestServer struct {
+	grpc.ServerStream
+}
+
+func (x *certificationWatchRequestServer) Send(m *RequestEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Certification_GetSTH_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(STHRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CertificationServer).GetSTH(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protocol.Certification/GetSTH",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CertificationServer).GetSTH(ctx, req.(*STHRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Certification_GetProofByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProofByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CertificationServer).GetProofByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protocol.Certification/GetProofByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CertificationServer).GetProofByHash(ctx, req.(*ProofByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Certification_GetConsistency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsistencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CertificationServer).GetConsistency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protocol.Certification/GetConsistency",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CertificationServer).GetConsistency(ctx, req.(*ConsistencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Certification_ServiceDesc is the grpc.ServiceDesc for Certification service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -95,7 +345,37 @@ var Certification_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetCertificate",
 			Handler:    _Certification_GetCertificate_Handler,
 		},
+		{
+			MethodName: "SubmitCSR",
+			Handler:    _Certification_SubmitCSR_Handler,
+		},
+		{
+			MethodName: "GetRequestStatus",
+			Handler:    _Certification_GetRequestStatus_Handler,
+		},
+		{
+			MethodName: "FetchCertificate",
+			Handler:    _Certification_FetchCertificate_Handler,
+		},
+		{
+			MethodName: "GetSTH",
+			Handler:    _Certification_GetSTH_Handler,
+		},
+		{
+			MethodName: "GetProofByHash",
+			Handler:    _Certification_GetProofByHash_Handler,
+		},
+		{
+			MethodName: "GetConsistency",
+			Handler:    _Certification_GetConsistency_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchRequest",
+			Handler:       _Certification_WatchRequest_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "client.proto",
 }
@@ -0,0 +1,22 @@
+package protocol
+
+import (
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// defaultRetryDelay is used if the server didn't say how long to wait.
+const defaultRetryDelay = time.Second
+
+// RetryDelay returns the time the server asked to wait before sending a request again that it refused with err,
+// or a second if it didn't say.
+func RetryDelay(err error) time.Duration {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.GetRetryDelay().AsDuration()
+		}
+	}
+	return defaultRetryDelay
+}
@@ -0,0 +1,279 @@
+package hotcertification
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/raphasch/hotcertification/logging"
+)
+
+// Subsystems of a node whose readiness is reported. Each is a service of the gRPC health service; the node as a whole
+// (the empty service name) only serves if all of them do.
+const (
+	SubsystemReplication = "replication" // HotStuff is connected to a quorum of replicas
+	SubsystemSigning     = "signing"     // the signing configuration is set up and a quorum of signers is reachable
+	SubsystemIssuance    = "issuance"    // new requests are admitted; an operator may pause issuance
+)
+
+// how often and how long the peers of a subsystem are probed
+const (
+	probeInterval = 2 * time.Second
+	probeTimeout  = time.Second
+)
+
+// SubsystemState is the readiness of a subsystem.
+type SubsystemState struct {
+	Ready  bool
+	Reason string    // why the subsystem isn't ready
+	Since  time.Time // when the subsystem got into this state
+}
+
+// Probe is the result of trying to connect to a peer.
+type Probe struct {
+	Err     error         // nil if the peer has been reachable
+	Latency time.Duration // how long it took to connect
+}
+
+// Readiness keeps track of the readiness of the subsystems of a node and reports it through the gRPC health service.
+type Readiness struct {
+	mut    sync.Mutex
+	health *health.Server
+	states map[string]SubsystemState
+	peers  map[string]map[string]Probe // result of the last probe of each peer by subsystem and address
+	log    logging.Logger
+}
+
+// NewReadiness returns the readiness of a node whose replication and signing haven't been set up yet.
+func NewReadiness() *Readiness {
+	r := &Readiness{
+		health: health.NewServer(),
+		states: make(map[string]SubsystemState),
+		peers:  make(map[string]map[string]Probe),
+		log:    logging.New("readiness"),
+	}
+	now := time.Now()
+	r.states[SubsystemReplication] = SubsystemState{Reason: "connecting to replicas", Since: now}
+	r.states[SubsystemSigning] = SubsystemState{Reason: "setting up signing configuration", Since: now}
+	r.states[SubsystemIssuance] = SubsystemState{Ready: true, Since: now}
+	r.report()
+	return r
+}
+
+// HealthServer returns the gRPC health service reporting the readiness.
+func (r *Readiness) HealthServer() healthpb.HealthServer {
+	return r.health
+}
+
+// Set changes the readiness of a subsystem. Nothing happens on a nil Readiness.
+func (r *Readiness) Set(subsystem string, ready bool, reason string) {
+	if r == nil {
+		return
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	old, known := r.states[subsystem]
+	if known && old.Ready == ready && old.Reason == reason {
+		return
+	}
+	r.states[subsystem] = SubsystemState{Ready: ready, Reason: reason, Since: time.Now()}
+	if ready {
+		r.log.Infof("%v is ready", subsystem)
+	} else {
+		r.log.Warnf("%v isn't ready: %v", subsystem, reason)
+	}
+	r.report()
+}
+
+// report updates the health service. The caller must hold r.mut.
+func (r *Readiness) report() {
+	all := healthpb.HealthCheckResponse_SERVING
+	for subsystem, state := range r.states {
+		status := healthpb.HealthCheckResponse_SERVING
+		if !state.Ready {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			all = status
+		}
+		r.health.SetServingStatus(subsystem, status)
+	}
+	r.health.SetServingStatus("", all)
+}
+
+// Ready tells whether all subsystems are ready. A nil Readiness is always ready.
+func (r *Readiness) Ready() bool {
+	if r == nil {
+		return true
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	for _, state := range r.states {
+		if !state.Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// States returns a copy of the states of all subsystems.
+func (r *Readiness) States() map[string]SubsystemState {
+	if r == nil {
+		return nil
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	states := make(map[string]SubsystemState, len(r.states))
+	for subsystem, state := range r.states {
+		states[subsystem] = state
+	}
+	return states
+}
+
+// Peers returns the result of the last probe of each peer of a subsystem by address; ok is false if the peers of
+// the subsystem aren't watched (yet).
+func (r *Readiness) Peers(subsystem string) (probes map[string]Probe, ok bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	last, ok := r.peers[subsystem]
+	probes = make(map[string]Probe, len(last))
+	for addr, p := range last {
+		probes[addr] = p
+	}
+	return probes, ok
+}
+
+// Shutdown reports all services as NOT_SERVING for good, so that no new calls are routed to the node.
+func (r *Readiness) Shutdown() {
+	if r == nil {
+		return
+	}
+	r.health.Shutdown()
+}
+
+// WaitForQuorum probes the peers of a subsystem at addrs, backing off in between, until a quorum of them is
+// reachable. It is how a node that has just been started finds out that enough of the cluster is up to connect to
+// it. An error is only returned once ctx is done.
+func (r *Readiness) WaitForQuorum(ctx context.Context, subsystem string, addrs []string, b Backoff) error {
+	return Retry(ctx, b, func(ctx context.Context) error {
+		probes := probe(ctx, addrs, probeTimeout)
+		r.store(subsystem, probes)
+		if err := quorum(addrs, probes); err != nil {
+			r.Set(subsystem, false, "waiting for peers: "+err.Error())
+			return err
+		}
+		return nil
+	})
+}
+
+// WatchPeers probes the peers of a subsystem at addrs until ctx is cancelled. The subsystem is ready while a quorum
+// of them is reachable, the node itself included. It should be called once the subsystem has been set up.
+func (r *Readiness) WatchPeers(ctx context.Context, subsystem string, addrs []string) {
+	if r == nil {
+		return
+	}
+
+	ticker := time.NewTicker(probeInterval)
+	defer ticker.Stop()
+
+	for {
+		probes := probe(ctx, addrs, probeTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+
+		r.store(subsystem, probes)
+		if err := quorum(addrs, probes); err != nil {
+			r.Set(subsystem, false, err.Error())
+		} else {
+			r.Set(subsystem, true, "")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check returns an error naming the first of the subsystems that isn't ready. A nil Readiness is always ready.
+func (r *Readiness) Check(subsystems ...string) error {
+	if r == nil {
+		return nil
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	for _, subsystem := range subsystems {
+		if state := r.states[subsystem]; !state.Ready {
+			return fmt.Errorf("%v isn't ready: %v", subsystem, state.Reason)
+		}
+	}
+	return nil
+}
+
+// store keeps the result of probing the peers of a subsystem.
+func (r *Readiness) store(subsystem string, probes map[string]Probe) {
+	if r == nil {
+		return
+	}
+	r.mut.Lock()
+	defer r.mut.Unlock()
+	r.peers[subsystem] = probes
+}
+
+// quorum returns an error listing the unreachable peers unless a quorum of addrs has been reachable.
+func quorum(addrs []string, probes map[string]Probe) error {
+	var unreachable []string
+	for _, addr := range addrs {
+		if probes[addr].Err != nil {
+			unreachable = append(unreachable, addr)
+		}
+	}
+	sort.Strings(unreachable)
+
+	if reachable, quorum := len(addrs)-len(unreachable), QuorumSize(len(addrs)); reachable < quorum {
+		return fmt.Errorf("only %v of %v peers are reachable, %v are needed; unreachable: %v",
+			reachable, len(addrs), quorum, unreachable)
+	}
+	return nil
+}
+
+// probe tries to open a connection to all addresses at once.
+func probe(ctx context.Context, addrs []string, timeout time.Duration) map[string]Probe {
+	var (
+		mut     sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]Probe, len(addrs))
+	)
+	dialer := &net.Dialer{Timeout: timeout}
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			start := time.Now()
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			latency := time.Since(start)
+			if err == nil {
+				conn.Close()
+			}
+			mut.Lock()
+			results[addr] = Probe{Err: err, Latency: latency}
+			mut.Unlock()
+		}(addr)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,151 @@
+package hotcertification_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+func checkHealth(t *testing.T, r *hc.Readiness, service string, want healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	resp, err := r.HealthServer().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != want {
+		t.Errorf("service %q is %v, want %v", service, resp.Status, want)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	r := hc.NewReadiness()
+	c := hc.NewCoordinator(&hc.Options{})
+	c.Readiness = r
+
+	// nothing has been connected yet
+	checkHealth(t, r, "", healthpb.HealthCheckResponse_NOT_SERVING)
+	checkHealth(t, r, hc.SubsystemSigning, healthpb.HealthCheckResponse_NOT_SERVING)
+
+	r.Set(hc.SubsystemReplication, true, "")
+	checkHealth(t, r, hc.SubsystemReplication, healthpb.HealthCheckResponse_SERVING)
+	checkHealth(t, r, "", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	r.Set(hc.SubsystemSigning, true, "")
+	checkHealth(t, r, "", healthpb.HealthCheckResponse_SERVING)
+	if !r.Ready() {
+		t.Error("node isn't ready although all subsystems are")
+	}
+
+	// a paused node shouldn't get new requests
+	c.Pause("maintenance")
+	checkHealth(t, r, hc.SubsystemIssuance, healthpb.HealthCheckResponse_NOT_SERVING)
+	checkHealth(t, r, "", healthpb.HealthCheckResponse_NOT_SERVING)
+	if state := r.States()[hc.SubsystemIssuance]; state.Ready || state.Reason == "" {
+		t.Errorf("got issuance state %+v while paused", state)
+	}
+	c.Resume()
+	checkHealth(t, r, "", healthpb.HealthCheckResponse_SERVING)
+
+	r.Shutdown()
+	checkHealth(t, r, "", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// listen returns the addresses of n nodes that are up and of one that is down.
+func listen(t *testing.T, n int) (up []string, down string) {
+	t.Helper()
+	for i := 0; i < n+1; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == n {
+			down = lis.Addr().String()
+			lis.Close()
+			break
+		}
+		t.Cleanup(func() { lis.Close() })
+		up = append(up, lis.Addr().String())
+	}
+	return up, down
+}
+
+// watch runs WatchPeers until the subsystem has been probed once and returns its state.
+func watch(t *testing.T, addrs []string) hc.SubsystemState {
+	t.Helper()
+	r := hc.NewReadiness()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.WatchPeers(ctx, hc.SubsystemSigning, addrs)
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, probed := r.Peers(hc.SubsystemSigning); probed {
+			return r.States()[hc.SubsystemSigning]
+		}
+	}
+	t.Fatal("peers haven't been probed")
+	return hc.SubsystemState{}
+}
+
+func TestWatchPeers(t *testing.T) {
+	up, down := listen(t, 3)
+
+	// one of four nodes may be down
+	if state := watch(t, append(up, down)); !state.Ready {
+		t.Errorf("not ready with one node down: %v", state.Reason)
+	}
+
+	// two of four nodes down leaves no quorum
+	if state := watch(t, append(up[:2], down, down)); state.Ready {
+		t.Error("ready without a quorum of nodes")
+	}
+}
+
+func TestWaitForQuorum(t *testing.T) {
+	up, down := listen(t, 3)
+	r := hc.NewReadiness()
+
+	if err := r.WaitForQuorum(context.Background(), hc.SubsystemReplication, append(up, down), fastBackoff); err != nil {
+		t.Fatal(err)
+	}
+	if probes, ok := r.Peers(hc.SubsystemReplication); !ok || probes[down].Err == nil {
+		t.Errorf("got probes %v", probes)
+	}
+	// reaching the peers doesn't make the subsystem ready; it still has to connect to them
+	if r.States()[hc.SubsystemReplication].Ready {
+		t.Error("replication is ready before it has been connected")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := r.WaitForQuorum(ctx, hc.SubsystemSigning, append(up[:2], down, down), fastBackoff); err == nil {
+		t.Fatal("waited for a quorum that isn't reachable")
+	}
+	if state := r.States()[hc.SubsystemSigning]; state.Ready || !strings.HasPrefix(state.Reason, "waiting for peers") {
+		t.Errorf("got signing state %+v", state)
+	}
+}
+
+func TestNotReadyRefusesRequests(t *testing.T) {
+	base := newTestCSR()
+	c := hc.NewCoordinator(&hc.Options{})
+	c.Readiness = hc.NewReadiness()
+
+	_, err := c.AddRequest(context.Background(), requestOf(base, 1, 0))
+	if hc.ReasonOf(err) != protocol.ErrorReason_NOT_READY {
+		t.Fatalf("expected request to be refused before the node is connected, got %v", err)
+	}
+	if err := c.Readiness.Check(hc.SubsystemIssuance); err != nil {
+		t.Errorf("issuance isn't ready: %v", err)
+	}
+
+	c.Readiness.Set(hc.SubsystemReplication, true, "")
+	c.Readiness.Set(hc.SubsystemSigning, true, "")
+	add(t, c, requestOf(base, 1, 0))
+}
@@ -15,7 +15,6 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"time"
 
 	"github.com/relab/hotstuff"
 	hotstuffbackend "github.com/relab/hotstuff/backend/gorums"
@@ -28,6 +27,8 @@ import (
 	"github.com/relab/hotstuff/synchronizer"
 
 	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/metrics"
 )
 
 // This implements the Certification interface from the certification_gorums.pb.go (protocol.Certification)
@@ -37,6 +38,9 @@ type replicationServer struct {
 	hsSrv       *hotstuffbackend.Server // the transport backend for the consensus algorithm
 	cfg         *hotstuffbackend.Config // manages the connections to the other nodes in the network
 	coordinator *hc.Coordinator
+	peers       []string   // addresses of all replicas
+	backoff     hc.Backoff // how connecting to the other replicas is retried
+	log         logging.Logger
 }
 
 func NewReplicationServer(coordinator *hc.Coordinator, opts *hc.Options) *replicationServer {
@@ -45,6 +49,11 @@ func NewReplicationServer(coordinator *hc.Coordinator, opts *hc.Options) *replic
 
 	srv := &replicationServer{
 		coordinator: coordinator,
+		backoff:     hc.NewBackoff(opts),
+		log:         logging.New("replication"),
+	}
+	for _, node := range opts.Nodes {
+		srv.peers = append(srv.peers, node.ReplicationSrvAddr)
 	}
 
 	// building the hotstuff consensus algorithm
@@ -79,10 +88,16 @@ func NewReplicationServer(coordinator *hc.Coordinator, opts *hc.Options) *replic
 		leaderRotation,
 		coordinator, // executor
 		coordinator, // acceptor and command queue
-		coordinator.Log,
+		// hotstuff's own logs go through the same pipeline under their own name and level
+		logging.New("hotstuff"),
 	)
 	srv.hs = builder.Build()
 
+	metrics.RegisterHotStuff(
+		func() uint64 { return uint64(srv.hs.ViewSynchronizer().View()) },
+		func() uint32 { return uint32(srv.hs.LeaderRotation().GetLeader(srv.hs.ViewSynchronizer().View())) },
+	)
+
 	return srv
 }
 
@@ -116,6 +131,8 @@ func createReplicaConfig(opts *hc.Options) *hsconfig.ReplicaConfig {
 	return replicaConfig
 }
 
+// Start serves HotStuff on addr and runs the consensus until ctx is cancelled. Everything it has set up is torn
+// down before it returns.
 func (srv *replicationServer) Start(ctx context.Context, addr string) (err error) {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
@@ -123,14 +140,32 @@ func (srv *replicationServer) Start(ctx context.Context, addr string) (err error
 	}
 
 	srv.hsSrv.StartOnListener(lis)
+	defer srv.hsSrv.Stop()
 
-	err = srv.cfg.Connect(10 * time.Second)
+	// replicas started at the same time might not be listening yet; dialing them is only worth it once they are
+	err = srv.coordinator.Readiness.WaitForQuorum(ctx, hc.SubsystemReplication, srv.peers, srv.backoff)
 	if err != nil {
 		return err
 	}
 
-	// sleep so that all replicas can be ready before we start
-	time.Sleep(time.Second)
+	// Unlike the signing configuration, the HotStuff backend can't be connected to a part of the replicas: its
+	// Config.Connect dials every replica with grpc.WithBlock and fails if one of them can't be reached. A node
+	// therefore needs all replicas once to bootstrap, even though a quorum is enough to run the consensus;
+	// tolerating unreachable replicas here takes a backend that dials lazily, i.e. a change to the hotstuff
+	// submodule. Afterwards, gorums re-establishes broken connections by itself.
+	err = hc.Retry(ctx, srv.backoff, func(context.Context) error {
+		err := srv.cfg.Connect(srv.backoff.Timeout)
+		if err != nil {
+			srv.log.Warnf("Failed to connect to all replicas: %v", err)
+			srv.coordinator.Readiness.Set(hc.SubsystemReplication, false,
+				fmt.Sprintf("HotStuff needs all replicas to bootstrap: %v", err))
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer srv.cfg.Close()
 
 	c := make(chan struct{})
 	go func() {
@@ -138,17 +173,16 @@ func (srv *replicationServer) Start(ctx context.Context, addr string) (err error
 		close(c)
 	}()
 
-	srv.coordinator.Log.Infof("Replication server listening on %v.", addr)
+	srv.log.Infof("Replication server listening on %v.", addr)
+
+	// ready as long as a quorum of replicas can be reached
+	go srv.coordinator.Readiness.WatchPeers(ctx, hc.SubsystemReplication, srv.peers)
 
 	// wait for the event loop to exit
 	<-c
-	return nil
-}
-
-func (srv *replicationServer) Stop() {
 	srv.hs.ViewSynchronizer().Stop()
-	srv.cfg.Close()
-	srv.hsSrv.Stop()
+	srv.log.Info("Replication server stopped.")
+	return nil
 }
 
 /*
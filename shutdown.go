@@ -0,0 +1,87 @@
+package hotcertification
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/raphasch/hotcertification/metrics"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// ErrShutdown is the error of a request that has been abandoned because the node shut down before completing it.
+var ErrShutdown = errors.New("node shut down before the request was completed")
+
+// DrainReport tells what became of the requests that had been submitted to the node and were still in flight when
+// it started shutting down. Both lists hold the hashes of the CSRs.
+type DrainReport struct {
+	Completed []string // issued or failed while draining
+	Abandoned []string // still in flight when draining ended
+}
+
+// Drain stops admitting requests and waits until the requests that have been submitted to this node are completed
+// or ctx is done. The requests still in flight then are abandoned: they fail with ErrShutdown so that the clients
+// waiting for them learn that they have to go to another node. Replication and signing have to keep running until
+// Drain returns since the requests can't be completed otherwise.
+func (c *Coordinator) Drain(ctx context.Context) DrainReport {
+	c.Mut.Lock()
+	c.draining = true
+	c.Readiness.Set(SubsystemIssuance, false, "shutting down")
+
+	// the done channels are read under the lock since signing a failed request again replaces them; requests
+	// aren't signed again while draining
+	inFlight := make(map[string]*RequestInfo)
+	done := make([]chan struct{}, 0)
+	for hash, info := range c.Database {
+		if info.Received && !info.finished() {
+			inFlight[hash] = info
+			done = append(done, info.done)
+		}
+	}
+	c.Mut.Unlock()
+
+	c.Log.Infof("Draining %v requests", len(inFlight))
+	for _, d := range done {
+		select {
+		case <-d:
+		case <-ctx.Done():
+		}
+	}
+
+	c.Mut.Lock()
+	defer c.Mut.Unlock()
+
+	var report DrainReport
+	for hash, info := range inFlight {
+		if info.finished() {
+			report.Completed = append(report.Completed, hash)
+			continue
+		}
+		report.Abandoned = append(report.Abandoned, hash)
+
+		abandoned := NewRequestError(protocol.ErrorReason_NOT_READY, ErrShutdown, "request has been abandoned")
+		metrics.FailedRequests.WithLabelValues(ReasonOf(abandoned).String()).Inc()
+		c.audited(c.Audit.Failed(hash, ReasonOf(abandoned).String()))
+		if c.withdraw(hash, info, abandoned) {
+			continue
+		}
+		info.Err = abandoned
+		info.finish()
+		c.emit(hash, info, &protocol.RequestEvent{Message: "abandoned: " + ErrShutdown.Error()})
+	}
+	sort.Strings(report.Completed)
+	sort.Strings(report.Abandoned)
+	return report
+}
+
+// Close closes the audit log and the certificate log. It must only be called once nothing is replicated or signed
+// anymore.
+func (c *Coordinator) Close() error {
+	auditErr := c.Audit.Close()
+	if c.CTLog != nil {
+		if err := c.CTLog.Close(); err != nil {
+			return err
+		}
+	}
+	return auditErr
+}
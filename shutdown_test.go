@@ -0,0 +1,59 @@
+package hotcertification_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+func TestDrain(t *testing.T) {
+	base := newTestCSR()
+	c := hc.NewCoordinator(&hc.Options{})
+
+	issued := add(t, c, requestOf(base, 1, 0))
+	replicate(t, c)
+	signing := add(t, c, requestOf(base, 1, 1))
+	replicate(t, c)
+	queued := add(t, c, requestOf(base, 1, 2))
+
+	// the first request gets issued while the node drains, the others are still in flight when it gives up
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		c.Finish(issued, &x509.Certificate{}, nil)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	report := c.Drain(ctx)
+
+	if len(report.Completed) != 1 || report.Completed[0] != issued {
+		t.Errorf("got completed %v, want %v", report.Completed, issued)
+	}
+	abandoned := []string{signing, queued}
+	sort.Strings(abandoned)
+	if len(report.Abandoned) != 2 || report.Abandoned[0] != abandoned[0] || report.Abandoned[1] != abandoned[1] {
+		t.Errorf("got abandoned %v, want %v", report.Abandoned, abandoned)
+	}
+
+	// the clients waiting for an abandoned request are told to go elsewhere
+	info, err := c.Wait(context.Background(), signing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(info.Err, hc.ErrShutdown) || hc.ReasonOf(info.Err) != protocol.ErrorReason_NOT_READY {
+		t.Errorf("got error %v for abandoned request", info.Err)
+	}
+	// a request that hasn't been proposed yet is withdrawn
+	if _, ok := c.Lookup(queued); ok {
+		t.Error("abandoned request that hasn't been proposed is still stored")
+	}
+
+	if _, err := c.AddRequest(context.Background(), requestOf(base, 1, 3)); hc.ReasonOf(err) != protocol.ErrorReason_NOT_READY {
+		t.Errorf("expected new request to be refused while shutting down, got %v", err)
+	}
+}
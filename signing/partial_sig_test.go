@@ -0,0 +1,99 @@
+package signing
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/relab/hotstuff"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// TestGetPartialSig checks that a node only contributes a signature share to the certificate of a replicated CSR.
+func TestGetPartialSig(t *testing.T) {
+	keys, root, err := crypto.GenerateCA(3, 4, 512, stdcrypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherRoot, err := crypto.GenerateCA(3, 4, 512, stdcrypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := hc.NewCoordinator(&hc.Options{})
+	srv := &signingServer{key: keys[1], rootCA: root, coordinator: c, log: logging.New("signing")}
+
+	newCSR := func() (*x509.CertificateRequest, *protocol.CSR) {
+		clientKey, err := rsa.GenerateKey(rand.Reader, 512)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := crypto.GenerateCSR(clientKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req, &protocol.CSR{ClientID: 1, CertificateRequest: req.Raw}
+	}
+	req, csr := newCSR()
+	otherReq, _ := newCSR()
+	cmd, err := c.Marshaler.Marshal(csr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Accept(hotstuff.Command(cmd)) {
+		t.Fatal("command has been rejected")
+	}
+	hash := hc.HashCSR(csr)
+
+	sign := func(req *x509.CertificateRequest, issuer *x509.Certificate, opts ...crypto.CertOption) error {
+		t.Helper()
+		cert, err := crypto.GenerateCert(req, issuer, keys[0], opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srv.GetPartialSig(context.Background(), &TBS{CSRHash: hash, Certificate: cert.Raw}, func(_ *SigShare, e error) { err = e })
+		return err
+	}
+
+	if err := sign(req, root); err != nil {
+		t.Errorf("certificate of the CSR hasn't been signed: %v", err)
+	}
+	if err := sign(req, root, crypto.Precertificate()); err != nil {
+		t.Errorf("precertificate of the CSR hasn't been signed: %v", err)
+	}
+
+	refused := map[string]struct {
+		req    *x509.CertificateRequest
+		issuer *x509.Certificate
+		opts   []crypto.CertOption
+	}{
+		"certificate of another CSR": {req: otherReq, issuer: root},
+		"certificate of another CA":  {req: req, issuer: otherRoot},
+		"CA certificate": {req: req, issuer: root, opts: []crypto.CertOption{func(cert *x509.Certificate) error {
+			cert.IsCA = true
+			return nil
+		}}},
+		"certificate with another name": {req: req, issuer: root, opts: []crypto.CertOption{func(cert *x509.Certificate) error {
+			cert.EmailAddresses = append(cert.EmailAddresses, "mallory@example.com")
+			return nil
+		}}},
+		"certificate with another extension": {req: req, issuer: root, opts: []crypto.CertOption{func(cert *x509.Certificate) error {
+			value, _ := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 1}})
+			cert.ExtraExtensions = append(cert.ExtraExtensions, pkix.Extension{Id: asn1.ObjectIdentifier{2, 5, 29, 37}, Value: value})
+			return nil
+		}}},
+	}
+	for name, tc := range refused {
+		if err := sign(tc.req, tc.issuer, tc.opts...); err == nil {
+			t.Errorf("%v has been signed", name)
+		}
+	}
+}
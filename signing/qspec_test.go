@@ -0,0 +1,114 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/niclabs/tcrsa"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/metrics"
+)
+
+// TestInvalidShare checks that a quorum call collecting signature shares doesn't return with an invalid share but
+// waits for a threshold of valid ones.
+func TestInvalidShare(t *testing.T) {
+	keys, err := crypto.ComputeTresholdKeys(3, 4, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, selfTestNonceSize)
+	in := &SelfTestNonce{Nonce: nonce}
+	digest := selfTestDigest(nonce, keys[0].HashType)
+
+	shares := make([]*SigShare, len(keys))
+	for i, key := range keys {
+		(&signingServer{key: key}).SelfTest(context.Background(), in, func(share *SigShare, err error) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			shares[i] = share
+		})
+	}
+	// the share of the second node has been tampered with
+	forged := &SigShare{Xi: new(big.Int).Add(new(big.Int).SetBytes(shares[1].Xi), big.NewInt(1)).Bytes(),
+		C: shares[1].C, Z: shares[1].Z, Id: shares[1].Id}
+	forgedID := strconv.Itoa(int(forged.Id))
+
+	qs := &QSpec{quorumSize: 3, log: logging.New("signing")}
+	done := qs.expect(in, "self-test", (&signingServer{key: keys[0]}).verifyDigest(digest, keys[0].HashType))
+	defer done()
+
+	invalid := testutil.ToFloat64(metrics.InvalidShares.WithLabelValues(forgedID))
+	replies := map[uint32]*SigShare{1: shares[0], 2: forged, 3: shares[2]}
+	if _, ok := qs.SelfTestQF(in, replies); ok {
+		t.Fatal("quorum call returned with an invalid share")
+	}
+	// a share sent twice doesn't count twice either
+	replies[4] = shares[0]
+	if _, ok := qs.SelfTestQF(in, replies); ok {
+		t.Fatal("quorum call returned with a share that has been sent twice")
+	}
+	if got := testutil.ToFloat64(metrics.InvalidShares.WithLabelValues(forgedID)); got != invalid+1 {
+		t.Errorf("invalid share counted %v times", got-invalid)
+	}
+
+	delete(replies, 4)
+	replies[5] = shares[3]
+	thresholdOf, ok := qs.SelfTestQF(in, replies)
+	if !ok {
+		t.Fatal("quorum call didn't return with 3 valid shares")
+	}
+	for _, share := range thresholdOf.SigShares {
+		if share.Id == forged.Id {
+			t.Fatal("invalid share has been returned")
+		}
+	}
+	if _, err := crypto.JoinDigestShares(digest, keys[0].HashType, keys[0], thresholdOf.shares()...); err != nil {
+		t.Fatal(err)
+	}
+
+	// shares of a call that hasn't been registered are never used
+	done()
+	if _, ok := qs.SelfTestQF(in, replies); ok {
+		t.Error("quorum call returned with shares that haven't been verified")
+	}
+}
+
+// TestProgress checks that the progress of collecting signature shares is only reported when another valid share
+// has arrived and tells the shares on the precertificate apart.
+func TestProgress(t *testing.T) {
+	var reports []string
+	qs := &QSpec{quorumSize: 3, log: logging.New("signing"), progress: func(hash, what string, shares, threshold int) {
+		reports = append(reports, fmt.Sprintf("%v %v %v/%v", hash, what, shares, threshold))
+	}}
+
+	in := &TBS{CSRHash: "abcdef"}
+	done := qs.expect(in, "precertificate", func(share *tcrsa.SigShare) error {
+		if share.Id == 2 {
+			return errors.New("invalid share")
+		}
+		return nil
+	})
+	defer done()
+
+	replies := map[uint32]*SigShare{1: {Id: 1}}
+	qs.GetPartialSigQF(in, replies)
+	replies[2] = &SigShare{Id: 2} // invalid
+	qs.GetPartialSigQF(in, replies)
+	replies[3] = &SigShare{Id: 1} // sent twice
+	qs.GetPartialSigQF(in, replies)
+	replies[4] = &SigShare{Id: 3}
+	qs.GetPartialSigQF(in, replies)
+
+	want := []string{"abcdef precertificate 1/3", "abcdef precertificate 2/3"}
+	if fmt.Sprint(reports) != fmt.Sprint(want) {
+		t.Errorf("got progress %q, want %q", reports, want)
+	}
+}
@@ -0,0 +1,88 @@
+package signing
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+// time a node that has just been started has to get a valid test signature from the replicas
+const selfTestTimeout = 30 * time.Second
+
+const selfTestNonceSize = 32
+
+// selfTestDigest is the digest the replicas sign for the self-test, hashed with the hash the key signs certificates
+// with; the prefix keeps it apart from anything else that is signed with the key of the CA.
+func selfTestDigest(nonce []byte, hashType stdcrypto.Hash) []byte {
+	h := hashType.New()
+	h.Write([]byte("hotcertification self-test"))
+	h.Write(nonce)
+	return h.Sum(nil)
+}
+
+// selfTest gets a threshold signature on a random nonce from the replicas and verifies it with the public key of
+// the root certificate, so that a node whose key or whose peers' keys don't match the CA never signs a
+// certificate. It fails unless a valid signature has been collected within selfTestTimeout.
+func (srv *signingServer) selfTest(ctx context.Context) error {
+	srv.coordinator.Readiness.Set(hc.SubsystemSigning, false, "testing the threshold key")
+	if srv.rootCA == nil {
+		return fmt.Errorf("root certificate couldn't be read")
+	}
+	pub, ok := srv.rootCA.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("root certificate doesn't have an RSA key")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+	return hc.Retry(ctx, srv.backoff, func(ctx context.Context) error {
+		nonce := make([]byte, selfTestNonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		digest := selfTestDigest(nonce, srv.key.HashType)
+		in := &SelfTestNonce{Nonce: nonce}
+		done := srv.qspec.expect(in, "self-test", srv.verifyDigest(digest, srv.key.HashType))
+		thresholdOf, err := srv.config().SelfTest(ctx, in)
+		done()
+		if err != nil {
+			srv.log.Warnf("Failed to collect valid signature shares for the self-test: %v", err)
+			return err
+		}
+
+		signature, err := crypto.JoinDigestShares(digest, srv.key.HashType, srv.key, thresholdOf.shares()...)
+		if err == nil {
+			err = rsa.VerifyPKCS1v15(pub, srv.key.HashType, digest, signature)
+		}
+		if err != nil {
+			srv.log.Errorf("Self-test signature is invalid: %v", err)
+			return err
+		}
+		srv.log.Infof("Self-test of the threshold key passed with %v signature shares.", len(thresholdOf.SigShares))
+		return nil
+	})
+}
+
+// SelfTest contributes a signature share to the self-test of a node that has just been started.
+func (srv *signingServer) SelfTest(_ context.Context, in *SelfTestNonce, out func(*SigShare, error)) {
+	if len(in.Nonce) != selfTestNonceSize {
+		out(nil, fmt.Errorf("self-test nonce has to be %v bytes", selfTestNonceSize))
+		return
+	}
+	share, err := crypto.SignDigestShare(selfTestDigest(in.Nonce, srv.key.HashType), srv.key.HashType, srv.key)
+	if err != nil {
+		out(nil, fmt.Errorf("failed to compute a signature share"))
+		return
+	}
+	out(&SigShare{Xi: share.Xi, C: share.C, Z: share.Z, Id: uint32(share.Id)}, nil)
+}
+
+func (qs *QSpec) SelfTestQF(in *SelfTestNonce, sigShares map[uint32]*SigShare) (*ThresholdOf, bool) {
+	return qs.quorum(qs.validShares(in, sigShares))
+}
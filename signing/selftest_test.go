@@ -0,0 +1,56 @@
+package signing
+
+import (
+	"context"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/logging"
+)
+
+// TestSelfTestShares checks that the shares the replicas contribute to the self-test combine into a signature of
+// the CA on the nonce.
+func TestSelfTestShares(t *testing.T) {
+	keys, err := crypto.ComputeTresholdKeys(3, 4, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, selfTestNonceSize)
+	nonce[0] = 1
+
+	in := &SelfTestNonce{Nonce: nonce}
+	digest := selfTestDigest(nonce, keys[0].HashType)
+	qs := &QSpec{quorumSize: 3, log: logging.New("signing")}
+	defer qs.expect(in, "self-test", (&signingServer{key: keys[0]}).verifyDigest(digest, keys[0].HashType))()
+	replies := make(map[uint32]*SigShare)
+	for _, key := range keys[1:] {
+		srv := &signingServer{key: key}
+		srv.SelfTest(context.Background(), &SelfTestNonce{Nonce: nonce}, func(share *SigShare, err error) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			replies[share.Id] = share
+		})
+	}
+	thresholdOf, ok := qs.SelfTestQF(in, replies)
+	if !ok {
+		t.Fatalf("%v shares aren't a quorum", len(replies))
+	}
+
+	signature, err := crypto.JoinDigestShares(digest, keys[0].HashType, keys[0], thresholdOf.shares()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(keys[0].Public(), keys[0].HashType, digest, signature); err != nil {
+		t.Fatal(err)
+	}
+
+	// nonces of the wrong size aren't signed
+	srv := &signingServer{key: keys[0]}
+	srv.SelfTest(context.Background(), &SelfTestNonce{Nonce: nonce[:8]}, func(share *SigShare, err error) {
+		if err == nil {
+			t.Error("signed a nonce of the wrong size")
+		}
+	})
+}
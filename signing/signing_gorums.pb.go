@@ -130,6 +130,41 @@ type QuorumSpec interface {
 	// be used by the quorum function. If the in parameter is not needed
 	// you should implement your quorum function with '_ *TBS'.
 	GetPartialSigQF(in *TBS, replies map[uint32]*SigShare) (*ThresholdOf, bool)
+
+	// StoreCertificateQF is the quorum function for the StoreCertificate
+	// quorum call method. The in parameter is the request object
+	// supplied to the StoreCertificate method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *IssuedCert'.
+	StoreCertificateQF(in *IssuedCert, replies map[uint32]*Ack) (*Ack, bool)
+
+	// SignCheckpointQF is the quorum function for the SignCheckpoint
+	// quorum call method. The in parameter is the request object
+	// supplied to the SignCheckpoint method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *Checkpoint'.
+	SignCheckpointQF(in *Checkpoint, replies map[uint32]*SigShare) (*ThresholdOf, bool)
+
+	// ExtendLogQF is the quorum function for the ExtendLog
+	// quorum call method. The in parameter is the request object
+	// supplied to the ExtendLog method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *LogExtension'.
+	ExtendLogQF(in *LogExtension, replies map[uint32]*SigShare) (*ThresholdOf, bool)
+
+	// PublishSTHQF is the quorum function for the PublishSTH
+	// quorum call method. The in parameter is the request object
+	// supplied to the PublishSTH method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *SignedTreeHead'.
+	PublishSTHQF(in *SignedTreeHead, replies map[uint32]*Ack) (*Ack, bool)
+
+	// SelfTestQF is the quorum function for the SelfTest
+	// quorum call method. The in parameter is the request object
+	// supplied to the SelfTest method at call time, and may or may not
+	// be used by the quorum function. If the in parameter is not needed
+	// you should implement your quorum function with '_ *SelfTestNonce'.
+	SelfTestQF(in *SelfTestNonce, replies map[uint32]*SigShare) (*ThresholdOf, bool)
 }
 
 // GetPartialSig is a quorum call invoked on all nodes in configuration c,
@@ -154,9 +189,124 @@ func (c *Configuration) GetPartialSig(ctx context.Context, in *TBS) (resp *Thres
 	return res.(*ThresholdOf), err
 }
 
+// StoreCertificate is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) StoreCertificate(ctx context.Context, in *IssuedCert) (resp *Ack, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "signing.Signing.StoreCertificate",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*Ack, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*Ack)
+		}
+		return c.qspec.StoreCertificateQF(req.(*IssuedCert), r)
+	}
+
+	res, err := c.Configuration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*Ack), err
+}
+
+// SignCheckpoint is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) SignCheckpoint(ctx context.Context, in *Checkpoint) (resp *ThresholdOf, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "signing.Signing.SignCheckpoint",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*SigShare, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*SigShare)
+		}
+		return c.qspec.SignCheckpointQF(req.(*Checkpoint), r)
+	}
+
+	res, err := c.Configuration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*ThresholdOf), err
+}
+
+// ExtendLog is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) ExtendLog(ctx context.Context, in *LogExtension) (resp *ThresholdOf, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "signing.Signing.ExtendLog",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*SigShare, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*SigShare)
+		}
+		return c.qspec.ExtendLogQF(req.(*LogExtension), r)
+	}
+
+	res, err := c.Configuration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*ThresholdOf), err
+}
+
+// PublishSTH is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) PublishSTH(ctx context.Context, in *SignedTreeHead) (resp *Ack, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "signing.Signing.PublishSTH",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*Ack, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*Ack)
+		}
+		return c.qspec.PublishSTHQF(req.(*SignedTreeHead), r)
+	}
+
+	res, err := c.Configuration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*Ack), err
+}
+
+// SelfTest is a quorum call invoked on all nodes in configuration c,
+// with the same argument in, and returns a combined result.
+func (c *Configuration) SelfTest(ctx context.Context, in *SelfTestNonce) (resp *ThresholdOf, err error) {
+	cd := gorums.QuorumCallData{
+		Message: in,
+		Method:  "signing.Signing.SelfTest",
+	}
+	cd.QuorumFunction = func(req protoreflect.ProtoMessage, replies map[uint32]protoreflect.ProtoMessage) (protoreflect.ProtoMessage, bool) {
+		r := make(map[uint32]*SigShare, len(replies))
+		for k, v := range replies {
+			r[k] = v.(*SigShare)
+		}
+		return c.qspec.SelfTestQF(req.(*SelfTestNonce), r)
+	}
+
+	res, err := c.Configuration.QuorumCall(ctx, cd)
+	if err != nil {
+		return nil, err
+	}
+	return res.(*ThresholdOf), err
+}
+
 // Signing is the server-side API for the Signing Service
 type Signing interface {
 	GetPartialSig(context.Context, *TBS, func(*SigShare, error))
+	StoreCertificate(context.Context, *IssuedCert, func(*Ack, error))
+	SignCheckpoint(context.Context, *Checkpoint, func(*SigShare, error))
+	ExtendLog(context.Context, *LogExtension, func(*SigShare, error))
+	PublishSTH(context.Context, *SignedTreeHead, func(*Ack, error))
+	SelfTest(context.Context, *SelfTestNonce, func(*SigShare, error))
 }
 
 func RegisterSigningServer(srv *gorums.Server, impl Signing) {
@@ -173,6 +323,71 @@ func RegisterSigningServer(srv *gorums.Server, impl Signing) {
 		}
 		impl.GetPartialSig(ctx, req, f)
 	})
+	srv.RegisterHandler("signing.Signing.StoreCertificate", func(ctx context.Context, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*IssuedCert)
+		once := new(sync.Once)
+		f := func(resp *Ack, err error) {
+			once.Do(func() {
+				select {
+				case finished <- gorums.WrapMessage(in.Metadata, resp, err):
+				case <-ctx.Done():
+				}
+			})
+		}
+		impl.StoreCertificate(ctx, req, f)
+	})
+	srv.RegisterHandler("signing.Signing.SignCheckpoint", func(ctx context.Context, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*Checkpoint)
+		once := new(sync.Once)
+		f := func(resp *SigShare, err error) {
+			once.Do(func() {
+				select {
+				case finished <- gorums.WrapMessage(in.Metadata, resp, err):
+				case <-ctx.Done():
+				}
+			})
+		}
+		impl.SignCheckpoint(ctx, req, f)
+	})
+	srv.RegisterHandler("signing.Signing.ExtendLog", func(ctx context.Context, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*LogExtension)
+		once := new(sync.Once)
+		f := func(resp *SigShare, err error) {
+			once.Do(func() {
+				select {
+				case finished <- gorums.WrapMessage(in.Metadata, resp, err):
+				case <-ctx.Done():
+				}
+			})
+		}
+		impl.ExtendLog(ctx, req, f)
+	})
+	srv.RegisterHandler("signing.Signing.PublishSTH", func(ctx context.Context, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*SignedTreeHead)
+		once := new(sync.Once)
+		f := func(resp *Ack, err error) {
+			once.Do(func() {
+				select {
+				case finished <- gorums.WrapMessage(in.Metadata, resp, err):
+				case <-ctx.Done():
+				}
+			})
+		}
+		impl.PublishSTH(ctx, req, f)
+	})
+	srv.RegisterHandler("signing.Signing.SelfTest", func(ctx context.Context, in *gorums.Message, finished chan<- *gorums.Message) {
+		req := in.Message.(*SelfTestNonce)
+		once := new(sync.Once)
+		f := func(resp *SigShare, err error) {
+			once.Do(func() {
+				select {
+				case finished <- gorums.WrapMessage(in.Metadata, resp, err):
+				case <-ctx.Done():
+				}
+			})
+		}
+		impl.SelfTest(ctx, req, f)
+	})
 }
 
 type internalSigShare struct {
@@ -180,3 +395,9 @@ type internalSigShare struct {
 	reply *SigShare
 	err   error
 }
+
+type internalAck struct {
+	nid   uint32
+	reply *Ack
+	err   error
+}
@@ -1,55 +1,124 @@
 package signing
 
 import (
+	"bytes"
 	"context"
+	stdcrypto "crypto"
 	"crypto/x509"
 	"fmt"
 	"net"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/niclabs/tcrsa"
 	"github.com/relab/gorums"
+	"github.com/relab/hotstuff"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 
 	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/audit"
 	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/ctlog"
+	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/metrics"
 	"github.com/raphasch/hotcertification/protocol"
+	"github.com/raphasch/hotcertification/tracing"
 )
 
+// time replicas wait for each other to reach a checkpoint of the audit log and sign it
+const checkpointTimeout = 30 * time.Second
+
+// time the sequencer waits for the replicas to sign an extension of the certificate log
+const extensionTimeout = 10 * time.Second
+
+const defaultSTHInterval = time.Second
+
 type signingServer struct {
 	key         *crypto.ThresholdKey
 	rootCA      *x509.Certificate
 	coordinator *hc.Coordinator
 	nodes       []string
+	nodeIDs     []hotstuff.ID
 	mgr         *Manager // calls the RPC on the other servers to get a partial signature
-	cfg         *Configuration
-	backendSrv  *gorums.Server // handles the transport/serialization/tls....
+	cfgMut      sync.RWMutex
+	cfg         *Configuration // signers that have been connected; replaced as more of them get connected
+	qspec       *QSpec         // decides when enough signers have replied
+	backoff     hc.Backoff     // how connecting to the other signers is retried
+	backendSrv  *gorums.Server  // handles the transport/serialization/tls....
+	pool        *workerPool     // runs several signing sessions concurrently
+	sequencer   bool            // this node decides in which order certificates are added to the certificate log
+	sthInterval time.Duration   // how often the sequencer extends the certificate log
+	ctLogs      []*ctlog.Client // CT logs precertificates are submitted to
+	minSCTs     int             // number of SCTs a certificate needs
+	log         logging.Logger
 }
 
-func NewSigningServer(coordinator *hc.Coordinator, key *crypto.ThresholdKey, nodes []string, rootCAFile string) *signingServer {
+func NewSigningServer(coordinator *hc.Coordinator, key *crypto.ThresholdKey, opts *hc.Options) *signingServer {
 	// add options here
 	gorumsSrv := gorums.NewServer()
+	// gRPC redials and gorums reopens the streams of broken connections with the same backoff
+	b := hc.NewBackoff(opts)
 	mgr := NewManager(
-		gorums.WithDialTimeout(30*time.Second),
+		gorums.WithDialTimeout(b.Timeout),
+		gorums.WithBackoff(b.Config),
 		gorums.WithGrpcDialOptions(
 			grpc.WithBlock(),
 			grpc.WithInsecure(),
+			grpc.WithConnectParams(grpc.ConnectParams{Backoff: b.Config, MinConnectTimeout: b.Timeout}),
 		),
 	)
 
-	rootCA, err := crypto.ReadCertFile(rootCAFile)
+	log := logging.New("signing")
+
+	rootCA, err := crypto.ReadCertFile(opts.RootCA)
 	if err != nil {
-		coordinator.Log.Error(err)
+		log.Error(err)
+	}
+
+	// Parsing signing node information
+	nodes := make([]string, len(opts.Nodes))
+	nodeIDs := make([]hotstuff.ID, len(opts.Nodes))
+	for i, node := range opts.Nodes {
+		nodes[i] = node.SigningSrvAddr
+		nodeIDs[i] = node.ID
 	}
 
 	sigSrv := &signingServer{
 		key:         key,
 		backendSrv:  gorumsSrv,
 		mgr:         mgr,
+		backoff:     b,
 		rootCA:      rootCA,
 		nodes:       nodes,
+		nodeIDs:     nodeIDs,
 		coordinator: coordinator,
+		qspec:       &QSpec{quorumSize: hc.QuorumSize(len(nodes)), log: log, progress: coordinator.Progress},
+		sequencer:   opts.ID == sequencerOf(opts),
+		sthInterval: defaultSTHInterval,
+		minSCTs:     1,
+		log:         log,
+	}
+	if opts.STHInterval > 0 {
+		sigSrv.sthInterval = time.Duration(opts.STHInterval) * time.Millisecond
 	}
+	for _, endpoint := range opts.CTLogs {
+		client, err := ctlog.NewClient(endpoint.URL, endpoint.PublicKey)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		sigSrv.ctLogs = append(sigSrv.ctLogs, client)
+	}
+	if opts.MinSCTs > 0 {
+		sigSrv.minSCTs = opts.MinSCTs
+	}
+
+	sigSrv.pool = newWorkerPool(opts.SigningWorkers, time.Duration(opts.SigningTimeout)*time.Millisecond, sigSrv.GetFullSignature)
 
 	// glue together backend (transport/serialization) with frontend implementation (computing the partial signature)
 	RegisterSigningServer(gorumsSrv, sigSrv)
@@ -57,7 +126,7 @@ func NewSigningServer(coordinator *hc.Coordinator, key *crypto.ThresholdKey, nod
 	return sigSrv
 }
 
-func (srv *signingServer) GetPartialSig(_ context.Context, tbs *TBS, out func(*SigShare, error)) {
+func (srv *signingServer) GetPartialSig(ctx context.Context, tbs *TBS, out func(*SigShare, error)) {
 	/*
 		1. Parse certificate
 		2. Partially Sign certificate
@@ -65,29 +134,49 @@ func (srv *signingServer) GetPartialSig(_ context.Context, tbs *TBS, out func(*S
 		4. TODO: check database if already signed and then update to signed = true
 	*/
 
-	srv.coordinator.Log.Info("Received request for partial signature. Checking authorization.")
+	log := logging.With(srv.log, "csr", tbs.CSRHash[:6])
+	log.Info("Received request for partial signature. Checking authorization.")
+
+	_, span := tracing.Start(tracing.Extract(ctx, tbs.TraceParent), tbs.CSRHash, "partial signature",
+		trace.WithSpanKind(trace.SpanKindServer))
+	defer span.End()
+	out = recordErr(span, out)
+	srv.coordinator.Mut.Lock()
 	info := srv.coordinator.Database[tbs.CSRHash]
-	if info == nil || !info.Validated {
-		srv.coordinator.Log.Error("CSR has not been validated.")
+	validated := info != nil && info.Validated
+	srv.coordinator.Mut.Unlock()
+	if !validated {
+		log.Error("CSR has not been validated.")
 		out(nil, fmt.Errorf("CSR has not been validated"))
 		return
 	}
 
 	cert, err := x509.ParseCertificate(tbs.Certificate)
 	if err != nil {
-		srv.coordinator.Log.Error("error parsing certificate")
+		log.Error("error parsing certificate: ", err)
+		out(nil, fmt.Errorf("error parsing certificate: %v", err))
+		return
+	}
+	// a faulty node mustn't get the group to sign anything but the certificate of the replicated CSR
+	if err := srv.checkIssuance(tbs.CSRHash, cert); err != nil {
+		log.Error("certificate to be signed doesn't match its CSR: ", err)
+		out(nil, fmt.Errorf("certificate doesn't match the CSR: %v", err))
+		return
 	}
 
 	partialSig, err := crypto.ComputePartialSignature(cert, srv.key)
 	if err != nil {
-		srv.coordinator.Log.Error("failed to compute a partial signature: ", err)
+		log.Error("failed to compute a partial signature: ", err)
 		out(nil, fmt.Errorf("failed to compute a partial signature"))
 		return
 	}
 
-	srv.coordinator.Log.Info("Successfully partially signed certificate for CSR ", tbs.CSRHash[:6])
+	log.Info("Successfully partially signed certificate")
+	srv.coordinator.Mut.Lock()
 	info.Signed = true
+	srv.coordinator.Mut.Unlock()
 
+	span.SetAttributes(attribute.Int("hotcertification.share_id", int(partialSig.Id)))
 	out(&SigShare{
 		Xi: partialSig.Xi,
 		C:  partialSig.C,
@@ -96,102 +185,707 @@ func (srv *signingServer) GetPartialSig(_ context.Context, tbs *TBS, out func(*S
 		nil)
 }
 
-func (srv *signingServer) GetFullSignature(csr *protocol.CSR) (*x509.Certificate, error) {
+// StoreCertificate is called by the node that issued a certificate so that every node can hand it out.
+func (srv *signingServer) StoreCertificate(_ context.Context, issued *IssuedCert, out func(*Ack, error)) {
+	cert, err := x509.ParseCertificate(issued.Certificate)
+	if err != nil {
+		srv.log.Error("error parsing certificate")
+		out(nil, fmt.Errorf("error parsing certificate"))
+		return
+	}
+
+	// only accept certificates that have been signed by the group
+	err = cert.CheckSignatureFrom(srv.rootCA)
+	if err != nil {
+		srv.log.Error("invalid signature on issued certificate: ", err)
+		out(nil, fmt.Errorf("invalid signature on issued certificate"))
+		return
+	}
+
+	// and only for the request they are handed in for, or a faulty peer could attach any certificate to it
+	if err := srv.checkIssuance(issued.CSRHash, cert); err != nil {
+		srv.log.Error("issued certificate doesn't match its CSR: ", err)
+		out(nil, fmt.Errorf("certificate doesn't match the CSR: %v", err))
+		return
+	}
+
+	srv.coordinator.Finish(issued.CSRHash, cert, nil)
+	out(&Ack{}, nil)
+}
+
+// checkIssuance checks that cert is the certificate or precertificate the CA issues for the CSR with the given
+// hash, which has to be known to this node.
+func (srv *signingServer) checkIssuance(hash string, cert *x509.Certificate) error {
+	if srv.rootCA == nil {
+		return fmt.Errorf("root certificate hasn't been read")
+	}
+	info, ok := srv.coordinator.Lookup(hash)
+	if !ok || info.CSR == nil {
+		return fmt.Errorf("unknown CSR")
+	}
+	csr, err := x509.ParseCertificateRequest(info.CSR.CertificateRequest)
+	if err != nil {
+		return err
+	}
+	return crypto.CheckIssuance(cert, csr, srv.rootCA)
+}
+
+// recordErr returns out that additionally records the error handed to it in span.
+func recordErr(span trace.Span, out func(*SigShare, error)) func(*SigShare, error) {
+	return func(share *SigShare, err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, err.Error())
+		}
+		out(share, err)
+	}
+}
+
+func (srv *signingServer) GetFullSignature(ctx context.Context, csr *protocol.CSR) (cert *x509.Certificate, err error) {
 
 	hash := hc.HashCSR(csr)
-	srv.coordinator.Log.Info("Initializing treshold signing session CSR ", hash[:6])
+	log := logging.With(srv.log, "csr", hash[:6])
+	log.Info("Initializing threshold signing session")
+
+	ctx, span := tracing.Start(ctx, hash, "signing session")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(otelcodes.Error, hc.ReasonOf(err).String())
+		}
+		span.End()
+	}()
 
 	x509csr, err := x509.ParseCertificateRequest(csr.CertificateRequest)
 	if err != nil {
-		srv.coordinator.Log.Error(err)
+		return nil, hc.NewRequestError(protocol.ErrorReason_INVALID_CSR, err, "malformed certificate request")
 	}
 
-	cert, err := crypto.GenerateCert(x509csr, srv.rootCA, srv.key)
+	var certOpts []crypto.CertOption
+	if len(srv.ctLogs) > 0 {
+		// the SCTs of the CT logs are embedded in the certificate, so a precertificate is signed and logged first
+		precert, err := crypto.GenerateCert(x509csr, srv.rootCA, srv.key, crypto.Precertificate())
+		if err != nil {
+			return nil, hc.NewRequestError(protocol.ErrorReason_INTERNAL, err, "failed to generate precertificate")
+		}
+		log.Info("Threshold signing precertificate")
+		precert, err = srv.thresholdSign(ctx, hash, precert, true)
+		if err != nil {
+			return nil, err
+		}
+		scts, err := srv.submitPrecert(ctx, hash, precert)
+		if err != nil {
+			return nil, err
+		}
+		certOpts = append(certOpts, crypto.FromPrecertificate(precert, ctlog.SerializeSCTList(scts)))
+	}
+
+	cert, err = crypto.GenerateCert(x509csr, srv.rootCA, srv.key, certOpts...)
 	if err != nil {
-		srv.coordinator.Log.Error(err)
+		return nil, hc.NewRequestError(protocol.ErrorReason_INTERNAL, err, "failed to generate certificate")
 	}
+	return srv.thresholdSign(ctx, hash, cert, false)
+}
+
+// thresholdSign collects signature shares on cert from the other nodes and combines them into the full signature.
+func (srv *signingServer) thresholdSign(ctx context.Context, hash string, cert *x509.Certificate, precert bool) (*x509.Certificate, error) {
+	log := logging.With(srv.log, "csr", hash[:6])
+	span := trace.SpanFromContext(ctx)
 
 	// TODO: rename to quorumAnswer? quorumOfReplies?
-	thresholdOf, err := srv.cfg.GetPartialSig(context.Background(), &TBS{CSRHash: hash, Certificate: cert.Raw})
+	collectCtx, collectSpan := tracing.Start(ctx, hash, "collect shares", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.Bool("hotcertification.precertificate", precert)))
+	tbs := &TBS{
+		CSRHash:     hash,
+		Certificate: cert.Raw,
+		TraceParent: tracing.Inject(collectCtx),
+	}
+	what := "certificate"
+	if precert {
+		what = "precertificate"
+	}
+	// a faulty peer can't spoil the signature as long as there are enough valid shares
+	done := srv.qspec.expect(tbs, what, func(share *tcrsa.SigShare) error {
+		return crypto.VerifyPartialSignature(cert, srv.key, share)
+	})
+	thresholdOf, err := srv.config().GetPartialSig(collectCtx, tbs)
+	done()
+	collectSpan.End()
 	if err != nil {
-		srv.coordinator.Log.Errorf("failed to get enough partial signatures.")
-		return nil, err
+		log.Errorf("failed to get enough valid partial signatures: %v", err)
+		return nil, hc.NewRequestError(protocol.ErrorReason_INSUFFICIENT_SHARES, err, "failed to get enough valid partial signatures")
+	}
+	if info, ok := srv.coordinator.Lookup(hash); ok && !info.Committed.IsZero() && !precert {
+		metrics.ObserveStage(metrics.StageShares, info.Committed)
 	}
 
 	// in tcrsa K is threshold and L is total number of participants
-	partialSigs := make(tcrsa.SigShareList, hc.QuorumSize(len(srv.cfg.Nodes())))
-	for i, share := range thresholdOf.SigShares {
-		partialSigs[i] = &tcrsa.SigShare{
-			Xi: share.GetXi(),
-			C:  share.GetC(),
-			Z:  share.GetZ(),
-			Id: uint16(share.GetId()),
-		}
-	}
+	partialSigs := thresholdOf.shares()
+	span.SetAttributes(attribute.Int("hotcertification.valid_shares", len(partialSigs)))
 
-	srv.coordinator.Log.Info("Computing full signature for certificate.")
+	log.Info("Computing full signature for certificate.")
+	start := time.Now()
+	_, combineSpan := tracing.Start(ctx, hash, "combine")
 	fullCert, err := crypto.ComputeFullySignedCert(cert, srv.key, partialSigs...)
+	combineSpan.End()
 	if err != nil {
-		return nil, fmt.Errorf("failed to compute full signature: %v", err)
+		return nil, hc.NewRequestError(protocol.ErrorReason_INTERNAL, err, "failed to compute full signature")
 	}
+	metrics.ObserveStage(metrics.StageCombine, start)
 
 	return fullCert, nil
 }
 
-func (srv *signingServer) Start(addr string) {
+// submitPrecert submits the precertificate to all configured CT logs at once and returns the SCTs of those that
+// answered, in the order the logs are configured. It fails unless at least minSCTs logs answered.
+func (srv *signingServer) submitPrecert(ctx context.Context, hash string, precert *x509.Certificate) ([]*ctlog.SCT, error) {
+	log := logging.With(srv.log, "csr", hash[:6])
+	ctx, span := tracing.Start(ctx, hash, "submit precertificate", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	start := time.Now()
+
+	scts := make([]*ctlog.SCT, len(srv.ctLogs))
+	var wg sync.WaitGroup
+	for i, client := range srv.ctLogs {
+		wg.Add(1)
+		go func(i int, client *ctlog.Client) {
+			defer wg.Done()
+			sct, err := client.AddPreChain(ctx, precert, srv.rootCA)
+			if err != nil {
+				log.Warnf("CT log didn't return an SCT: %v", err)
+				return
+			}
+			scts[i] = sct
+		}(i, client)
+	}
+	wg.Wait()
+
+	received := scts[:0]
+	for _, sct := range scts {
+		if sct != nil {
+			received = append(received, sct)
+		}
+	}
+	span.SetAttributes(attribute.Int("hotcertification.scts", len(received)))
+	if len(received) < srv.minSCTs {
+		return nil, hc.NewRequestError(protocol.ErrorReason_CT_UNAVAILABLE, nil,
+			"only %v of %v required CT logs returned an SCT", len(received), srv.minSCTs)
+	}
+	metrics.ObserveStage(metrics.StageSCTs, start)
+
+	return received, nil
+}
+
+// Start serves the signing service on addr and runs signing sessions until ctx is cancelled. The sessions still
+// running then are aborted and their results delivered before the server is stopped and Start returns.
+func (srv *signingServer) Start(ctx context.Context, addr string) error {
 	// open port
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
-		fmt.Println(err)
+		return err
 	}
 	// starting listener
 	go srv.backendSrv.Serve(lis)
+	defer srv.backendSrv.Stop()
+	defer srv.mgr.Close()
 
 	// creating configuration (group of signers)
-	time.Sleep(10 * time.Second)
+	if err := srv.connect(ctx); err != nil {
+		return fmt.Errorf("failed to set up signing configuration: %w", err)
+	}
+	// no client is served unless the shares of the replicas combine into signatures of the CA
+	if err := srv.selfTest(ctx); err != nil {
+		return fmt.Errorf("self-test of the threshold key failed: %w", err)
+	}
+
+	// ready as long as a quorum of signers can be reached
+	go srv.coordinator.Readiness.WatchPeers(ctx, hc.SubsystemSigning, srv.nodes)
+	go srv.signCheckpoints(ctx)
+	if srv.sequencer {
+		go srv.sequenceLog(ctx)
+	}
+
+	srv.log.Infof("Signing server listening on %v with %v workers.", addr, srv.pool.size)
+
+	// blocks until ctx is cancelled
+	srv.pool.run(ctx, srv.coordinator.SigningQueue, func(csr *protocol.CSR, cert *x509.Certificate, err error) {
+		hash := hc.HashCSR(csr)
+		if err != nil {
+			logging.With(srv.log, "csr", hash[:6]).Errorf("Couldn't generate full signature: %v", err)
+			srv.coordinator.Finish(hash, nil, err)
+			return
+		}
+		metrics.CertificatesIssued.Inc()
+		srv.coordinator.Finish(hash, cert, nil)
+		go srv.distribute(ctx, hash, cert)
+	})
+	srv.log.Info("Signing server stopped.")
+	return nil
+}
+
+// config returns the configuration of the signers connected so far.
+func (srv *signingServer) config() *Configuration {
+	srv.cfgMut.RLock()
+	defer srv.cfgMut.RUnlock()
+	return srv.cfg
+}
+
+// connect sets up the configuration of signers. It returns once a quorum of them has been connected, which is all
+// it takes to sign, and keeps connecting the others in the background, backing off between attempts.
+func (srv *signingServer) connect(ctx context.Context) error {
+	// signers that have just been started might not be listening yet
+	err := srv.coordinator.Readiness.WaitForQuorum(ctx, hc.SubsystemSigning, srv.nodes, srv.backoff)
+	if err != nil {
+		return err
+	}
+
+	qspec := srv.qspec
+	ready := make(chan struct{})
+	go func() {
+		connected := make(map[string]bool, len(srv.nodes))
+		err := hc.Retry(ctx, srv.backoff, func(context.Context) error {
+			err := srv.addSigners(qspec, connected)
+			if srv.config() != nil {
+				select {
+				case <-ready:
+				default:
+					close(ready)
+				}
+			}
+			if err != nil {
+				srv.log.Warnf("Failed to connect to signers: %v", err)
+			}
+			return err
+		})
+		if err == nil {
+			srv.log.Info("Connected to all signers.")
+		}
+	}()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// addSigners dials the signers that aren't connected yet and, once a quorum of them is, replaces the configuration
+// with one of all connected signers. It fails unless all signers have been connected.
+func (srv *signingServer) addSigners(qspec *QSpec, connected map[string]bool) error {
+	var (
+		mut     sync.Mutex
+		wg      sync.WaitGroup
+		added   int
+		failed  []string
+		lastErr error
+	)
+	for _, addr := range srv.nodes {
+		if connected[addr] {
+			continue
+		}
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			// the manager keeps the node once it has been connected, so that later configurations reuse it
+			_, err := srv.mgr.NewConfiguration(qspec, gorums.WithNodeList([]string{addr}))
+			mut.Lock()
+			defer mut.Unlock()
+			if err != nil {
+				failed = append(failed, addr)
+				lastErr = err
+				return
+			}
+			connected[addr] = true
+			added++
+		}(addr)
+	}
+	wg.Wait()
+
+	if added > 0 && len(connected) >= qspec.quorumSize {
+		addrs := make([]string, 0, len(connected))
+		for _, addr := range srv.nodes {
+			if connected[addr] {
+				addrs = append(addrs, addr)
+			}
+		}
+		cfg, err := srv.mgr.NewConfiguration(qspec, gorums.WithNodeList(addrs))
+		if err != nil {
+			return err
+		}
+		srv.cfgMut.Lock()
+		srv.cfg = cfg
+		srv.cfgMut.Unlock()
+		srv.log.Infof("Signing configuration has %v of %v signers.", len(addrs), len(srv.nodes))
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("%v unreachable: %w", failed, lastErr)
+	}
+	return nil
+}
+
+// Peer is the connection of the signing server to another node.
+type Peer struct {
+	ID        hotstuff.ID
+	Address   string
+	Connected bool          // the node has been reachable when it has been probed last
+	Err       error         // why the node hasn't been reachable
+	Latency   time.Duration // time it took to connect to the node when it has been probed last
+}
+
+// Peers returns the state of the connections to all nodes of the cluster, in the order they are configured.
+// No node is connected until the signing configuration has been set up.
+func (srv *signingServer) Peers() []Peer {
+	// the last error of a gorums node sticks after it has reconnected, so the nodes are probed instead
+	probes, probed := srv.coordinator.Readiness.Peers(hc.SubsystemSigning)
+
+	peers := make([]Peer, len(srv.nodes))
+	for i, addr := range srv.nodes {
+		peers[i] = Peer{ID: srv.nodeIDs[i], Address: addr}
+		if p, ok := probes[addr]; probed && ok {
+			peers[i].Connected = p.Err == nil
+			peers[i].Err = p.Err
+			peers[i].Latency = p.Latency
+		}
+	}
+	return peers
+}
+
+// signCheckpoints collects a threshold signature for each checkpoint of the audit log until ctx is cancelled.
+func (srv *signingServer) signCheckpoints(ctx context.Context) {
+	for {
+		select {
+		case cp := <-srv.coordinator.Audit.Checkpoints():
+			err := srv.signCheckpoint(ctx, cp)
+			if err != nil {
+				srv.log.Errorf("Failed to sign audit checkpoint after %v commits: %v", cp.Commit, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
 
-TRY:
-	signersConfig, err := srv.mgr.NewConfiguration(
-		&QSpec{hc.QuorumSize(len(srv.nodes))},
-		gorums.WithNodeList(srv.nodes))
+func (srv *signingServer) signCheckpoint(ctx context.Context, cp audit.Checkpoint) error {
+	ctx, cancel := context.WithTimeout(ctx, checkpointTimeout)
+	defer cancel()
+
+	digest := audit.CheckpointDigest(cp.Commit, cp.Digest)
+	in := &Checkpoint{Commit: cp.Commit, Digest: cp.Digest}
+	done := srv.qspec.expect(in, "audit checkpoint", srv.verifyDigest(digest, audit.CheckpointHash))
+	thresholdOf, err := srv.config().SignCheckpoint(ctx, in)
+	done()
 	if err != nil {
-		srv.coordinator.Log.Error("failed to initialize signing configuration: ", err)
-		err = nil
-		goto TRY
-		//os.Exit(1)
+		return err
 	}
 
-	srv.cfg = signersConfig
+	signature, err := crypto.JoinDigestShares(digest, audit.CheckpointHash, srv.key, thresholdOf.shares()...)
+	if err != nil {
+		return err
+	}
+
+	srv.log.Infof("Signed audit checkpoint after %v commits.", cp.Commit)
+	return srv.coordinator.Audit.AddCheckpoint(cp, signature)
+}
+
+// verifyDigest returns a function that verifies a signature share on digest, which has been hashed with hashType.
+func (srv *signingServer) verifyDigest(digest []byte, hashType stdcrypto.Hash) func(*tcrsa.SigShare) error {
+	return func(share *tcrsa.SigShare) error {
+		return crypto.VerifyDigestShare(digest, hashType, srv.key, share)
+	}
+}
+
+// SignCheckpoint contributes a signature share to a checkpoint of the audit log if this replica committed the
+// same commands up to the checkpoint.
+func (srv *signingServer) SignCheckpoint(ctx context.Context, cp *Checkpoint, out func(*SigShare, error)) {
+	// this replica might not have executed all commands up to the checkpoint yet; waiting for them must not block
+	// the other requests
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, checkpointTimeout)
+		defer cancel()
 
-	srv.coordinator.Log.Infof("Signing server listening on %v.", addr)
+		digest, err := srv.coordinator.Audit.CommitDigest(ctx, cp.Commit)
+		if err != nil {
+			out(nil, fmt.Errorf("no checkpoint after %v commits: %v", cp.Commit, err))
+			return
+		}
+		if !bytes.Equal(digest, cp.Digest) {
+			srv.log.Errorf("Audit checkpoint after %v commits differs from own log.", cp.Commit)
+			out(nil, fmt.Errorf("checkpoint after %v commits differs", cp.Commit))
+			return
+		}
+
+		share, err := crypto.SignDigestShare(audit.CheckpointDigest(cp.Commit, digest), audit.CheckpointHash, srv.key)
+		if err != nil {
+			out(nil, fmt.Errorf("failed to compute a signature share"))
+			return
+		}
+		out(&SigShare{Xi: share.Xi, C: share.C, Z: share.Z, Id: uint32(share.Id)}, nil)
+	}()
+}
+
+// sequenceLog regularly adds the certificates issued in the meantime to the certificate log and gets the new tree
+// head signed by the replicas until ctx is cancelled.
+func (srv *signingServer) sequenceLog(ctx context.Context) {
+	ticker := time.NewTicker(srv.sthInterval)
+	defer ticker.Stop()
 
-	// TODO: add cancel function through context
 	for {
-		csr := <-srv.coordinator.SigningQueue
-		cert, err := srv.GetFullSignature(csr)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		ext := srv.coordinator.CTLog.NextExtension(time.Now())
+		if ext == nil {
+			continue
+		}
+		if err := srv.extendLog(ctx, ext); err != nil {
+			srv.log.Errorf("Failed to extend certificate log of %v entries: %v", ext.TreeSize, err)
+		}
+	}
+}
+
+func (srv *signingServer) extendLog(ctx context.Context, ext *ctlog.Extension) error {
+	ctx, cancel := context.WithTimeout(ctx, extensionTimeout)
+	defer cancel()
+
+	sth, err := srv.coordinator.CTLog.Extend(ext)
+	if err != nil {
+		return err
+	}
+
+	in := &LogExtension{
+		TreeSize:     ext.TreeSize,
+		Timestamp:    ext.Timestamp,
+		Certificates: ext.Certificates,
+	}
+	done := srv.qspec.expect(in, "tree head", srv.verifyDigest(sth.Digest(), ctlog.DigestHash))
+	thresholdOf, err := srv.config().ExtendLog(ctx, in)
+	done()
+	if err != nil {
+		return err
+	}
+
+	sth.Signature, err = crypto.JoinDigestShares(sth.Digest(), ctlog.DigestHash, srv.key, thresholdOf.shares()...)
+	if err != nil {
+		return err
+	}
+	if err := srv.coordinator.CTLog.AddSTH(sth); err != nil {
+		return err
+	}
+	srv.log.Infof("Signed tree head of certificate log with %v entries.", sth.TreeSize)
+
+	_, err = srv.config().PublishSTH(ctx, &SignedTreeHead{
+		TreeSize:  sth.TreeSize,
+		Timestamp: sth.Timestamp,
+		RootHash:  sth.RootHash,
+		Signature: sth.Signature,
+	})
+	return err
+}
+
+// ExtendLog appends the certificates of an extension by the sequencer to the certificate log and contributes a
+// signature share to the new tree head.
+func (srv *signingServer) ExtendLog(_ context.Context, ext *LogExtension, out func(*SigShare, error)) {
+	// only certificates issued by the CA are logged
+	for _, raw := range ext.Certificates {
+		cert, err := x509.ParseCertificate(raw)
+		if err == nil {
+			err = cert.CheckSignatureFrom(srv.rootCA)
+		}
 		if err != nil {
-			srv.coordinator.Log.Errorf("Couldn't generate full signature: %v", err)
-			srv.coordinator.FinishedCerts <- &protocol.Certificate{}
-		} else {
-			// wrap x509 cert and convert to ASN.1 DER encoded byte array
-			srv.coordinator.FinishedCerts <- &protocol.Certificate{Certificate: cert.Raw}
+			srv.log.Error("invalid certificate in extension of certificate log: ", err)
+			out(nil, fmt.Errorf("invalid certificate in extension"))
+			return
 		}
+	}
 
+	sth, err := srv.coordinator.CTLog.Extend(&ctlog.Extension{
+		TreeSize:     ext.TreeSize,
+		Timestamp:    ext.Timestamp,
+		Certificates: ext.Certificates,
+	})
+	if err != nil {
+		srv.log.Errorf("Can't extend certificate log: %v", err)
+		out(nil, err)
+		return
 	}
+
+	share, err := crypto.SignDigestShare(sth.Digest(), ctlog.DigestHash, srv.key)
+	if err != nil {
+		out(nil, fmt.Errorf("failed to compute a signature share"))
+		return
+	}
+	out(&SigShare{Xi: share.Xi, C: share.C, Z: share.Z, Id: uint32(share.Id)}, nil)
 }
 
+// PublishSTH records a tree head of the certificate log signed by the replicas.
+func (srv *signingServer) PublishSTH(_ context.Context, sth *SignedTreeHead, out func(*Ack, error)) {
+	err := srv.coordinator.CTLog.AddSTH(&ctlog.SignedTreeHead{
+		TreeSize:  sth.TreeSize,
+		Timestamp: sth.Timestamp,
+		RootHash:  sth.RootHash,
+		Signature: sth.Signature,
+	})
+	if err != nil {
+		srv.log.Errorf("Rejected tree head of certificate log: %v", err)
+		out(nil, err)
+		return
+	}
+	out(&Ack{}, nil)
+}
+
+// sequencerOf returns the ID of the node that sequences the certificate log.
+func sequencerOf(opts *hc.Options) hotstuff.ID {
+	if opts.LogSequencer == 0 {
+		return 1
+	}
+	return opts.LogSequencer
+}
+
+// distribute hands an issued certificate to the other nodes so that clients can fetch it from any of them.
+func (srv *signingServer) distribute(ctx context.Context, hash string, cert *x509.Certificate) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := srv.config().StoreCertificate(ctx, &IssuedCert{CSRHash: hash, Certificate: cert.Raw})
+	if err != nil {
+		logging.With(srv.log, "csr", hash[:6]).Errorf("failed to distribute certificate: %v", err)
+	}
+}
+
+// QSpec decides when enough signers have replied to a quorum call. Signature shares are verified as they arrive,
+// so that a call collecting them only returns once there is a threshold of valid ones.
 type QSpec struct {
 	quorumSize int
+	log        logging.Logger
+	progress   func(hash, what string, shares, threshold int) // called every time a valid signature share arrives
+	sessions   sync.Map                                       // *shareSession by the request of the quorum call
 }
 
-func (qs *QSpec) GetPartialSigQF(_ *TBS, sigShares map[uint32]*SigShare) (*ThresholdOf, bool) {
-	if len(sigShares) < qs.quorumSize {
-		return nil, false
+// shareSession holds the signature shares a quorum call has collected so far.
+type shareSession struct {
+	what     string                      // what is signed
+	verify   func(*tcrsa.SigShare) error // verifies a share on what is signed
+	verified map[uint32]bool             // nodes whose share has been verified
+	valid    map[uint16]*SigShare        // valid shares by the ID of the key share
+	reported int                         // number of valid shares reported as progress
+}
+
+// expect registers how the signature shares replied to the quorum call with the request in are verified; what
+// describes what is signed. The returned function has to be called once the call has returned.
+func (qs *QSpec) expect(in interface{}, what string, verify func(*tcrsa.SigShare) error) (done func()) {
+	qs.sessions.Store(in, &shareSession{
+		what:     what,
+		verify:   verify,
+		verified: make(map[uint32]bool),
+		valid:    make(map[uint16]*SigShare),
+	})
+	return func() { qs.sessions.Delete(in) }
+}
+
+// validShares verifies the shares of the quorum call with the request in that haven't been verified yet and returns
+// the valid ones so far. The replies of a quorum call are handed to its quorum function one after another, so each
+// share is verified once.
+func (qs *QSpec) validShares(in interface{}, sigShares map[uint32]*SigShare) []*SigShare {
+	v, ok := qs.sessions.Load(in)
+	if !ok {
+		// shares are never used without having been verified
+		return nil
 	}
-	// TODO: is there a more efficient way?
-	shares := []*SigShare{}
-	for _, share := range sigShares {
+	session := v.(*shareSession)
+
+	for node, share := range sigShares {
+		if session.verified[node] {
+			continue
+		}
+		session.verified[node] = true
+
+		id := uint16(share.GetId())
+		if _, ok := session.valid[id]; ok {
+			qs.log.Errorf("Signature share %v on %v has been sent twice", id, session.what)
+			continue
+		}
+		if err := session.verify(share.tcrsa()); err != nil {
+			qs.log.Errorf("Invalid signature share %v on %v: %v", id, session.what, err)
+			metrics.InvalidShares.WithLabelValues(strconv.Itoa(int(id))).Inc()
+			continue
+		}
+		session.valid[id] = share
+	}
+
+	shares := make([]*SigShare, 0, len(session.valid))
+	for _, share := range session.valid {
 		shares = append(shares, share)
 	}
+	return shares
+}
+
+// reportProgress reports the number of valid shares the quorum call with the request in has collected for the
+// request with the given hash if it has grown since the last report. Invalid and duplicate shares aren't reported.
+func (qs *QSpec) reportProgress(in interface{}, hash string, shares int) {
+	v, ok := qs.sessions.Load(in)
+	if !ok || qs.progress == nil {
+		return
+	}
+	session := v.(*shareSession)
+	if shares <= session.reported {
+		return
+	}
+	session.reported = shares
+	qs.progress(hash, session.what, shares, qs.quorumSize)
+}
+
+// quorum returns the valid shares once there are quorumSize of them.
+func (qs *QSpec) quorum(shares []*SigShare) (*ThresholdOf, bool) {
+	if len(shares) < qs.quorumSize {
+		return nil, false
+	}
 	return &ThresholdOf{SigShares: shares}, true
 }
+
+// tcrsa returns the share in the form of the tcrsa package.
+func (share *SigShare) tcrsa() *tcrsa.SigShare {
+	return &tcrsa.SigShare{Xi: share.GetXi(), C: share.GetC(), Z: share.GetZ(), Id: uint16(share.GetId())}
+}
+
+// shares returns the signature shares in the form of the tcrsa package.
+func (thresholdOf *ThresholdOf) shares() tcrsa.SigShareList {
+	shares := make(tcrsa.SigShareList, len(thresholdOf.GetSigShares()))
+	for i, share := range thresholdOf.GetSigShares() {
+		shares[i] = share.tcrsa()
+	}
+	return shares
+}
+
+func (qs *QSpec) GetPartialSigQF(tbs *TBS, sigShares map[uint32]*SigShare) (*ThresholdOf, bool) {
+	shares := qs.validShares(tbs, sigShares)
+	qs.reportProgress(tbs, tbs.CSRHash, len(shares))
+	return qs.quorum(shares)
+}
+
+func (qs *QSpec) SignCheckpointQF(cp *Checkpoint, sigShares map[uint32]*SigShare) (*ThresholdOf, bool) {
+	return qs.quorum(qs.validShares(cp, sigShares))
+}
+
+func (qs *QSpec) ExtendLogQF(ext *LogExtension, sigShares map[uint32]*SigShare) (*ThresholdOf, bool) {
+	return qs.quorum(qs.validShares(ext, sigShares))
+}
+
+func (qs *QSpec) PublishSTHQF(_ *SignedTreeHead, acks map[uint32]*Ack) (*Ack, bool) {
+	if len(acks) < qs.quorumSize {
+		return nil, false
+	}
+	return &Ack{}, true
+}
+
+func (qs *QSpec) StoreCertificateQF(_ *IssuedCert, acks map[uint32]*Ack) (*Ack, bool) {
+	if len(acks) < qs.quorumSize {
+		return nil, false
+	}
+	return &Ack{}, true
+}
@@ -0,0 +1,91 @@
+package signing
+
+import (
+	"context"
+	stdcrypto "crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/niclabs/tcrsa"
+	"github.com/relab/hotstuff"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/logging"
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// issueFor returns a certificate of the CA for a new CSR, and the CSR.
+func issueFor(t *testing.T, keys []*crypto.ThresholdKey, root *x509.Certificate) (*x509.Certificate, *protocol.CSR) {
+	t.Helper()
+	clientKey, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := crypto.GenerateCSR(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := crypto.GenerateCert(req, root, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares := make(tcrsa.SigShareList, 3)
+	for i, key := range keys[:3] {
+		if shares[i], err = crypto.ComputePartialSignature(cert, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if cert, err = crypto.ComputeFullySignedCert(cert, keys[0], shares...); err != nil {
+		t.Fatal(err)
+	}
+	return cert, &protocol.CSR{ClientID: 1, CertificateRequest: req.Raw}
+}
+
+// TestStoreCertificate checks that a node only takes certificates of the CA that have been issued for the CSR they
+// are handed in for.
+func TestStoreCertificate(t *testing.T) {
+	keys, root, err := crypto.GenerateCA(3, 4, 512, stdcrypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := hc.NewCoordinator(&hc.Options{})
+	srv := &signingServer{rootCA: root, coordinator: c, log: logging.New("signing")}
+
+	cert, csr := issueFor(t, keys, root)
+	other, otherCSR := issueFor(t, keys, root)
+	cmd, err := c.Marshaler.Marshal(csr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Accept(hotstuff.Command(cmd)) {
+		t.Fatal("command has been rejected")
+	}
+	hash := hc.HashCSR(csr)
+
+	store := func(hash string, cert *x509.Certificate) error {
+		var err error
+		srv.StoreCertificate(context.Background(), &IssuedCert{CSRHash: hash, Certificate: cert.Raw}, func(_ *Ack, e error) { err = e })
+		return err
+	}
+
+	// the certificate of another request is refused, as is one for a request the node doesn't know
+	if err := store(hash, other); err == nil {
+		t.Error("certificate for another CSR has been stored")
+	}
+	if err := store(hc.HashCSR(otherCSR), other); err == nil {
+		t.Error("certificate for an unknown CSR has been stored")
+	}
+	if info, _ := c.Lookup(hash); info.Certificate != nil {
+		t.Fatal("request has been completed with the certificate of another one")
+	}
+
+	if err := store(hash, cert); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := c.Lookup(hash); info.Certificate == nil || !info.Certificate.Equal(cert) {
+		t.Error("certificate hasn't been stored")
+	}
+}
@@ -0,0 +1,108 @@
+package signing
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// signFunc runs one complete threshold signing session for a CSR.
+type signFunc func(ctx context.Context, csr *protocol.CSR) (*x509.Certificate, error)
+
+// deliverFunc receives the outcome of a signing session.
+type deliverFunc func(csr *protocol.CSR, cert *x509.Certificate, err error)
+
+// job is a CSR taken from the signing queue together with the slot its result is delivered to.
+type job struct {
+	csr    *protocol.CSR
+	result chan result
+}
+
+type result struct {
+	cert *x509.Certificate
+	err  error
+}
+
+// The worker pool runs up to size signing sessions (certificate construction, quorum call and
+// combination of the signature shares) at the same time. Results are still handed out in the order
+// in which the CSRs were taken from the queue.
+type workerPool struct {
+	size    int
+	timeout time.Duration // per request; no timeout if zero
+	sign    signFunc
+}
+
+func newWorkerPool(size int, timeout time.Duration, sign signFunc) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	return &workerPool{
+		size:    size,
+		timeout: timeout,
+		sign:    sign,
+	}
+}
+
+// run consumes CSRs from in until ctx is cancelled and passes the outcome of every signing session to deliver.
+func (p *workerPool) run(ctx context.Context, in <-chan *protocol.CSR, deliver deliverFunc) {
+	jobs := make(chan *job)
+	// jobs are appended to pending in the order they were dispatched, which is the order of delivery
+	pending := make(chan *job, p.size)
+
+	for i := 0; i < p.size; i++ {
+		go p.worker(ctx, jobs)
+	}
+
+	// the collector waits for the result of the oldest pending job and passes it on
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for j := range pending {
+			res := <-j.result
+			deliver(j.csr, res.cert, res.err)
+		}
+	}()
+
+	// the jobs still running are aborted through ctx; run only returns once their results have been delivered
+	defer func() {
+		close(pending)
+		close(jobs)
+		<-collected
+	}()
+
+	// the dispatcher hands every CSR to the next free worker
+	for {
+		select {
+		case csr := <-in:
+			j := &job{csr: csr, result: make(chan result, 1)}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+			pending <- j
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *workerPool) worker(ctx context.Context, jobs <-chan *job) {
+	for j := range jobs {
+		j.result <- p.process(ctx, j.csr)
+	}
+}
+
+func (p *workerPool) process(ctx context.Context, csr *protocol.CSR) result {
+	// every signing session gets its own context so a slow quorum call only times out itself
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	cert, err := p.sign(ctx, csr)
+	return result{cert: cert, err: err}
+}
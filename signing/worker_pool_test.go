@@ -0,0 +1,137 @@
+package signing
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/raphasch/hotcertification/protocol"
+)
+
+// fakeSign simulates a signing session that takes latency and returns the ClientID as the certificate.
+func fakeSign(latency func() time.Duration) signFunc {
+	return func(ctx context.Context, csr *protocol.CSR) (*x509.Certificate, error) {
+		select {
+		case <-time.After(latency()):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		raw := make([]byte, 4)
+		binary.BigEndian.PutUint32(raw, csr.ClientID)
+		return &x509.Certificate{Raw: raw}, nil
+	}
+}
+
+// collect passes the issued certificates on to out.
+func collect(out chan<- *x509.Certificate) deliverFunc {
+	return func(_ *protocol.CSR, cert *x509.Certificate, _ error) {
+		out <- cert
+	}
+}
+
+func TestWorkerPoolOrder(t *testing.T) {
+	const num = 100
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan *protocol.CSR, num)
+	out := make(chan *x509.Certificate, num)
+
+	pool := newWorkerPool(8, time.Second, fakeSign(func() time.Duration {
+		return time.Duration(rand.Intn(5)) * time.Millisecond
+	}))
+	go pool.run(ctx, in, collect(out))
+
+	for i := 0; i < num; i++ {
+		in <- &protocol.CSR{ClientID: uint32(i)}
+	}
+
+	for i := 0; i < num; i++ {
+		cert := <-out
+		if id := binary.BigEndian.Uint32(cert.Raw); id != uint32(i) {
+			t.Fatalf("results out of order: got %v, want %v", id, i)
+		}
+	}
+}
+
+func TestWorkerPoolTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan *protocol.CSR, 1)
+	errs := make(chan error, 1)
+
+	pool := newWorkerPool(1, 10*time.Millisecond, fakeSign(func() time.Duration { return time.Second }))
+	go pool.run(ctx, in, func(_ *protocol.CSR, _ *x509.Certificate, err error) { errs <- err })
+
+	in <- &protocol.CSR{}
+	if err := <-errs; err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestWorkerPoolCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan *protocol.CSR, 2)
+	errs := make(chan error, 2)
+
+	pool := newWorkerPool(2, 0, fakeSign(func() time.Duration { return time.Hour }))
+	stopped := make(chan struct{})
+	go func() {
+		pool.run(ctx, in, func(_ *protocol.CSR, _ *x509.Certificate, err error) { errs <- err })
+		close(stopped)
+	}()
+
+	in <- &protocol.CSR{ClientID: 1}
+	in <- &protocol.CSR{ClientID: 2}
+	for len(in) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	// the aborted sessions are delivered before run returns
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("run didn't return after cancel")
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got %v results, want 2", len(errs))
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != context.Canceled {
+			t.Errorf("expected canceled, got %v", err)
+		}
+	}
+}
+
+// BenchmarkWorkerPool shows how the throughput scales with the number of workers when every
+// signing session is dominated by the latency of the quorum call.
+func BenchmarkWorkerPool(b *testing.B) {
+	for _, workers := range []int{1, 2, 4, 8, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			in := make(chan *protocol.CSR, b.N)
+			out := make(chan *x509.Certificate, b.N)
+
+			pool := newWorkerPool(workers, 0, fakeSign(func() time.Duration { return time.Millisecond }))
+			go pool.run(ctx, in, collect(out))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				in <- &protocol.CSR{ClientID: uint32(i)}
+			}
+			for i := 0; i < b.N; i++ {
+				<-out
+			}
+		})
+	}
+}
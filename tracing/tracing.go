@@ -0,0 +1,149 @@
+// Package tracing traces requests through the certification process with OpenTelemetry.
+//
+// All spans of a request belong to the trace whose ID is derived from the hash of its CSR, so the spans
+// recorded by the different replicas end up in the same trace without the replicas having to agree on an ID.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/exporters/stdout"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters that spans can be sent to
+const (
+	ExporterNone = ""     // tracing is disabled
+	ExporterOTLP = "otlp" // spans are sent to an OpenTelemetry collector; the endpoint is its address
+	ExporterFile = "file" // spans are written to a file as JSON; the endpoint is its path
+)
+
+// HashKey is the attribute holding the hash of the CSR a span belongs to.
+const HashKey = attribute.Key("hotcertification.csr_hash")
+
+const instrumentationName = "github.com/raphasch/hotcertification"
+
+// Setup installs the global tracer provider exporting to the given exporter. The returned function flushes
+// the remaining spans and has to be called before the program exits.
+func Setup(ctx context.Context, exporter, endpoint, service string, id int) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exp sdktrace.SpanExporter
+	switch exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exp, err = otlp.NewExporter(ctx, otlpgrpc.NewDriver(
+			otlpgrpc.WithInsecure(),
+			otlpgrpc.WithEndpoint(endpoint),
+		))
+	case ExporterFile:
+		var f *os.File
+		f, err = os.OpenFile(endpoint, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		exp, err = stdout.NewExporter(stdout.WithWriter(f), stdout.WithoutMetricExport())
+	default:
+		return nil, fmt.Errorf("unknown trace exporter '%v'", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.ServiceNameKey.String(service),
+			semconv.ServiceInstanceIDKey.String(strconv.Itoa(id)),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// RequestSpanContext returns the span context of the (never recorded) root span of the request identified
+// by the hash of its CSR. Its trace ID are the first 16 bytes of the hash and its span ID the next 8 bytes.
+func RequestSpanContext(hash string) trace.SpanContext {
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+
+	b, err := hex.DecodeString(hash)
+	if err != nil || len(b) < len(traceID)+len(spanID) {
+		return trace.SpanContext{}
+	}
+	copy(traceID[:], b)
+	copy(spanID[:], b[len(traceID):])
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+}
+
+// Start starts a span of the request identified by hash. The span is a child of the span in ctx if that belongs
+// to the trace of the request; otherwise it is put into the trace of the request and linked to the span in ctx.
+func Start(ctx context.Context, hash, name string, opts ...trace.SpanOption) (context.Context, trace.Span) {
+	opts = append(opts, trace.WithAttributes(HashKey.String(hash)))
+
+	root := RequestSpanContext(hash)
+	if current := trace.SpanContextFromContext(ctx); root.IsValid() && current.TraceID() != root.TraceID() {
+		if current.IsValid() {
+			opts = append(opts, trace.WithLinks(trace.Link{SpanContext: current}))
+		}
+		ctx = trace.ContextWithRemoteSpanContext(ctx, root)
+	}
+
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// Inject returns the W3C traceparent of the span in ctx so that it can be sent along with a message.
+func Inject(ctx context.Context) string {
+	c := carrier{}
+	propagation.TraceContext{}.Inject(ctx, c)
+	return c.Get(traceParentKey)
+}
+
+// Extract returns ctx carrying the remote span described by a traceparent created by Inject.
+func Extract(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier{traceParentKey: traceParent})
+}
+
+const traceParentKey = "traceparent"
+
+// carrier holds the fields of the trace context that are sent along with a message.
+type carrier map[string]string
+
+func (c carrier) Get(key string) string {
+	return c[key]
+}
+
+func (c carrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c carrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,87 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/raphasch/hotcertification/tracing"
+)
+
+const hash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestRequestSpanContext(t *testing.T) {
+	sc := tracing.RequestSpanContext(hash)
+	if !sc.IsValid() || !sc.IsSampled() {
+		t.Fatalf("span context of request is invalid or not sampled: %v", sc)
+	}
+	if got, want := sc.TraceID().String(), hash[:32]; got != want {
+		t.Errorf("trace ID is %v; want %v", got, want)
+	}
+	if got, want := sc.SpanID().String(), hash[32:48]; got != want {
+		t.Errorf("span ID is %v; want %v", got, want)
+	}
+
+	if tracing.RequestSpanContext("not a hash").IsValid() {
+		t.Error("span context derived from an invalid hash is valid")
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	sc := tracing.RequestSpanContext(hash)
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	traceParent := tracing.Inject(ctx)
+	if traceParent == "" {
+		t.Fatal("no traceparent injected")
+	}
+
+	got := trace.SpanContextFromContext(tracing.Extract(context.Background(), traceParent))
+	if got.TraceID() != sc.TraceID() || got.SpanID() != sc.SpanID() {
+		t.Errorf("extracted span context %v; want %v", got, sc)
+	}
+	if !got.IsRemote() {
+		t.Error("extracted span context isn't remote")
+	}
+}
+
+func TestStartJoinsRequestTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	// a span of a trace the client started on its own
+	ctx, client := otel.Tracer("test").Start(context.Background(), "client")
+	client.End()
+
+	_, span := tracing.Start(ctx, hash, "request")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %v spans; want 2", len(spans))
+	}
+	request := spans[1]
+
+	root := tracing.RequestSpanContext(hash)
+	if request.SpanContext.TraceID() != root.TraceID() || request.Parent.SpanID() != root.SpanID() {
+		t.Errorf("span isn't a child of the root span of the request")
+	}
+	if len(request.Links) != 1 || request.Links[0].SpanContext.SpanID() != client.SpanContext().SpanID() {
+		t.Errorf("span isn't linked to the span of the client: %v", request.Links)
+	}
+
+	// spans started within the trace of the request are plain children
+	ctx = trace.ContextWithSpanContext(context.Background(), request.SpanContext)
+	_, child := tracing.Start(ctx, hash, "child")
+	child.End()
+
+	spans = exporter.GetSpans()
+	if got := spans[len(spans)-1]; got.Parent.SpanID() != request.SpanContext.SpanID() || len(got.Links) != 0 {
+		t.Errorf("span isn't a child of the span of the request")
+	}
+}
@@ -0,0 +1,251 @@
+package hotcertification
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/relab/hotstuff"
+
+	"github.com/raphasch/hotcertification/crypto"
+	"github.com/raphasch/hotcertification/crypto/keystore"
+)
+
+// ConfigError lists everything that is wrong with a configuration.
+type ConfigError struct {
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// problems collects the problems of a configuration.
+type problems []string
+
+func (p *problems) add(format string, args ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ConfigError{Problems: p}
+}
+
+// Self returns the entry of this node in the node list; nil if there is none.
+func (opts *Options) Self() *Node {
+	return opts.Node(opts.ID)
+}
+
+// Node returns the entry of the node with the given ID; nil if there is none.
+func (opts *Options) Node(id hotstuff.ID) *Node {
+	for i := range opts.Nodes {
+		if opts.Nodes[i].ID == id {
+			return &opts.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Validate checks that the configuration is complete and consistent and that the files it refers to exist.
+// It returns a *ConfigError listing all problems at once.
+func Validate(opts *Options) error {
+	var p problems
+
+	validateNodes(opts, &p)
+
+	if opts.ID == 0 {
+		p.add("id of this node is missing")
+	} else if self := opts.Self(); self == nil {
+		p.add("node %v isn't in the node list", opts.ID)
+	} else {
+		if self.AdminAddr != "" {
+			fileExists(&p, "admin-cert of this node", self.AdminCert)
+			fileExists(&p, "admin-key of this node", self.AdminKey)
+			fileExists(&p, "admin-ca", opts.AdminCA)
+		}
+		if opts.TLS {
+			fileExists(&p, "tls-cert of this node", self.TLSCert)
+		}
+	}
+
+	if keystore.IsPKCS11(opts.ThresholdKey) {
+		if _, err := keystore.ParsePKCS11URI(opts.ThresholdKey); err != nil {
+			p.add("thresholdkey: %v", err)
+		}
+	} else {
+		fileExists(&p, "thresholdkey", opts.ThresholdKey)
+	}
+	fileExists(&p, "privkey", opts.PrivKey)
+	fileExists(&p, "root-ca", opts.RootCA)
+
+	switch opts.PmType {
+	case "round-robin":
+	case "fixed":
+		if opts.Node(opts.LeaderID) == nil {
+			p.add("leader-id %v isn't in the node list", opts.LeaderID)
+		}
+	default:
+		p.add("pacemaker %q is neither \"fixed\" nor \"round-robin\"", opts.PmType)
+	}
+	if opts.LogSequencer != 0 && opts.Node(opts.LogSequencer) == nil {
+		p.add("log-sequencer %v isn't in the node list", opts.LogSequencer)
+	}
+
+	switch opts.TraceExporter {
+	case "", "otlp", "file":
+	default:
+		p.add("trace-exporter %q is neither \"otlp\" nor \"file\"", opts.TraceExporter)
+	}
+
+	for i, endpoint := range opts.CTLogs {
+		if endpoint.URL == "" {
+			p.add("ct-logs entry %v has no url", i+1)
+		}
+		if endpoint.PublicKey != "" {
+			fileExists(&p, fmt.Sprintf("public-key of CT log %v", endpoint.URL), endpoint.PublicKey)
+		}
+	}
+	if len(opts.CTLogs) > 0 && opts.MinSCTs > len(opts.CTLogs) {
+		p.add("min-scts is %v but only %v CT logs are configured", opts.MinSCTs, len(opts.CTLogs))
+	}
+
+	durations := []struct {
+		name  string
+		value int
+	}{
+		{"view-timeout", opts.ViewTimeout},
+		{"signing-timeout", opts.SigningTimeout},
+		{"retry-after", opts.RetryAfter},
+		{"sth-interval", opts.STHInterval},
+		{"inclusion-wait", opts.InclusionWait},
+		{"connect-timeout", opts.ConnectTimeout},
+		{"max-backoff", opts.MaxBackoff},
+		{"request-timeout", opts.RequestTimeout},
+		{"retention", opts.Retention},
+		{"drain-timeout", opts.DrainTimeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			p.add("%v must not be negative", d.name)
+		}
+	}
+	if opts.SigningWorkers < 0 || opts.QueueLimit < 0 || opts.SigningQueueLimit < 0 || opts.ClientQueueLimit < 0 {
+		p.add("signing-workers and the queue limits must not be negative")
+	}
+	if opts.ReplayWindow < 0 {
+		p.add("replay-window must not be negative")
+	}
+	if opts.ClientRate < 0 || opts.ClientBurst < 0 {
+		p.add("client-rate and client-burst must not be negative")
+	}
+
+	return p.err()
+}
+
+// validateNodes checks that the IDs of the nodes are 1 to N, in any order, and that no address is used twice.
+func validateNodes(opts *Options, p *problems) {
+	if len(opts.Nodes) == 0 {
+		p.add("node list is empty")
+		return
+	}
+
+	ids := make(map[hotstuff.ID]bool, len(opts.Nodes))
+	addrs := make(map[string]string) // which setting an address has been used for first
+	for i, node := range opts.Nodes {
+		name := fmt.Sprintf("node %v", node.ID)
+		switch {
+		case node.ID == 0:
+			p.add("nodes entry %v has no id", i+1)
+			name = fmt.Sprintf("nodes entry %v", i+1)
+		case int(node.ID) > len(opts.Nodes):
+			p.add("%v is out of range: the IDs of %v nodes must be 1 to %v", name, len(opts.Nodes), len(opts.Nodes))
+		case ids[node.ID]:
+			p.add("%v is listed twice", name)
+		}
+		ids[node.ID] = true
+
+		fileExists(p, "pubkey of "+name, node.PubKey)
+
+		required := []struct{ setting, addr string }{
+			{"client-srv-address", node.ClientSrvAddr},
+			{"replication-srv-address", node.ReplicationSrvAddr},
+			{"signing-srv-address", node.SigningSrvAddr},
+		}
+		optional := []struct{ setting, addr string }{
+			{"metrics-address", node.MetricsAddr},
+			{"admin-address", node.AdminAddr},
+		}
+		for _, a := range required {
+			if a.addr == "" {
+				p.add("%v has no %v", name, a.setting)
+			}
+		}
+		for _, a := range append(required, optional...) {
+			if a.addr == "" {
+				continue
+			}
+			setting := fmt.Sprintf("%v of %v", a.setting, name)
+			if _, _, err := net.SplitHostPort(a.addr); err != nil {
+				p.add("%v is invalid: %v", setting, err)
+				continue
+			}
+			if first, used := addrs[a.addr]; used {
+				p.add("%v %v is already the %v", setting, a.addr, first)
+				continue
+			}
+			addrs[a.addr] = setting
+		}
+	}
+}
+
+// fileExists adds a problem unless path names a readable file.
+func fileExists(p *problems, setting, path string) {
+	if path == "" {
+		p.add("%v is missing", setting)
+		return
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		p.add("%v: %v", setting, err)
+	case info.IsDir():
+		p.add("%v: %v is a directory", setting, path)
+	}
+}
+
+// ValidateKey checks that the threshold key share of this node fits the configuration: it must be the share of
+// this node, split among all nodes with the threshold the signing quorum needs, of the size in key-size and
+// belong to the root certificate. rootCA may be nil if it couldn't be read.
+func ValidateKey(opts *Options, key *crypto.ThresholdKey, rootCA *x509.Certificate) error {
+	var p problems
+
+	meta := key.KeyMeta
+	if n := len(opts.Nodes); int(meta.L) != n {
+		p.add("threshold key is split into %v shares but there are %v nodes", meta.L, n)
+	}
+	if quorum := QuorumSize(len(opts.Nodes)); int(meta.K) != quorum {
+		p.add("threshold key needs %v shares to sign but a quorum of %v nodes is %v", meta.K, len(opts.Nodes), quorum)
+	}
+	if id := hotstuff.ID(key.KeyShare.Id); id != opts.ID {
+		p.add("threshold key is the share of node %v, not of node %v", id, opts.ID)
+	}
+	// the modulus of a tcrsa key may be a bit shorter than the size it has been generated with
+	if bits := meta.PublicKey.Size() * 8; opts.KeySize != 0 && bits != opts.KeySize {
+		p.add("threshold key has %v bits but key-size is %v", bits, opts.KeySize)
+	}
+
+	if rootCA != nil {
+		pub, ok := rootCA.PublicKey.(*rsa.PublicKey)
+		if !ok || !pub.Equal(meta.PublicKey) {
+			p.add("public key of the root certificate %v doesn't belong to the threshold key", opts.RootCA)
+		}
+	}
+
+	return p.err()
+}
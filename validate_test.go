@@ -0,0 +1,144 @@
+package hotcertification_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/relab/hotstuff"
+
+	hc "github.com/raphasch/hotcertification"
+	"github.com/raphasch/hotcertification/crypto"
+)
+
+// validOptions returns the configuration of node 1 of a cluster of four nodes, listed out of order, whose files
+// exist in a temporary directory.
+func validOptions(t *testing.T) *hc.Options {
+	t.Helper()
+	dir := t.TempDir()
+	file := func(name string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	opts := &hc.Options{
+		ID:           1,
+		RootCA:       file("root.crt"),
+		PrivKey:      file("n1.key"),
+		ThresholdKey: file("n1.thresholdkey"),
+		PmType:       "round-robin",
+	}
+	for _, id := range []int{3, 1, 4, 2} {
+		port := string(rune('0' + id))
+		opts.Nodes = append(opts.Nodes, hc.Node{
+			ID:                 hotstuff.ID(id),
+			PubKey:             file("n" + port + ".key.pub"),
+			ClientSrvAddr:      "127.0.0.1:808" + port,
+			ReplicationSrvAddr: "127.0.0.1:1337" + port,
+			SigningSrvAddr:     "127.0.0.1:2337" + port,
+		})
+	}
+	return opts
+}
+
+func problemsOf(t *testing.T, err error) []string {
+	t.Helper()
+	var cfgErr *hc.ConfigError
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected a ConfigError, got %v", err)
+	}
+	return cfgErr.Problems
+}
+
+// mentions tells whether one of the problems contains all parts.
+func mentions(problems []string, parts ...string) bool {
+	for _, problem := range problems {
+		found := true
+		for _, part := range parts {
+			found = found && strings.Contains(problem, part)
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidate(t *testing.T) {
+	opts := validOptions(t)
+	if err := hc.Validate(opts); err != nil {
+		t.Fatal(err)
+	}
+	if self := opts.Self(); self == nil || self.ClientSrvAddr != "127.0.0.1:8081" {
+		t.Errorf("got %+v as this node", self)
+	}
+
+	opts.Nodes[2].ID = 3                                       // node 4 is missing, node 3 is listed twice
+	opts.Nodes[1].SigningSrvAddr = opts.Nodes[0].ClientSrvAddr // node 1 signs on the client address of node 3
+	opts.Nodes[3].ReplicationSrvAddr = ""
+	opts.Nodes = append(opts.Nodes, hc.Node{ID: 7})
+	opts.PrivKey = filepath.Join(filepath.Dir(opts.PrivKey), "missing.key")
+	opts.PmType = "fixed"
+	opts.LeaderID = 9
+	opts.RequestTimeout = -1
+
+	problems := problemsOf(t, hc.Validate(opts))
+	for _, want := range [][]string{
+		{"node 3", "twice"},
+		{"signing-srv-address of node 1", "client-srv-address of node 3"},
+		{"node 2", "replication-srv-address"},
+		{"node 7", "out of range"},
+		{"privkey", "missing.key"},
+		{"leader-id 9"},
+		{"request-timeout"},
+	} {
+		if !mentions(problems, want...) {
+			t.Errorf("no problem mentions %q; got:\n%v", want, strings.Join(problems, "\n"))
+		}
+	}
+
+	opts.ID = 5
+	if problems := problemsOf(t, hc.Validate(opts)); !mentions(problems, "node 5 isn't in the node list") {
+		t.Errorf("unknown ID of this node isn't reported; got:\n%v", strings.Join(problems, "\n"))
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	keys, err := crypto.ComputeTresholdKeys(3, 4, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := validOptions(t)
+	opts.KeySize = 512
+	rootCA := &x509.Certificate{PublicKey: keys[0].Public()}
+
+	if err := hc.ValidateKey(opts, keys[0], rootCA); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := rsa.GenerateKey(rand.Reader, 512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Nodes = opts.Nodes[:3]
+	opts.KeySize = 1024
+	problems := problemsOf(t, hc.ValidateKey(opts, keys[1], &x509.Certificate{PublicKey: &other.PublicKey}))
+	for _, want := range [][]string{
+		{"4 shares", "3 nodes"},
+		{"share of node 2, not of node 1"},
+		{"512 bits", "key-size is 1024"},
+		{"root certificate"},
+	} {
+		if !mentions(problems, want...) {
+			t.Errorf("no problem mentions %q; got:\n%v", want, strings.Join(problems, "\n"))
+		}
+	}
+}